@@ -19,13 +19,22 @@ type Action struct {
 }
 
 var acceptedMethods = func() []string {
-	methods := []string{http.MethodGet, http.MethodDelete}
+	methods := []string{http.MethodGet, http.MethodDelete, http.MethodPost, http.MethodPut, http.MethodPatch}
 	for i := range methods {
 		methods[i] = strings.ToLower(methods[i])
 	}
 	return methods
 }()
 
+// webSocketMode takes the place of the method to open a WebSocket.
+const webSocketMode = "ws"
+
+// graphQLMode takes the place of the method to send a GraphQL operation.
+const graphQLMode = "graphql"
+
+// rpcMode takes the place of the method to send a JSON-RPC call.
+const rpcMode = "rpc"
+
 var errInvalidFormat = errors.New("invalid format")
 
 func NewAction(args []string) (Action, error) {
@@ -92,8 +101,12 @@ func (a Action) Setting() (config.Setting, error) {
 }
 
 func validateHTTPMethod(method string) error {
+	if method == webSocketMode || method == graphQLMode || method == rpcMode {
+		return nil
+	}
 	if !slices.Contains(acceptedMethods, method) {
-		return fmt.Errorf("invalid http method, only %s are supported", strings.Join(acceptedMethods, ", "))
+		return fmt.Errorf("invalid http method, only %s, %s, %s and %s are supported",
+			strings.Join(acceptedMethods, ", "), webSocketMode, graphQLMode, rpcMode)
 	}
 	return nil
 }
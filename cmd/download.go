@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ashttp/internal/config"
+	"github.com/ashttp/internal/download"
+	"github.com/ashttp/internal/output"
+)
+
+// runDownload saves the response body to disk instead of printing it.
+func runDownload(req *http.Request, setting config.Setting, flags cliFlags, record *historyRecord) {
+	opts := download.Options{Path: flags.outputFile}
+	if output.IsTerminal(os.Stderr) {
+		opts.Progress = os.Stderr
+	}
+
+	result, err := download.Run(req, setting, opts)
+	if err != nil {
+		record.save(0, time.Since(record.entry.Time), err)
+		fatal("failed to download: %v", err)
+	}
+	record.save(result.Stream.StatusCode, result.Stream.Timing().Total, nil)
+
+	if flags.timing || flags.verbose {
+		if err := printTiming(result.Stream.Timing(), flags.timingFormat); err != nil {
+			fatal("failed to print timing: %v", err)
+		}
+	}
+
+	if flags.include {
+		stream := result.Stream
+		fmt.Print(output.Head(stream.Proto, stream.Status, stream.StatusCode, stream.Header, flags.colored))
+	}
+
+	if result.Resumed > 0 {
+		fmt.Fprintf(os.Stderr, "saved %s (%s, resumed after %s)\n",
+			result.Path, output.FormatSize(result.Size), output.FormatSize(result.Resumed))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "saved %s (%s)\n", result.Path, output.FormatSize(result.Size))
+}
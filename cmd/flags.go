@@ -0,0 +1,428 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ashttp/internal/assert"
+	"github.com/ashttp/internal/config"
+	"github.com/ashttp/internal/export"
+	"github.com/ashttp/internal/filter"
+	internalhttp "github.com/ashttp/internal/http"
+	"github.com/ashttp/internal/jsonvalue"
+	"github.com/ashttp/internal/output"
+	"github.com/ashttp/internal/session"
+	"github.com/ashttp/internal/templating"
+)
+
+type cliFlags struct {
+	version      bool
+	verbose      bool
+	timing       bool
+	timingFormat string
+
+	headers headerFlags
+	data    string
+
+	vars     varFlags
+	varsFile string
+	captures captureFlags
+
+	expectStatus  string
+	expectHeaders headerFlags
+	expectJSON    jsonAssertionFlags
+	expectLatency time.Duration
+	expectSchema  string
+
+	// expect is resolved from the -expect flags once flags are parsed.
+	expect assert.Assertions
+
+	output  string
+	columns string
+	filter  string
+	color   string
+	include bool
+
+	// colored is resolved from color and the terminal once flags are parsed.
+	colored bool
+
+	outputFile string
+	download   bool
+	stream     bool
+	replies    int
+
+	dryRun       bool
+	printRequest bool
+	showSecrets  bool
+	asCurl       bool
+	asHTTPie     bool
+	asFetch      bool
+
+	connectTimeout        time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	totalTimeout          time.Duration
+
+	maxAttempts     int
+	retryAllMethods bool
+
+	noFollow     bool
+	maxRedirects int
+	keepMethod   bool
+	forwardAuth  bool
+
+	noHistory bool
+}
+
+func parseFlags() cliFlags {
+	var f cliFlags
+
+	flag.BoolVar(&f.version, "v", false, "Print version information and exit")
+	flag.BoolVar(&f.verbose, "verbose", false, "Print details about each exchange, timing included, to stderr")
+	flag.BoolVar(&f.timing, "timing", false, "Print the timing breakdown of the request to stderr")
+	flag.StringVar(&f.timingFormat, "timing-format", "text", "Format of the timing breakdown: text or json")
+
+	flag.Var(&f.headers, "header", "Header sent with this request only, as \"Name: value\", may be repeated")
+	flag.StringVar(&f.data, "data", "", "Request body, or @path to read it from a file")
+
+	flag.Var(&f.vars, "var", "Template variable as name=value, may be repeated")
+	flag.StringVar(&f.varsFile, "vars", "", "JSON or YAML file of template variables, -var values take precedence")
+	flag.Var(&f.captures, "capture",
+		"Store a value of the JSON response in the session as name=expression, e.g. id=.data.id, may be repeated")
+
+	flag.StringVar(&f.expectStatus, "expect-status", "", "Fail unless the response status is one of these, e.g. 200,204")
+	flag.Var(&f.expectHeaders, "expect-header",
+		"Fail unless the response header matches a regular expression, as \"Name: regex\", may be repeated")
+	flag.Var(&f.expectJSON, "expect-json",
+		"Fail unless a value of the JSON response is as expected, as \"path == value\" or \"path =~ regex\", may be repeated")
+	flag.DurationVar(&f.expectLatency, "expect-latency", 0, "Fail if the response takes longer than this")
+	flag.StringVar(&f.expectSchema, "expect-schema", "", "Fail unless the JSON response follows the JSON Schema of this file")
+
+	flag.StringVar(&f.output, "output", output.FormatJSON,
+		"Response format: "+strings.Join(output.Formats, ", ")+", table and csv expect an array of objects")
+	flag.StringVar(&f.columns, "columns", "", "Comma separated columns for table and csv output, dots reach nested fields")
+	flag.StringVar(&f.color, "color", output.ColorAuto,
+		"When to color the response: auto (terminals only, unless NO_COLOR is set), always or never")
+	flag.BoolVar(&f.include, "include", false, "Print the response status line and headers before the body")
+	flag.StringVar(&f.filter, "filter", "", "jq expression (supported subset in the README) applied to the JSON response")
+
+	flag.StringVar(&f.outputFile, "output-file", "",
+		"Stream the response body to this file, resuming a previous interrupted download")
+	flag.BoolVar(&f.download, "download", false,
+		"Stream the response body to a file named after Content-Disposition or the URL")
+
+	flag.BoolVar(&f.stream, "stream", false,
+		"Print the body as it arrives, NDJSON and event stream responses are always streamed")
+
+	flag.IntVar(&f.replies, "replies", 1, "With ws and -data, number of messages to wait for before closing")
+
+	flag.BoolVar(&f.dryRun, "dry-run", false, "Print the request that would be sent and exit without sending it")
+	flag.BoolVar(&f.printRequest, "print-request", false, "Print the request to stderr before sending it")
+	flag.BoolVar(&f.showSecrets, "show-secrets", false, "Do not redact sensitive headers when printing the request")
+	flag.BoolVar(&f.asCurl, "as-curl", false, "Print the request as a curl command and exit")
+	flag.BoolVar(&f.asHTTPie, "as-httpie", false, "Print the request as an HTTPie command and exit")
+	flag.BoolVar(&f.asFetch, "as-fetch", false, "Print the request as a JavaScript fetch() call and exit")
+
+	flag.DurationVar(&f.connectTimeout, "connect-timeout", 0, "Maximum time to establish the connection, DNS lookup included")
+	flag.DurationVar(&f.tlsHandshakeTimeout, "tls-timeout", 0, "Maximum time for the TLS handshake")
+	flag.DurationVar(&f.responseHeaderTimeout, "header-timeout", 0,
+		"Maximum time to wait for the response headers once the request is sent")
+	flag.DurationVar(&f.totalTimeout, "timeout", 0, "Maximum time for the whole request, body included")
+
+	flag.IntVar(&f.maxAttempts, "max-attempts", 0, "Maximum number of attempts for retryable failures")
+	flag.BoolVar(&f.retryAllMethods, "retry-all-methods", false, "Also retry non idempotent methods such as POST")
+
+	flag.BoolVar(&f.noFollow, "no-follow", false, "Do not follow redirects")
+	flag.IntVar(&f.maxRedirects, "max-redirects", 0, "Maximum number of redirects to follow")
+	flag.BoolVar(&f.keepMethod, "keep-method", false, "Keep the request method on 301 and 302 redirects")
+	flag.BoolVar(&f.forwardAuth, "forward-auth", false, "Forward authorization headers when a redirect changes host")
+
+	flag.BoolVar(&f.noHistory, "no-history", false, "Do not record this request in the history")
+
+	flag.Parse()
+	return f
+}
+
+// headerFlags collects repeated -header "Name: value" flags.
+type headerFlags map[string]string
+
+func (h *headerFlags) String() string {
+	return fmt.Sprint(map[string]string(*h))
+}
+
+func (h *headerFlags) Set(value string) error {
+	name, headerValue, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected \"Name: value\", got %q", value)
+	}
+
+	if *h == nil {
+		*h = headerFlags{}
+	}
+	(*h)[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+	return nil
+}
+
+// varFlags collects repeated -var name=value flags.
+type varFlags map[string]string
+
+func (v *varFlags) String() string {
+	return fmt.Sprint(map[string]string(*v))
+}
+
+func (v *varFlags) Set(value string) error {
+	name, varValue, err := templating.ParseVar(value)
+	if err != nil {
+		return err
+	}
+
+	if *v == nil {
+		*v = varFlags{}
+	}
+	(*v)[name] = varValue
+	return nil
+}
+
+// captureFlags collects repeated -capture name=expression flags.
+type captureFlags map[string]string
+
+func (c *captureFlags) String() string {
+	return fmt.Sprint(map[string]string(*c))
+}
+
+func (c *captureFlags) Set(value string) error {
+	name, expression, err := session.ParseCapture(value)
+	if err != nil {
+		return err
+	}
+
+	if *c == nil {
+		*c = captureFlags{}
+	}
+	(*c)[name] = expression
+	return nil
+}
+
+// jsonAssertionFlags collects repeated -expect-json flags.
+type jsonAssertionFlags []assert.JSONAssertion
+
+func (j *jsonAssertionFlags) String() string {
+	return fmt.Sprint(len(*j), " assertions")
+}
+
+func (j *jsonAssertionFlags) Set(value string) error {
+	assertion, err := assert.ParseJSONAssertion(value)
+	if err != nil {
+		return err
+	}
+	*j = append(*j, assertion)
+	return nil
+}
+
+// assertions returns the checks given with the -expect flags.
+func (f cliFlags) assertions() (assert.Assertions, error) {
+	a := assert.Assertions{
+		Headers:    f.expectHeaders,
+		JSON:       f.expectJSON,
+		MaxLatency: assert.Duration(f.expectLatency),
+	}
+
+	if f.expectStatus != "" {
+		statuses, err := assert.ParseStatuses(f.expectStatus)
+		if err != nil {
+			return assert.Assertions{}, err
+		}
+		a.Status = statuses
+	}
+
+	if f.expectSchema != "" {
+		schema, err := assert.LoadSchema(f.expectSchema)
+		if err != nil {
+			return assert.Assertions{}, err
+		}
+		a.Schema = schema
+	}
+
+	return a, a.Validate()
+}
+
+// templateVars returns the variables of the -vars file and the -var flags,
+// the latter taking precedence. Both take precedence over the values
+// captured in the session.
+func (f cliFlags) templateVars() (*templateVars, error) {
+	vars := templating.Vars{}
+	if f.varsFile != "" {
+		fromFile, err := templating.LoadFile(f.varsFile)
+		if err != nil {
+			return nil, err
+		}
+		vars = fromFile
+	}
+
+	for name, value := range f.vars {
+		vars[name] = value
+	}
+	return &templateVars{values: vars}, nil
+}
+
+// body returns the request body given with -data, reading it from a file
+// when it starts with "@".
+func (f cliFlags) body() ([]byte, error) {
+	path, isFile := strings.CutPrefix(f.data, "@")
+	if !isFile {
+		return []byte(f.data), nil
+	}
+
+	return os.ReadFile(path)
+}
+
+// request applies the per-call headers and body to request.
+func (f cliFlags) request(request *internalhttp.Request) error {
+	body, err := f.body()
+	if err != nil {
+		return err
+	}
+	request.Body = body
+
+	if len(f.headers) == 0 {
+		return nil
+	}
+	if request.Headers == nil {
+		request.Headers = map[string]string{}
+	}
+	for k, v := range f.headers {
+		request.Headers[k] = v
+	}
+
+	return nil
+}
+
+// render formats the response body as asked with -output and -columns.
+func (f cliFlags) render(body []byte, contentType string) (string, error) {
+	var columns []string
+	if f.columns != "" {
+		for column := range strings.SplitSeq(f.columns, ",") {
+			columns = append(columns, strings.TrimSpace(column))
+		}
+	}
+
+	if f.filter == "" {
+		return output.Render(body, contentType, f.output, columns)
+	}
+
+	query, err := filter.Parse(f.filter)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := jsonvalue.Decode([]byte(output.Text(body, contentType)))
+	if err != nil {
+		return "", fmt.Errorf("the response is not JSON and cannot be filtered: %w", err)
+	}
+
+	results, err := query.Apply(value)
+	if err != nil {
+		return "", err
+	}
+
+	return output.RenderValues(results, f.output, columns)
+}
+
+// exporter returns the function printing the request as another tool's
+// command, if one was asked for.
+func (f cliFlags) exporter() func(*http.Request, bool) (string, error) {
+	switch {
+	case f.asCurl:
+		return export.Curl
+	case f.asHTTPie:
+		return export.HTTPie
+	case f.asFetch:
+		return export.Fetch
+	default:
+		return nil
+	}
+}
+
+// override applies the per-call flags on top of the alias setting.
+func (f cliFlags) override(setting *config.Setting) {
+	f.overrideTimeouts(setting)
+	f.overrideRetry(setting)
+	f.overrideRedirect(setting)
+}
+
+func (f cliFlags) overrideTimeouts(setting *config.Setting) {
+	if f.connectTimeout <= 0 && f.tlsHandshakeTimeout <= 0 && f.responseHeaderTimeout <= 0 && f.totalTimeout <= 0 {
+		return
+	}
+
+	timeouts := config.TimeoutSetting{}
+	if setting.Timeouts != nil {
+		timeouts = *setting.Timeouts
+	}
+
+	if f.connectTimeout > 0 {
+		timeouts.Connect = config.Duration(f.connectTimeout)
+	}
+	if f.tlsHandshakeTimeout > 0 {
+		timeouts.TLSHandshake = config.Duration(f.tlsHandshakeTimeout)
+	}
+	if f.responseHeaderTimeout > 0 {
+		timeouts.ResponseHeader = config.Duration(f.responseHeaderTimeout)
+	}
+	if f.totalTimeout > 0 {
+		timeouts.Total = config.Duration(f.totalTimeout)
+	}
+
+	setting.Timeouts = &timeouts
+}
+
+func (f cliFlags) overrideRetry(setting *config.Setting) {
+	if f.maxAttempts <= 0 && !f.retryAllMethods {
+		return
+	}
+
+	retry := config.RetrySetting{}
+	if setting.Retry != nil {
+		retry = *setting.Retry
+	}
+
+	if f.maxAttempts > 0 {
+		retry.MaxAttempts = f.maxAttempts
+	}
+	if f.retryAllMethods {
+		retry.AllMethods = true
+	}
+
+	setting.Retry = &retry
+}
+
+func (f cliFlags) overrideRedirect(setting *config.Setting) {
+	if !f.noFollow && f.maxRedirects <= 0 && !f.keepMethod && !f.forwardAuth {
+		return
+	}
+
+	redirect := config.RedirectSetting{}
+	if setting.Redirect != nil {
+		redirect = *setting.Redirect
+	}
+
+	if f.noFollow {
+		follow := false
+		redirect.Follow = &follow
+	}
+	if f.maxRedirects > 0 {
+		redirect.Max = f.maxRedirects
+	}
+	if f.keepMethod {
+		redirect.KeepMethod = true
+	}
+	if f.forwardAuth {
+		redirect.ForwardAuth = true
+	}
+
+	setting.Redirect = &redirect
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ashttp/internal/graphql"
+	"github.com/ashttp/internal/http"
+)
+
+var graphQLFormatExpected = "[flags] <URL-alias> graphql (<query|@file> [--variable value] | schema [type])"
+
+// graphQLCall is an operation posted to the alias URL: a query given as
+// argument or @file, or the introspection query of schema.
+type graphQLCall struct {
+	schema   bool
+	typeName string
+}
+
+// newGraphQLCall turns the request built from the arguments into a
+// GraphQL one, its options becoming the operation variables.
+func newGraphQLCall(action Action, request *http.Request) (*graphQLCall, error) {
+	args := action.URLPathComponents
+	call := &graphQLCall{}
+
+	var query string
+	switch {
+	case len(args) > 0 && args[0] == "schema":
+		if len(args) > 2 {
+			return nil, fmt.Errorf("schema takes at most a type name")
+		}
+		if len(args) == 2 {
+			call.typeName = args[1]
+		}
+		call.schema = true
+		query = graphql.IntrospectionQuery
+	case len(args) == 1:
+		query = args[0]
+		if path, found := strings.CutPrefix(query, "@"); found {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			query = string(data)
+		}
+	default:
+		return nil, fmt.Errorf("expected %s", graphQLFormatExpected)
+	}
+
+	variables, err := graphql.Variables(query, action.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := graphql.Body(query, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Method = "post"
+	request.Path = ""
+	request.Arguments = nil
+	request.Body = body
+	return call, nil
+}
+
+// print shows data like any response and errors apart on stderr, exiting
+// with an error status when there are some.
+func (c *graphQLCall) print(response *http.Response, flags cliFlags) {
+	parsed, err := graphql.ParseResponse(response.Body)
+	if err != nil {
+		printResponse(response, flags)
+		fatal("%v", err)
+	}
+
+	if c.schema && parsed.Data != nil {
+		schema, err := graphql.ParseSchema(parsed.Data)
+		if err != nil {
+			fatal("%v", err)
+		}
+
+		description := schema.Summary()
+		if c.typeName != "" {
+			if description, err = schema.Describe(c.typeName); err != nil {
+				fatal("%v", err)
+			}
+		}
+		fmt.Println(description)
+	} else if parsed.Data != nil {
+		data := *response
+		data.Body = parsed.Data
+		printResponse(&data, flags)
+	}
+
+	for _, e := range parsed.Errors {
+		fmt.Fprintf(os.Stderr, "[graphql error] %s\n", e)
+	}
+	if len(parsed.Errors) > 0 {
+		os.Exit(1)
+	}
+}
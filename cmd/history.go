@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	nethttp "net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ashttp/internal/config"
+	"github.com/ashttp/internal/history"
+	"github.com/ashttp/internal/http"
+)
+
+var historyFormatExpected = "history [-limit n] [search terms...]"
+
+var replayFormatExpected = "[flags] replay <id> [--option value]"
+
+// historyRecord is the history entry of the request being sent, saved once
+// the request is answered or fails.
+type historyRecord struct {
+	entry   history.Entry
+	enabled bool
+}
+
+func newHistoryRecord(action Action, request http.Request, req *nethttp.Request, enveloped bool,
+	flags cliFlags) *historyRecord {
+	entry := history.Entry{
+		Time:    time.Now(),
+		Alias:   action.URLAlias,
+		Method:  action.HTTPMethod,
+		Path:    action.URLPathComponents,
+		Options: action.Options,
+		Headers: request.Headers,
+		URL:     req.URL.String(),
+	}
+	// The body of GraphQL and JSON-RPC calls is rebuilt from the arguments.
+	if !enveloped {
+		entry.Body = string(request.Body)
+		entry.ContentType = req.Header.Get("Content-Type")
+	}
+
+	return &historyRecord{entry: entry, enabled: !flags.noHistory}
+}
+
+func (r *historyRecord) save(status int, duration time.Duration, err error) {
+	if !r.enabled {
+		return
+	}
+
+	r.entry.Status = status
+	r.entry.Duration = config.Duration(duration)
+	if err != nil {
+		r.entry.Error = err.Error()
+	}
+
+	if _, err := history.Append(r.entry); err != nil {
+		fmt.Fprintf(os.Stderr, "[warning] failed to record the request in the history: %v\n", err)
+	}
+}
+
+// runHistory lists the last entries of the history matching the search
+// terms, oldest first.
+func runHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf("usage: %s\n", historyFormatExpected)
+		fs.PrintDefaults()
+	}
+	limit := fs.Int("limit", 20, "Number of entries to list, 0 for all of them")
+	_ = fs.Parse(args)
+
+	entries, err := history.Load()
+	if err != nil {
+		fatal("failed to load history: %v", err)
+	}
+
+	entries = history.Search(entries, fs.Args())
+	if *limit > 0 && len(entries) > *limit {
+		entries = entries[len(entries)-*limit:]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, entry := range entries {
+		status := strconv.Itoa(entry.Status)
+		if entry.Status == 0 {
+			status = "-"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.ID, entry.Time.Local().Format(time.DateTime),
+			status, roundDuration(time.Duration(entry.Duration)), entry.Alias, strings.ToUpper(entry.Method), entry.URL)
+	}
+	w.Flush()
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// replayCall rebuilds the call of a history entry. Redacted values must be
+// given again, with an option after the id or the -header and -data flags.
+func replayCall(args []string, flags cliFlags) ([]string, *storedCall, error) {
+	if len(args) == 0 {
+		return nil, nil, fmt.Errorf("expected %s", replayFormatExpected)
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid entry id %q", args[0])
+	}
+
+	entries, err := history.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	entry, err := history.Find(entries, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	call := &storedCall{
+		alias:   entry.Alias,
+		method:  entry.Method,
+		path:    entry.Path,
+		options: entry.Options,
+		headers: entry.Headers,
+		body:    entry.Body,
+		// The history keeps what was sent, templates already rendered.
+		verbatim: true,
+	}
+	callArgs, options, err := call.args(args[1:], replayFormatExpected)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for name, value := range options {
+		if value == history.Redacted {
+			return nil, nil, fmt.Errorf("option %s of entry %d was redacted, give it again with --%s value", name, id, name)
+		}
+	}
+	for name, value := range entry.Headers {
+		if _, given := flags.headers[name]; !given && strings.Contains(value, history.Redacted) {
+			return nil, nil, fmt.Errorf("header %s of entry %d was redacted, give it again with -header", name, id)
+		}
+	}
+	if flags.data == "" {
+		switch {
+		case entry.BodyOmitted:
+			return nil, nil, fmt.Errorf("the body of entry %d was not recorded, give it again with -data", id)
+		case strings.Contains(entry.Body, history.Redacted):
+			return nil, nil, fmt.Errorf("the body of entry %d has redacted fields, give it again with -data", id)
+		}
+	}
+
+	return callArgs, call, nil
+}
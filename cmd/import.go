@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ashttp/internal/config"
+	"github.com/ashttp/internal/export"
+	"github.com/ashttp/internal/importer"
+)
+
+var importFormatExpected = "import curl [-run] [-write] [-name alias] '<curl command>'"
+
+// importCall translates a pasted curl command into an ashttp call, matching
+// the existing aliases by base URL before suggesting a new one. With -run
+// it returns the call, sent the way any other is, otherwise it prints it
+// and returns nil.
+func importCall(args []string) ([]string, *storedCall) {
+	if len(args) == 0 || args[0] != "curl" {
+		fmt.Printf("usage: %s\n", importFormatExpected)
+		os.Exit(0)
+	}
+
+	fs := flag.NewFlagSet("import curl", flag.ExitOnError)
+	run := fs.Bool("run", false, "Send the request right away")
+	write := fs.Bool("write", false, "Add the suggested alias to the config file")
+	name := fs.String("name", "", "Name of the alias to create when no existing alias matches")
+	_ = fs.Parse(args[1:])
+
+	command, err := importer.ParseCurl(curlCommand(fs.Args()))
+	if err != nil {
+		fatal("failed to parse curl command: %v", err)
+	}
+
+	settings, err := config.GetSettings()
+	if err != nil {
+		fatal("failed to load setting: %v", err)
+	}
+
+	suggestion, err := importer.Translate(command, settings, config.URLAlias(*name))
+	if err != nil {
+		fatal("failed to translate curl command: %v", err)
+	}
+
+	if suggestion.NewAlias != nil {
+		if *write {
+			if err := config.AddURLAlias(suggestion.Alias, *suggestion.NewAlias); err != nil {
+				fatal("failed to write alias: %v", err)
+			}
+			fmt.Fprintf(os.Stderr, "alias %s added to %s\n", suggestion.Alias, config.GetDefaultConfigPath())
+		} else if !*run {
+			fmt.Printf("# new alias, add it to %s or run again with -write\n", config.GetDefaultConfigPath())
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(map[config.URLAlias]config.ExternalSettingURLAlias{
+				suggestion.Alias: *suggestion.NewAlias,
+			}); err != nil {
+				fatal("failed to print alias: %v", err)
+			}
+			fmt.Println()
+		}
+	}
+
+	if !*run {
+		fmt.Println(suggestion.CommandLine())
+		return nil, nil
+	}
+
+	call := &storedCall{
+		alias:    string(suggestion.Alias),
+		method:   suggestion.Method,
+		path:     suggestion.PathComponents,
+		options:  suggestion.Options,
+		headers:  suggestion.Headers,
+		body:     string(suggestion.Body),
+		setting:  &suggestion.Setting,
+		verbatim: true,
+	}
+	callArgs, _, err := call.args(nil, importFormatExpected)
+	if err != nil {
+		fatal("failed to run imported command: %v", err)
+	}
+	return callArgs, call
+}
+
+// curlCommand accepts the command either as a single quoted argument or
+// split by the shell into several ones.
+func curlCommand(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = export.ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -1,27 +1,96 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	nethttp "net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
+	"github.com/ashttp/internal/config"
+	"github.com/ashttp/internal/filter"
 	"github.com/ashttp/internal/http"
+	"github.com/ashttp/internal/output"
+	"github.com/ashttp/internal/stream"
 	"github.com/ashttp/internal/version"
 )
 
-var cliFormatExpected = "<URL-alias> <http-method> [path-components...] [--option value]"
+// exitInterrupted is the status of a request stopped with Ctrl-C, as shells
+// report for SIGINT.
+const exitInterrupted = 130
 
-func main() {
-	versionFlag := flag.Bool("v", false, "Print version information and exit")
-	flag.Parse()
+var cliFormatExpected = "[flags] <URL-alias> <http-method> [path-components...] [--option value]"
 
-	if *versionFlag {
+func main() {
+	flags := parseFlags()
+	if flags.version {
 		showVersion()
 	}
+	if flags.verbose {
+		http.Verbose = os.Stderr
+		stream.Verbose = os.Stderr
+	}
+	if err := output.ValidateFormat(flags.output); err != nil {
+		fatal("%v", err)
+	}
+	colored, err := output.ColorEnabled(flags.color, os.Stdout)
+	if err != nil {
+		fatal("%v", err)
+	}
+	flags.colored = colored
+	if flags.filter != "" {
+		if _, err := filter.Parse(flags.filter); err != nil {
+			fatal("invalid filter: %v", err)
+		}
+	}
+	if flags.expect, err = flags.assertions(); err != nil {
+		fatal("invalid assertion: %v", err)
+	}
 
 	args := flag.Args()
+	if len(args) > 0 && args[0] == "history" {
+		runHistory(args[1:])
+		return
+	}
+
+	if len(args) > 0 && args[0] == "run-file" {
+		runScenarioFile(args[1:], flags)
+		return
+	}
+	if len(args) > 0 && args[0] == "test" {
+		runTests(args[1:], flags)
+		return
+	}
+	if len(args) > 0 && args[0] == "session" {
+		runSession(args[1:])
+		return
+	}
+	if len(args) > 0 && args[0] == "requests" {
+		runRequests(args[1:], flags)
+		return
+	}
+
+	// Replayed, saved and imported calls are rebuilt into arguments, their
+	// headers and body being restored once the request is built.
+	var stored *storedCall
+	if len(args) > 0 && args[0] == "import" {
+		if args, stored = importCall(args[1:]); stored == nil {
+			return
+		}
+	} else if len(args) > 0 && args[0] == "replay" {
+		if args, stored, err = replayCall(args[1:], flags); err != nil {
+			fatal("failed to replay: %v", err)
+		}
+	} else if len(args) > 0 && args[0] == "run" {
+		if args, stored, err = savedCall(args[1:]); err != nil {
+			fatal("failed to run: %v", err)
+		}
+	}
 
 	action, err := NewAction(args)
 	if err != nil {
@@ -33,43 +102,223 @@ func main() {
 		}
 	}
 
-	request := action.Request()
-	setting, err := action.Setting()
+	vars, err := flags.templateVars()
 	if err != nil {
+		fatal("failed to read template variables: %v", err)
+	}
+	if err := renderAction(&action, vars); err != nil {
+		fatal("failed to render %v", err)
+	}
+
+	request := action.Request()
+	if err := flags.request(&request); err != nil {
+		fatal("failed to read request body: %v", err)
+	}
+	if stored != nil && !stored.verbatim {
+		stored.restore(&request, flags)
+	}
+	if err := renderRequest(&request, vars); err != nil {
+		fatal("failed to render %v", err)
+	}
+	if stored != nil && stored.verbatim {
+		stored.restore(&request, flags)
+	}
+
+	var setting config.Setting
+	if stored != nil && stored.setting != nil {
+		setting = *stored.setting
+	} else if setting, err = action.Setting(); err != nil {
 		fatal("failed to load setting: %v", err)
 	}
+	flags.override(&setting)
+
+	// GraphQL and JSON-RPC calls wrap the request in their envelope and
+	// unwrap the response.
+	var call envelopedCall
+	switch action.HTTPMethod {
+	case graphQLMode:
+		if flags.data != "" {
+			fatal("-data cannot be used with graphql, the body is built from the query and its variables")
+		}
+		if call, err = newGraphQLCall(action, &request); err != nil {
+			fatal("graphql: %v", err)
+		}
+	case rpcMode:
+		if flags.data != "" {
+			fatal("-data cannot be used with rpc, the body is built from the method and its params")
+		}
+		if call, err = newRPCCall(action, &request); err != nil {
+			fatal("rpc: %v", err)
+		}
+	}
+
+	if action.HTTPMethod == webSocketMode {
+		if !flags.expect.Empty() {
+			fatal("-expect flags cannot be used with ws")
+		}
+		runWebSocket(action, request, setting, flags)
+		return
+	}
 
 	req, err := request.ToHTTPRequest(setting)
 	if err != nil {
 		fatal("failed to build request: %v", err)
 	}
+	if call != nil {
+		// The call goes to the alias URL itself, not to a path below it.
+		req.URL.Path = strings.TrimSuffix(req.URL.Path, "/")
+	}
+
+	// Ctrl-C cancels the request instead of killing the process, so that
+	// streams end cleanly and downloads keep what they received.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	req = req.WithContext(ctx)
+
+	if exporter := flags.exporter(); exporter != nil {
+		command, err := exporter(req, flags.showSecrets)
+		if err != nil {
+			fatal("failed to export request: %v", err)
+		}
+		fmt.Println(command)
+		os.Exit(0)
+	}
+	showRequest(req, nil, flags)
 
-	response, err := http.Execute(req)
+	record := newHistoryRecord(action, request, req, call != nil, flags)
+
+	if flags.outputFile != "" || flags.download {
+		if len(flags.captures) > 0 || !flags.expect.Empty() {
+			fatal("-capture and -expect flags cannot be used when downloading")
+		}
+		runDownload(req, setting, flags, record)
+		return
+	}
+
+	opened, err := http.Open(req, setting)
 	if err != nil {
+		record.save(0, time.Since(record.entry.Time), err)
+		if ctx.Err() != nil {
+			os.Exit(exitInterrupted)
+		}
 		fatal("failed to execute request: %v", err)
 	}
 
-	output, err := prettyResponse(response)
+	if mode := stream.Detect(opened.Header.Get("Content-Type")); mode != stream.None || flags.stream {
+		// A stream may last for hours, its duration is the time to the headers.
+		record.save(opened.StatusCode, opened.Timing().Total, nil)
+		if len(flags.captures) > 0 || !flags.expect.Empty() {
+			fatal("-capture and -expect flags cannot be used with streamed responses")
+		}
+		runStream(ctx, req, setting, opened, mode, flags)
+		return
+	}
+
+	response, err := opened.ReadAll()
 	if err != nil {
-		fmt.Println(err)
+		record.save(opened.StatusCode, opened.Timing().Total, err)
+		if ctx.Err() != nil {
+			os.Exit(exitInterrupted)
+		}
+		fatal("failed to read response: %v", err)
+	}
+	record.save(response.StatusCode, response.Timing.Total, nil)
+
+	if err := captureResponse(response, flags); err != nil {
+		printResponse(response, flags)
+		fatal("failed to capture %v", err)
+	}
+
+	if flags.timing || flags.verbose {
+		if err := printTiming(response.Timing, flags.timingFormat); err != nil {
+			fatal("failed to print timing: %v", err)
+		}
 	}
 
-	fmt.Println(output)
+	// Failed assertions are reported before the response, which still
+	// helps to understand them.
+	var failures []string
+	if !flags.expect.Empty() {
+		failures = flags.expect.Check(response)
+		for _, failure := range failures {
+			fmt.Fprintf(os.Stderr, "[assertion failed] %s\n", failure)
+		}
+	}
+
+	if call != nil {
+		call.print(response, flags)
+	} else {
+		printResponse(response, flags)
+	}
+	if len(failures) > 0 {
+		os.Exit(1)
+	}
 }
 
-func prettyResponse(resp []byte) (string, error) {
-	var data any
-	err := json.Unmarshal(resp, &data)
+// showRequest prints req, and the message sent after it if any, for
+// -print-request, or for -dry-run and exits without sending it.
+func showRequest(req *nethttp.Request, message []byte, flags cliFlags) {
+	if !flags.dryRun && !flags.printRequest {
+		return
+	}
+
+	dump, err := http.DumpRequest(req, flags.showSecrets)
 	if err != nil {
-		return string(resp), nil
+		fatal("failed to print request: %v", err)
+	}
+	if len(message) > 0 {
+		dump += "\n" + string(message) + "\n"
+	}
+
+	if flags.dryRun {
+		fmt.Print(dump)
+		os.Exit(0)
 	}
+	fmt.Fprintln(os.Stderr, dump)
+}
 
-	pretty, err := json.MarshalIndent(data, "", "  ")
+// envelopedCall is a call whose response wraps the result in an envelope.
+type envelopedCall interface {
+	print(response *http.Response, flags cliFlags)
+}
+
+func printResponse(response *http.Response, flags cliFlags) {
+	rendered, err := flags.render(response.Body, response.Header.Get("Content-Type"))
 	if err != nil {
-		return "", err
+		fatal("failed to render response: %v", err)
+	}
+
+	if flags.include {
+		fmt.Println(output.Head(response.Proto, response.Status, response.StatusCode, response.Header, flags.colored))
+	}
+
+	// The raw body is written untouched so it can be piped to other tools.
+	if flags.output == output.FormatRaw && flags.filter == "" {
+		fmt.Print(rendered)
+		return
+	}
+
+	if flags.colored {
+		rendered = output.Highlight(rendered, flags.output)
 	}
+	fmt.Println(rendered)
+}
 
-	return string(pretty), nil
+func printTiming(timing http.Timing, format string) error {
+	switch format {
+	case "text":
+		fmt.Fprint(os.Stderr, timing)
+	case "json":
+		data, err := json.Marshal(timing)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, string(data))
+	default:
+		return fmt.Errorf("unknown timing format %q, expected text or json", format)
+	}
+
+	return nil
 }
 
 func fatal(format string, v ...any) {
@@ -78,7 +327,12 @@ func fatal(format string, v ...any) {
 }
 
 func showHelp() {
-	fmt.Printf("usage: %s\n", cliFormatExpected)
+	usages := []string{
+		cliFormatExpected, graphQLFormatExpected, rpcFormatExpected, runFormatExpected, requestsFormatExpected,
+		runFileFormatExpected, testFormatExpected,
+		replayFormatExpected, historyFormatExpected, sessionFormatExpected, importFormatExpected,
+	}
+	fmt.Printf("usage: %s\n", strings.Join(usages, "\n       "))
 	os.Exit(0)
 }
 
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ashttp/internal/collection"
+	"github.com/ashttp/internal/config"
+	"github.com/ashttp/internal/history"
+	"github.com/ashttp/internal/http"
+)
+
+var requestsFormatExpected = "[flags] requests (list | save [-local] <name> [<URL-alias> <http-method> [path-components...] [--option value]] | delete <name>)"
+
+var runFormatExpected = "[flags] run <name> [--option value]"
+
+// storedCall is a call rebuilt from the history or a saved request.
+type storedCall struct {
+	alias   string
+	method  string
+	path    []string
+	options map[string]string
+	headers map[string]string
+	body    string
+
+	// setting is used for an alias that is not in the config, such as one
+	// suggested by an import.
+	setting *config.Setting
+
+	// verbatim is set when the headers and body were not written as
+	// templates, as those of the history or of an import, so "{{" in them is
+	// sent as is.
+	verbatim bool
+}
+
+// args turns the call back into command line arguments, the options in
+// extra being added to or replacing the stored ones. It also returns the
+// resulting options.
+func (c *storedCall) args(extra []string, usage string) ([]string, map[string]string, error) {
+	// Extra options are read the way NewAction reads options.
+	overrides, err := NewAction(append([]string{c.alias, c.method}, extra...))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(overrides.URLPathComponents) > 0 {
+		return nil, nil, fmt.Errorf("expected %s", usage)
+	}
+
+	options := map[string]string{}
+	for name, value := range c.options {
+		options[name] = value
+	}
+	for name, value := range overrides.Options {
+		options[name] = value
+	}
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	args := append([]string{c.alias, c.method}, c.path...)
+	for _, name := range names {
+		args = append(args, "--"+name, options[name])
+	}
+	return args, options, nil
+}
+
+// restore sets the stored headers and body that the flags of this call do
+// not replace.
+func (c *storedCall) restore(request *http.Request, flags cliFlags) {
+	for name, value := range c.headers {
+		if _, given := flags.headers[name]; given {
+			continue
+		}
+		if request.Headers == nil {
+			request.Headers = map[string]string{}
+		}
+		request.Headers[name] = value
+	}
+
+	if flags.data == "" && c.body != "" {
+		request.Body = []byte(c.body)
+	}
+}
+
+// savedCall rebuilds the call of a saved request.
+func savedCall(args []string) ([]string, *storedCall, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "--") {
+		return nil, nil, fmt.Errorf("expected %s", runFormatExpected)
+	}
+
+	saved, err := loadSaved()
+	if err != nil {
+		return nil, nil, err
+	}
+	found, err := collection.Find(saved, args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request := found.Request
+	call := &storedCall{
+		alias:   request.Alias,
+		method:  request.Method,
+		path:    request.Path,
+		options: request.Options,
+		headers: request.Headers,
+		body:    request.Body,
+	}
+	callArgs, _, err := call.args(args[1:], runFormatExpected)
+	return callArgs, call, err
+}
+
+func loadSaved() ([]collection.Saved, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := config.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	return collection.Load(dir, settings)
+}
+
+// runRequests lists, saves and deletes saved requests.
+func runRequests(args []string, flags cliFlags) {
+	if len(args) == 0 {
+		fmt.Printf("usage: %s\n", requestsFormatExpected)
+		os.Exit(0)
+	}
+
+	var err error
+	switch args[0] {
+	case "list":
+		err = listSaved()
+	case "save":
+		err = saveRequest(args[1:], flags)
+	case "delete":
+		if len(args) != 2 {
+			fatal("expected %s", requestsFormatExpected)
+		}
+		err = deleteSaved(args[1])
+	default:
+		fatal("unknown requests command %s, expected %s", args[0], requestsFormatExpected)
+	}
+
+	if err != nil {
+		fatal("%v", err)
+	}
+}
+
+func listSaved() error {
+	saved, err := loadSaved()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range saved {
+		where := config.GetDefaultConfigPath()
+		if s.File != "" {
+			where = s.File
+		}
+
+		request := s.Request
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Name, strings.ToUpper(request.Method), request.Alias,
+			strings.Join(request.Path, " "), where)
+	}
+	return w.Flush()
+}
+
+// saveRequest saves the call given after the name, or the last call of the
+// history when there is none.
+func saveRequest(args []string, flags cliFlags) error {
+	fs := flag.NewFlagSet("requests save", flag.ExitOnError)
+	local := fs.Bool("local", false, "Save the request in "+collection.FileName+" instead of under its alias")
+	_ = fs.Parse(args)
+
+	args = fs.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("expected %s", requestsFormatExpected)
+	}
+	name := args[0]
+
+	var request config.SavedRequest
+	var err error
+	if len(args) > 1 {
+		request, err = explicitRequest(args[1:], flags)
+	} else {
+		request, err = lastRequest()
+	}
+	if err != nil {
+		return err
+	}
+
+	where := config.GetDefaultConfigPath()
+	if *local {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if where, err = collection.SaveLocal(dir, name, request); err != nil {
+			return err
+		}
+		if relative, err := filepath.Rel(dir, where); err == nil {
+			where = relative
+		}
+	} else if err := config.SaveRequest(config.URLAlias(request.Alias), name, request); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "saved %s to %s\n", name, where)
+	return nil
+}
+
+// explicitRequest reads a call written as on the command line, with the
+// headers and body of the -header and -data flags.
+func explicitRequest(args []string, flags cliFlags) (config.SavedRequest, error) {
+	action, err := NewAction(args)
+	if err != nil {
+		return config.SavedRequest{}, err
+	}
+	body, err := flags.body()
+	if err != nil {
+		return config.SavedRequest{}, err
+	}
+
+	return config.SavedRequest{
+		Alias:   action.URLAlias,
+		Method:  action.HTTPMethod,
+		Path:    action.URLPathComponents,
+		Options: emptyToNil(action.Options),
+		Headers: flags.headers,
+		Body:    string(body),
+	}, nil
+}
+
+// lastRequest reads the last call of the history, refusing the ones whose
+// secrets were redacted.
+func lastRequest() (config.SavedRequest, error) {
+	entries, err := history.Load()
+	if err != nil {
+		return config.SavedRequest{}, err
+	}
+	if len(entries) == 0 {
+		return config.SavedRequest{}, errors.New("the history is empty, give the call to save")
+	}
+
+	entry := entries[len(entries)-1]
+	redacted := entry.BodyOmitted || strings.Contains(entry.Body, history.Redacted)
+	for _, value := range entry.Options {
+		redacted = redacted || value == history.Redacted
+	}
+	for _, value := range entry.Headers {
+		redacted = redacted || strings.Contains(value, history.Redacted)
+	}
+	if redacted {
+		return config.SavedRequest{}, fmt.Errorf(
+			"the last call, entry %d, was recorded without its secrets, give the call to save", entry.ID)
+	}
+
+	return config.SavedRequest{
+		Alias:   entry.Alias,
+		Method:  entry.Method,
+		Path:    entry.Path,
+		Options: entry.Options,
+		Headers: entry.Headers,
+		Body:    entry.Body,
+	}, nil
+}
+
+func deleteSaved(name string) error {
+	saved, err := loadSaved()
+	if err != nil {
+		return err
+	}
+	found, err := collection.Find(saved, name)
+	if err != nil {
+		return err
+	}
+	return collection.Delete(found)
+}
+
+func emptyToNil(values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ashttp/internal/http"
+	"github.com/ashttp/internal/jsonrpc"
+)
+
+var rpcFormatExpected = "[flags] <URL-alias> rpc (<method> [params...] [--param value] | @batch-file), ids start at 1 on each invocation"
+
+// rpcCall is a JSON-RPC call, or a batch of them read from a file, posted
+// to the alias URL.
+type rpcCall struct {
+	requests []jsonrpc.Request
+	batch    bool
+}
+
+// newRPCCall turns the request built from the arguments into a JSON-RPC
+// one. Path components after the method are positional params and
+// options named ones.
+func newRPCCall(action Action, request *http.Request) (*rpcCall, error) {
+	args := action.URLPathComponents
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expected %s", rpcFormatExpected)
+	}
+
+	call := &rpcCall{}
+	var calls []jsonrpc.Call
+	if path, found := strings.CutPrefix(args[0], "@"); found {
+		if len(args) > 1 || len(action.Options) > 0 {
+			return nil, fmt.Errorf("a batch file takes no params")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if calls, err = jsonrpc.ReadBatch(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		call.batch = true
+	} else {
+		params, err := jsonrpc.Params(args[1:], action.Options)
+		if err != nil {
+			return nil, err
+		}
+		calls = []jsonrpc.Call{{Method: args[0], Params: params}}
+	}
+
+	call.requests = jsonrpc.Requests(calls)
+	body, err := jsonrpc.Body(call.requests, call.batch)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Method = "post"
+	request.Path = ""
+	request.Arguments = nil
+	request.Body = body
+	return call, nil
+}
+
+// print shows the result of a call like any response, and the results of
+// a batch as an array in the order of the file. Errors are printed apart
+// on stderr, exiting with an error status when there are some.
+func (c *rpcCall) print(response *http.Response, flags cliFlags) {
+	results, err := jsonrpc.ParseResponses(response.Body, c.requests)
+	if err != nil {
+		printResponse(response, flags)
+		fatal("%v", err)
+	}
+
+	var body []byte
+	if c.batch {
+		body = []byte("[")
+		for i, result := range results {
+			if i > 0 {
+				body = append(body, ',')
+			}
+			if result.Error != nil {
+				body = append(body, "null"...)
+				continue
+			}
+			body = append(body, result.Result...)
+		}
+		body = append(body, ']')
+	} else if results[0].Error == nil {
+		body = results[0].Result
+	}
+
+	if body != nil {
+		unwrapped := *response
+		unwrapped.Body = body
+		printResponse(&unwrapped, flags)
+	}
+
+	failed := false
+	for i, result := range results {
+		if result.Error == nil {
+			continue
+		}
+		failed = true
+		if c.batch {
+			fmt.Fprintf(os.Stderr, "[rpc error] %s (call %d): %s\n", c.requests[i].Method, i+1, result.Error)
+		} else {
+			fmt.Fprintf(os.Stderr, "[rpc error] %s\n", result.Error)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
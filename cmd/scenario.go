@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ashttp/internal/history"
+	"github.com/ashttp/internal/http"
+	"github.com/ashttp/internal/scenario"
+	"github.com/ashttp/internal/session"
+)
+
+var runFileFormatExpected = "[flags] run-file <scenario.yaml|scenario.json>"
+
+// stepResult is how a scenario step went.
+type stepResult struct {
+	title    string
+	method   string
+	url      string
+	status   int
+	duration time.Duration
+	failures []string
+	skipped  bool
+
+	// request is the request printed instead of being sent, with -dry-run.
+	request string
+
+	response *http.Response
+}
+
+func (r stepResult) failed() bool {
+	return len(r.failures) > 0
+}
+
+// runScenarioFile runs the steps of a scenario in order, each one able to
+// use the values captured by the previous ones, and prints a summary.
+func runScenarioFile(args []string, flags cliFlags) {
+	if len(args) != 1 {
+		fmt.Printf("usage: %s\n", runFileFormatExpected)
+		os.Exit(0)
+	}
+
+	s, err := scenario.Load(args[0])
+	if err != nil {
+		fatal("failed to load scenario: %v", err)
+	}
+
+	if s.Name != "" {
+		fmt.Println(s.Name)
+	}
+	results, elapsed := runScenario(s, flags, os.Stdout)
+	if flags.dryRun {
+		return
+	}
+
+	passed, failed, skipped := countResults(results)
+	fmt.Printf("\n%d passed, %d failed, %d skipped in %s\n", passed, failed, skipped, roundDuration(elapsed))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// runScenario runs the steps of a scenario, printing their results to w.
+func runScenario(s *scenario.Scenario, flags cliFlags, w io.Writer) ([]stepResult, time.Duration) {
+	vars, err := flags.templateVars()
+	if err != nil {
+		fatal("failed to read template variables: %v", err)
+	}
+	// The scenario variables take precedence over the session and -vars,
+	// but not over -var.
+	for name, value := range s.Vars {
+		if _, given := flags.vars[name]; !given {
+			vars.set(name, value)
+		}
+	}
+
+	started := time.Now()
+	results := make([]stepResult, 0, len(s.Steps))
+	stopped := false
+	for i, step := range s.Steps {
+		result := stepResult{title: step.Title(i + 1), skipped: stopped}
+		if !stopped {
+			result = runStep(step, result, vars, flags)
+			stopped = result.failed() && s.StopsOnFailure(step)
+		}
+
+		printStepResult(w, result, flags)
+		results = append(results, result)
+	}
+
+	return results, time.Since(started)
+}
+
+func countResults(results []stepResult) (passed, failed, skipped int) {
+	for _, result := range results {
+		switch {
+		case result.skipped:
+			skipped++
+		case result.failed():
+			failed++
+		default:
+			passed++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// runStep sends the request of a step through the same pipeline as the
+// command line, then checks its expectations and captures its values.
+func runStep(step scenario.Step, result stepResult, vars *templateVars, flags cliFlags) stepResult {
+	fail := func(format string, v ...any) stepResult {
+		result.failures = append(result.failures, fmt.Sprintf(format, v...))
+		return result
+	}
+
+	action := Action{
+		URLAlias:          step.Alias,
+		HTTPMethod:        strings.ToLower(step.Method),
+		URLPathComponents: slices.Clone(step.Path),
+		Options:           maps.Clone(step.Options),
+	}
+	result.method = strings.ToUpper(action.HTTPMethod)
+	if !slices.Contains(acceptedMethods, action.HTTPMethod) {
+		return fail("unsupported method %s, scenarios support %s", step.Method, strings.Join(acceptedMethods, ", "))
+	}
+
+	if err := renderAction(&action, vars); err != nil {
+		return fail("failed to render %v", err)
+	}
+	request := action.Request()
+	request.Headers = maps.Clone(step.Headers)
+	request.Body = []byte(step.Body)
+	if err := renderRequest(&request, vars); err != nil {
+		return fail("failed to render %v", err)
+	}
+
+	setting, err := action.Setting()
+	if err != nil {
+		return fail("failed to load setting: %v", err)
+	}
+	flags.override(&setting)
+
+	req, err := request.ToHTTPRequest(setting)
+	if err != nil {
+		return fail("failed to build request: %v", err)
+	}
+	// The URL ends up in reports archived by CI, so its secrets are hidden
+	// as in the history.
+	result.url = req.URL.String()
+	if !flags.showSecrets {
+		result.url = history.RedactURL(result.url)
+	}
+
+	if flags.dryRun || flags.printRequest {
+		dump, err := http.DumpRequest(req, flags.showSecrets)
+		if err != nil {
+			return fail("failed to print request: %v", err)
+		}
+		if flags.dryRun {
+			// The following steps show where the captured values go.
+			for name := range step.Capture {
+				vars.set(name, "$"+name)
+			}
+			result.request = dump
+			return result
+		}
+		fmt.Fprintln(os.Stderr, dump)
+	}
+
+	record := newHistoryRecord(action, request, req, false, flags)
+	response, err := http.Execute(req, setting)
+	if err != nil {
+		record.save(0, time.Since(record.entry.Time), err)
+		return fail("failed to execute request: %v", err)
+	}
+	record.save(response.StatusCode, response.Timing.Total, nil)
+
+	result.status = response.StatusCode
+	result.duration = response.Timing.Total
+	result.response = response
+	// The -expect flags apply to every step, on top of its own expectations.
+	expect := step.Expect
+	expect.Merge(flags.expect)
+	result.failures = expect.Check(response)
+
+	for _, name := range sortedKeys(step.Capture) {
+		value, err := session.Capture(response.Body, step.Capture[name])
+		if err != nil {
+			result.failures = append(result.failures, fmt.Sprintf("failed to capture %s: %v", name, err))
+			continue
+		}
+		maps.Copy(vars.values, session.Values{name: value}.Vars())
+	}
+
+	return result
+}
+
+func printStepResult(w io.Writer, result stepResult, flags cliFlags) {
+	switch {
+	case result.request != "":
+		fmt.Fprintf(w, "DRY   %s\n", result.title)
+		for line := range strings.Lines(result.request) {
+			if line != "\n" {
+				line = "      " + line
+			}
+			fmt.Fprint(w, line)
+		}
+		return
+	case result.skipped:
+		fmt.Fprintf(w, "SKIP  %s\n", result.title)
+		return
+	case result.failed():
+		fmt.Fprint(w, "FAIL  ")
+	default:
+		fmt.Fprint(w, "PASS  ")
+	}
+
+	fmt.Fprint(w, result.title)
+	if result.url != "" {
+		fmt.Fprintf(w, "  %s %s", result.method, result.url)
+	}
+	if result.status != 0 {
+		fmt.Fprintf(w, "  %d  %s", result.status, roundDuration(result.duration))
+	}
+	fmt.Fprintln(w)
+
+	for _, failure := range result.failures {
+		fmt.Fprintf(w, "      %s\n", failure)
+	}
+
+	// The response of a failed step helps to understand what went wrong.
+	if flags.verbose && result.failed() && result.response != nil && len(result.response.Body) > 0 {
+		rendered, err := flags.render(result.response.Body, result.response.Header.Get("Content-Type"))
+		if err != nil {
+			rendered = string(result.response.Body)
+		}
+		for line := range strings.Lines(rendered) {
+			fmt.Fprintf(w, "      | %s", line)
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ashttp/internal/http"
+	"github.com/ashttp/internal/session"
+)
+
+var sessionFormatExpected = "session (list | clear [names...] | export [-format json|env])"
+
+// captureResponse stores the values asked with -capture in the session.
+// Nothing is stored unless every capture succeeds.
+func captureResponse(response *http.Response, flags cliFlags) error {
+	if len(flags.captures) == 0 {
+		return nil
+	}
+
+	values := session.Values{}
+	for _, name := range sortedKeys(flags.captures) {
+		value, err := session.Capture(response.Body, flags.captures[name])
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		values[name] = value
+		if flags.verbose {
+			fmt.Fprintf(os.Stderr, "[verbose] captured %s = %s\n", name, value)
+		}
+	}
+
+	return session.Set(values)
+}
+
+// runSession lists, clears and exports the values captured in the session.
+func runSession(args []string) {
+	if len(args) == 0 {
+		fmt.Printf("usage: %s\n", sessionFormatExpected)
+		os.Exit(0)
+	}
+
+	var err error
+	switch args[0] {
+	case "list":
+		err = listSession()
+	case "clear":
+		err = session.Clear(args[1:])
+	case "export":
+		err = exportSession(args[1:])
+	default:
+		fatal("unknown session command %s, expected %s", args[0], sessionFormatExpected)
+	}
+
+	if err != nil {
+		fatal("%v", err)
+	}
+}
+
+func listSession() error {
+	values, err := session.Load()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, name := range values.Names() {
+		fmt.Fprintf(w, "%s\t%s\n", name, values.Text(name))
+	}
+	return w.Flush()
+}
+
+// exportSession prints the values as JSON, which -vars reads back, or as
+// shell variables.
+func exportSession(args []string) error {
+	fs := flag.NewFlagSet("session export", flag.ExitOnError)
+	format := fs.String("format", "json", "Export format: json, as read by -vars, or env, as name='value' lines")
+	_ = fs.Parse(args)
+
+	values, err := session.Load()
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case "env":
+		fmt.Print(values.Env())
+	default:
+		return fmt.Errorf("unknown export format %q, expected json or env", *format)
+	}
+	return nil
+}
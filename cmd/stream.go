@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"os"
+
+	"github.com/ashttp/internal/config"
+	"github.com/ashttp/internal/http"
+	"github.com/ashttp/internal/output"
+	"github.com/ashttp/internal/stream"
+)
+
+// runStream prints a response as it arrives: NDJSON line by line, events
+// one by one and anything else chunk by chunk. It returns quietly once ctx
+// is cancelled by Ctrl-C.
+func runStream(ctx context.Context, req *nethttp.Request, setting config.Setting, opened *http.Stream,
+	mode stream.Mode, flags cliFlags) {
+	if flags.include {
+		fmt.Println(output.Head(opened.Proto, opened.Status, opened.StatusCode, opened.Header, flags.colored))
+	}
+
+	var err error
+	switch mode {
+	case stream.NDJSON:
+		err = stream.Lines(opened.Body, func(line []byte) error {
+			printStreamed(line, "application/json", flags)
+			return nil
+		})
+		opened.Body.Close()
+	case stream.SSE:
+		err = stream.Subscribe(ctx, req, setting, opened.Body, func(event stream.Event) error {
+			printEvent(event, flags)
+			return nil
+		})
+	default:
+		_, err = io.Copy(os.Stdout, opened.Body)
+		opened.Body.Close()
+	}
+
+	if err != nil && ctx.Err() == nil {
+		fatal("stream interrupted: %v", err)
+	}
+
+	if flags.timing || flags.verbose {
+		if err := printTiming(opened.Timing(), flags.timingFormat); err != nil {
+			fatal("failed to print timing: %v", err)
+		}
+	}
+}
+
+// printStreamed prints one line or event. A part that cannot be rendered
+// is reported without ending the stream.
+func printStreamed(data []byte, contentType string, flags cliFlags) {
+	rendered, err := flags.render(data, contentType)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[warning] %v\n", err)
+		return
+	}
+
+	if flags.colored {
+		rendered = output.Highlight(rendered, flags.output)
+	}
+	fmt.Println(rendered)
+}
+
+func printEvent(event stream.Event, flags cliFlags) {
+	if event.Type != "" {
+		fmt.Println("event: " + event.Type)
+	}
+	if event.ID != "" {
+		fmt.Println("id: " + event.ID)
+	}
+	printStreamed([]byte(event.Data), "", flags)
+	fmt.Println()
+}
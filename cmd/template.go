@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ashttp/internal/http"
+	"github.com/ashttp/internal/session"
+	"github.com/ashttp/internal/templating"
+)
+
+// templateVars are the variables of the templates. The values captured in
+// the session are only read once a template uses a variable defined nowhere
+// else, so a damaged session file only breaks the requests needing it.
+type templateVars struct {
+	values        templating.Vars
+	sessionLoaded bool
+}
+
+// set defines a variable, taking precedence over the session.
+func (v *templateVars) set(name string, value any) {
+	v.values[name] = value
+}
+
+func (v *templateVars) render(text string) (string, error) {
+	if !v.sessionLoaded && slices.ContainsFunc(templating.Variables(text), v.undefined) {
+		stored, err := session.Load()
+		if err != nil {
+			return "", err
+		}
+		for name, value := range stored.Vars() {
+			if v.undefined(name) {
+				v.values[name] = value
+			}
+		}
+		v.sessionLoaded = true
+	}
+
+	return templating.Render(text, v.values)
+}
+
+func (v *templateVars) undefined(name string) bool {
+	_, defined := v.values[name]
+	return !defined
+}
+
+// renderAction renders the templates of the path components and option
+// values. Errors name the argument at fault.
+func renderAction(action *Action, vars *templateVars) error {
+	for i, component := range action.URLPathComponents {
+		rendered, err := vars.render(component)
+		if err != nil {
+			return fmt.Errorf("path component %d %q: %w", i+1, component, err)
+		}
+		action.URLPathComponents[i] = rendered
+	}
+
+	for _, name := range sortedKeys(action.Options) {
+		rendered, err := vars.render(action.Options[name])
+		if err != nil {
+			return fmt.Errorf("option --%s %q: %w", name, action.Options[name], err)
+		}
+		action.Options[name] = rendered
+	}
+
+	return nil
+}
+
+// renderRequest renders the templates of the headers and body.
+func renderRequest(request *http.Request, vars *templateVars) error {
+	for _, name := range sortedKeys(request.Headers) {
+		rendered, err := vars.render(request.Headers[name])
+		if err != nil {
+			return fmt.Errorf("header %s %q: %w", name, request.Headers[name], err)
+		}
+		request.Headers[name] = rendered
+	}
+
+	body, err := vars.render(string(request.Body))
+	if err != nil {
+		return fmt.Errorf("body: %w", err)
+	}
+	request.Body = []byte(body)
+
+	return nil
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
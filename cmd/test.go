@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ashttp/internal/report"
+	"github.com/ashttp/internal/scenario"
+)
+
+var testFormatExpected = "[flags] test [-junit file] [-tap file] <scenario files...>"
+
+// runTests runs scenarios as test suites, their steps being the test cases,
+// and writes the reports continuous integration servers read.
+func runTests(args []string, flags cliFlags) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf("usage: %s\n", testFormatExpected)
+		fs.PrintDefaults()
+	}
+	junit := fs.String("junit", "", "Write a JUnit XML report to this file, - for stdout")
+	tap := fs.String("tap", "", "Write a TAP report to this file, - for stdout")
+	_ = fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(0)
+	}
+	if *junit == "-" && *tap == "-" {
+		fatal("only one of the reports can be written to stdout")
+	}
+	if flags.dryRun {
+		fatal("-dry-run cannot be used with test, run-file -dry-run prints the requests of a scenario")
+	}
+
+	// All scenarios are loaded first so a mistake in the last one is found
+	// before any request is sent.
+	scenarios := make([]*scenario.Scenario, fs.NArg())
+	for i, path := range fs.Args() {
+		s, err := scenario.Load(path)
+		if err != nil {
+			fatal("failed to load scenario: %v", err)
+		}
+		if s.Name == "" {
+			s.Name = path
+		}
+		scenarios[i] = s
+	}
+
+	// A report written to stdout replaces the results as they go.
+	var w io.Writer = os.Stdout
+	if *junit == "-" || *tap == "-" {
+		w = io.Discard
+	}
+
+	var suites []report.Suite
+	var elapsed time.Duration
+	passed, failed, skipped := 0, 0, 0
+	for i, s := range scenarios {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, s.Name)
+
+		results, duration := runScenario(s, flags, w)
+		suites = append(suites, newSuite(s.Name, results, duration))
+		elapsed += duration
+
+		p, f, sk := countResults(results)
+		passed, failed, skipped = passed+p, failed+f, skipped+sk
+	}
+	fmt.Fprintf(w, "\n%d passed, %d failed, %d skipped in %s\n", passed, failed, skipped, roundDuration(elapsed))
+
+	if err := writeReport(*junit, suites, report.JUnit); err != nil {
+		fatal("failed to write JUnit report: %v", err)
+	}
+	if err := writeReport(*tap, suites, report.TAP); err != nil {
+		fatal("failed to write TAP report: %v", err)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func newSuite(name string, results []stepResult, duration time.Duration) report.Suite {
+	suite := report.Suite{Name: name, Duration: duration}
+	for _, result := range results {
+		c := report.Case{
+			Name:     result.title,
+			Status:   result.status,
+			Duration: result.duration,
+			Failures: result.failures,
+			Skipped:  result.skipped,
+		}
+		if result.url != "" {
+			c.Request = result.method + " " + result.url
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	return suite
+}
+
+// writeReport writes the report to path, stdout being "-", if a path is
+// given.
+func writeReport(path string, suites []report.Suite, write func(io.Writer, []report.Suite) error) error {
+	switch path {
+	case "":
+		return nil
+	case "-":
+		return write(os.Stdout, suites)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, suites); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
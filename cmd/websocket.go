@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ashttp/internal/config"
+	"github.com/ashttp/internal/http"
+	"github.com/ashttp/internal/output"
+	"github.com/ashttp/internal/websocket"
+)
+
+// closeWait bounds how long the server has to confirm a close.
+const closeWait = 2 * time.Second
+
+// runWebSocket opens a WebSocket on the alias. With -data it sends that
+// message and prints -replies answers, otherwise each line read from stdin
+// is sent and incoming messages are printed until either side closes.
+func runWebSocket(action Action, request http.Request, setting config.Setting, flags cliFlags) {
+	sent := request
+	message := request.Body
+	request.Body = nil
+	request.Method = nethttp.MethodGet
+
+	req, err := request.ToHTTPRequest(setting)
+	if err != nil {
+		fatal("failed to build request: %v", err)
+	}
+	req.Header.Del("Content-Type")
+
+	// A dry run must not connect, the handshake is printed instead.
+	if flags.exporter() != nil {
+		fatal("-as-curl, -as-httpie and -as-fetch cannot export ws sessions")
+	}
+	if flags.dryRun || flags.printRequest {
+		handshake := req.Clone(req.Context())
+		if _, err := websocket.PrepareHandshake(handshake); err != nil {
+			fatal("failed to build request: %v", err)
+		}
+		showRequest(handshake, message, flags)
+	}
+
+	// A session may last for hours, its duration is the time to the handshake.
+	record := newHistoryRecord(action, sent, req, false, flags)
+	record.entry.URL = websocket.URL(req.URL)
+
+	conn, resp, err := websocket.Dial(req, setting)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		record.save(status, time.Since(record.entry.Time), err)
+		fatal("failed to open websocket: %v", err)
+	}
+	record.save(resp.StatusCode, time.Since(record.entry.Time), nil)
+	defer conn.Close()
+
+	fmt.Fprintf(os.Stderr, "connected to %s\n", websocket.URL(req.URL))
+	if flags.include {
+		fmt.Println(output.Head(resp.Proto, resp.Status, resp.StatusCode, resp.Header, flags.colored))
+	}
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+
+	replies := 0
+	var timeout <-chan time.Time
+	inputDone := make(chan struct{})
+	if len(message) > 0 {
+		replies = flags.replies
+		if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			fatal("failed to send message: %v", err)
+		}
+		if setting.Timeouts != nil && setting.Timeouts.Total > 0 {
+			timeout = time.After(time.Duration(setting.Timeouts.Total))
+		}
+	} else {
+		go func() {
+			sendLines(conn, os.Stdin)
+			close(inputDone)
+		}()
+	}
+
+	received := make(chan error, 1)
+	go func() {
+		received <- receive(conn, replies, flags)
+	}()
+
+	select {
+	case err := <-received:
+		if err != nil {
+			fatal("websocket: %v", err)
+		}
+		if replies > 0 {
+			// All the replies arrived, the server has not closed yet.
+			_ = conn.WriteClose(websocket.CloseNormal, "")
+			select {
+			case <-drain(conn):
+			case <-time.After(closeWait):
+			}
+		}
+		return
+	case <-timeout:
+		fatal("websocket: %d replies not received within %s", replies, time.Duration(setting.Timeouts.Total))
+	case <-interrupted:
+		_ = conn.WriteClose(websocket.CloseGoingAway, "")
+	case <-inputDone:
+		_ = conn.WriteClose(websocket.CloseNormal, "")
+	}
+
+	// Let the server confirm the close, printing what it still sends.
+	select {
+	case <-received:
+	case <-time.After(closeWait):
+	}
+}
+
+// drain discards messages until the server confirms the close.
+func drain(conn *websocket.Conn) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// sendLines sends each line of r as a text message.
+func sendLines(conn *websocket.Conn, r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, scanner.Bytes()); err != nil {
+			fmt.Fprintf(os.Stderr, "[warning] failed to send message: %v\n", err)
+			return
+		}
+	}
+}
+
+// receive prints incoming messages until the connection closes, or until
+// limit messages were printed when limit is positive.
+func receive(conn *websocket.Conn, limit int, flags cliFlags) error {
+	for count := 0; limit <= 0 || count < limit; count++ {
+		message, err := conn.ReadMessage()
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) && closeErr.Normal() {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case message.Type == websocket.TextMessage:
+			printStreamed(message.Data, "", flags)
+		case flags.output == output.FormatRaw:
+			_, _ = os.Stdout.Write(message.Data)
+		default:
+			fmt.Printf("[binary message, %s]\n", output.FormatSize(int64(len(message.Data))))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+// Package assert checks responses against expectations: status, headers,
+// JSON values, latency and a JSON Schema.
+package assert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashttp/internal/filter"
+	"github.com/ashttp/internal/http"
+	"github.com/ashttp/internal/jsonvalue"
+)
+
+// Assertions is what a response must satisfy. Without any status, the
+// status must be below 400.
+type Assertions struct {
+	Status     Statuses          `yaml:"status"`
+	Headers    map[string]string `yaml:"headers"`
+	JSON       []JSONAssertion   `yaml:"json"`
+	MaxLatency Duration          `yaml:"maxLatency"`
+	Schema     *Schema           `yaml:"schema"`
+}
+
+// JSONAssertion expects the single value path selects to equal a value,
+// or its text to match a regular expression.
+type JSONAssertion struct {
+	Path    string
+	Equals  any
+	Matches string
+
+	// hasEquals tells equality with null from no equality at all.
+	hasEquals bool
+}
+
+// Empty reports whether there is nothing to check.
+func (a Assertions) Empty() bool {
+	return len(a.Status) == 0 && len(a.Headers) == 0 && len(a.JSON) == 0 && a.MaxLatency == 0 && a.Schema == nil
+}
+
+// Merge adds the assertions of other, which take precedence for the
+// status, latency and schema.
+func (a *Assertions) Merge(other Assertions) {
+	if len(other.Status) > 0 {
+		a.Status = other.Status
+	}
+	if other.MaxLatency > 0 {
+		a.MaxLatency = other.MaxLatency
+	}
+	if other.Schema != nil {
+		a.Schema = other.Schema
+	}
+
+	if len(other.Headers) > 0 {
+		headers := make(map[string]string, len(a.Headers)+len(other.Headers))
+		for name, pattern := range a.Headers {
+			headers[name] = pattern
+		}
+		for name, pattern := range other.Headers {
+			headers[name] = pattern
+		}
+		a.Headers = headers
+	}
+	a.JSON = append(slices.Clip(a.JSON), other.JSON...)
+}
+
+// Validate compiles the expressions and regular expressions, so mistakes
+// are found before any request is sent.
+func (a Assertions) Validate() error {
+	for name, pattern := range a.Headers {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("header %s: %w", name, err)
+		}
+	}
+
+	for _, j := range a.JSON {
+		if _, err := filter.Parse(j.Path); err != nil {
+			return fmt.Errorf("json path %s: %w", j.Path, err)
+		}
+		if !j.hasEquals && j.Matches == "" {
+			return fmt.Errorf("json path %s: expected equals or matches", j.Path)
+		}
+		if _, err := regexp.Compile(j.Matches); err != nil {
+			return fmt.Errorf("json path %s: %w", j.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// Check returns why response does not satisfy the assertions, if it does
+// not.
+func (a Assertions) Check(response *http.Response) []string {
+	var failures []string
+
+	switch {
+	case len(a.Status) == 0 && response.StatusCode >= 400:
+		failures = append(failures, fmt.Sprintf("expected a status below 400, got %d", response.StatusCode))
+	case len(a.Status) > 0 && !slices.Contains(a.Status, response.StatusCode):
+		failures = append(failures, fmt.Sprintf("expected status %s, got %d", a.Status, response.StatusCode))
+	}
+
+	names := make([]string, 0, len(a.Headers))
+	for name := range a.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		values := response.Header.Values(name)
+		if len(values) == 0 {
+			failures = append(failures, fmt.Sprintf("expected header %s, it is missing", name))
+			continue
+		}
+
+		value := strings.Join(values, ", ")
+		if matched, err := regexp.MatchString(a.Headers[name], value); err != nil || !matched {
+			failures = append(failures, fmt.Sprintf("expected header %s to match %s, got %q", name, a.Headers[name], value))
+		}
+	}
+
+	if a.MaxLatency > 0 && response.Timing.Total > time.Duration(a.MaxLatency) {
+		failures = append(failures, fmt.Sprintf("expected a latency up to %s, took %s",
+			time.Duration(a.MaxLatency), response.Timing.Total.Round(time.Millisecond)))
+	}
+
+	if len(a.JSON) == 0 && a.Schema == nil {
+		return failures
+	}
+
+	body, err := jsonvalue.Decode(response.Body)
+	if err != nil {
+		return append(failures, fmt.Sprintf("expected a JSON response: %v", err))
+	}
+	for _, j := range a.JSON {
+		if failure := j.check(body); failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+
+	if a.Schema != nil {
+		for _, violation := range a.Schema.Validate(response.Body) {
+			failures = append(failures, "schema: "+violation)
+		}
+	}
+
+	return failures
+}
+
+func (j JSONAssertion) check(body any) string {
+	query, err := filter.Parse(j.Path)
+	if err != nil {
+		return fmt.Sprintf("%s: %v", j.Path, err)
+	}
+
+	results, err := query.Apply(body)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%s: %v", j.Path, err)
+	case len(results) != 1:
+		return fmt.Sprintf("expected %s to select one value, got %d", j.Path, len(results))
+	}
+
+	actual, err := jsonvalue.Marshal(results[0])
+	if err != nil {
+		return fmt.Sprintf("%s: %v", j.Path, err)
+	}
+
+	if j.hasEquals {
+		expected, err := json.Marshal(j.Equals)
+		if err != nil {
+			return fmt.Sprintf("%s: %v", j.Path, err)
+		}
+		if !jsonEqual(actual, expected) {
+			return fmt.Sprintf("expected %s to equal %s, got %s", j.Path, expected, actual)
+		}
+	}
+
+	if j.Matches != "" {
+		text := string(actual)
+		if s, ok := results[0].(string); ok {
+			text = s
+		}
+		if matched, err := regexp.MatchString(j.Matches, text); err != nil || !matched {
+			return fmt.Sprintf("expected %s to match %s, got %s", j.Path, j.Matches, actual)
+		}
+	}
+
+	return ""
+}
+
+// jsonEqual compares two JSON documents whatever their key order and
+// number formatting.
+func jsonEqual(a, b []byte) bool {
+	var decodedA, decodedB any
+	if json.Unmarshal(a, &decodedA) != nil || json.Unmarshal(b, &decodedB) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(decodedA, decodedB)
+}
+
+// ParseStatuses reads a comma separated list of statuses, such as 200,204.
+func ParseStatuses(s string) (Statuses, error) {
+	var statuses Statuses
+	for field := range strings.SplitSeq(s, ",") {
+		status, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || status < 100 || status > 999 {
+			return nil, fmt.Errorf("invalid status %q", strings.TrimSpace(field))
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// ParseJSONAssertion reads "path == value", the value being JSON or else
+// a string, or "path =~ regex".
+func ParseJSONAssertion(s string) (JSONAssertion, error) {
+	equals, matches := strings.LastIndex(s, " == "), strings.LastIndex(s, " =~ ")
+	if equals < 0 && matches < 0 {
+		return JSONAssertion{}, fmt.Errorf("expected \"path == value\" or \"path =~ regex\", got %q", s)
+	}
+
+	var j JSONAssertion
+	if equals > matches {
+		j.Path = strings.TrimSpace(s[:equals])
+		value := strings.TrimSpace(s[equals+4:])
+		if err := json.Unmarshal([]byte(value), &j.Equals); err != nil {
+			j.Equals = value
+		}
+		j.hasEquals = true
+	} else {
+		j.Path = strings.TrimSpace(s[:matches])
+		j.Matches = strings.TrimSpace(s[matches+4:])
+	}
+
+	if _, err := filter.Parse(j.Path); err != nil {
+		return JSONAssertion{}, err
+	}
+	if _, err := regexp.Compile(j.Matches); err != nil {
+		return JSONAssertion{}, err
+	}
+	return j, nil
+}
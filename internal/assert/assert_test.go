@@ -0,0 +1,188 @@
+package assert
+
+import (
+	nethttp "net/http"
+	"testing"
+	"time"
+
+	"github.com/ashttp/internal/http"
+	"github.com/stretchr/testify/require"
+)
+
+func mustJSONAssertion(t *testing.T, s string) JSONAssertion {
+	t.Helper()
+	j, err := ParseJSONAssertion(s)
+	require.NoError(t, err)
+	return j
+}
+
+func TestCheck(t *testing.T) {
+	response := &http.Response{
+		StatusCode: 201,
+		Header:     nethttp.Header{"Content-Type": {"application/json; charset=utf-8"}},
+		Body:       []byte(`{"data": {"id": 42, "name": "book", "price": 1.50, "tags": ["a", "b"], "note": null}}`),
+		Timing:     http.Timing{Total: 120 * time.Millisecond},
+	}
+
+	tests := []struct {
+		name       string
+		assertions Assertions
+		expected   []string
+	}{
+		{name: "default success"},
+		{name: "status", assertions: Assertions{Status: Statuses{200, 201}}},
+		{
+			name:       "unexpected status",
+			assertions: Assertions{Status: Statuses{200, 204}},
+			expected:   []string{"expected status 200 or 204, got 201"},
+		},
+		{name: "header", assertions: Assertions{Headers: map[string]string{"content-type": "^application/json"}}},
+		{
+			name:       "header mismatch",
+			assertions: Assertions{Headers: map[string]string{"Content-Type": "xml"}},
+			expected:   []string{`expected header Content-Type to match xml, got "application/json; charset=utf-8"`},
+		},
+		{
+			name:       "missing header",
+			assertions: Assertions{Headers: map[string]string{"ETag": "."}},
+			expected:   []string{"expected header ETag, it is missing"},
+		},
+		{name: "latency", assertions: Assertions{MaxLatency: Duration(time.Second)}},
+		{
+			name:       "slow",
+			assertions: Assertions{MaxLatency: Duration(100 * time.Millisecond)},
+			expected:   []string{"expected a latency up to 100ms, took 120ms"},
+		},
+		{
+			name: "json",
+			assertions: Assertions{JSON: []JSONAssertion{
+				mustJSONAssertion(t, ".data.id == 42"),
+				mustJSONAssertion(t, ".data.price == 1.5"),
+				mustJSONAssertion(t, ".data.name == book"),
+				mustJSONAssertion(t, `.data.tags == ["a","b"]`),
+				mustJSONAssertion(t, ".data.note == null"),
+				mustJSONAssertion(t, ".data.name =~ ^bo"),
+				mustJSONAssertion(t, ".data.id =~ ^4"),
+			}},
+		},
+		{
+			name: "json mismatch",
+			assertions: Assertions{JSON: []JSONAssertion{
+				mustJSONAssertion(t, ".data.id == 43"),
+				mustJSONAssertion(t, `.data.name =~ ^pen`),
+				mustJSONAssertion(t, ".data.tags[] == a"),
+			}},
+			expected: []string{
+				"expected .data.id to equal 43, got 42",
+				`expected .data.name to match ^pen, got "book"`,
+				"expected .data.tags[] to select one value, got 2",
+			},
+		},
+		{
+			name:       "schema",
+			assertions: Assertions{Schema: mustSchema(t, `{"properties": {"data": {"required": ["id", "sku"]}}}`)},
+			expected:   []string{"schema: $.data: missing required property sku"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.assertions.Check(response))
+		})
+	}
+}
+
+func TestCheckNotJSON(t *testing.T) {
+	assertions := Assertions{JSON: []JSONAssertion{mustJSONAssertion(t, ".id == 1")}}
+	failures := assertions.Check(&http.Response{StatusCode: 500, Body: []byte("oops")})
+	require.Len(t, failures, 2)
+	require.Equal(t, "expected a status below 400, got 500", failures[0])
+	require.Contains(t, failures[1], "expected a JSON response")
+}
+
+func TestMerge(t *testing.T) {
+	a := Assertions{
+		Status:  Statuses{201},
+		Headers: map[string]string{"ETag": ".", "Location": "orders"},
+		JSON:    []JSONAssertion{{Path: ".id", Matches: "."}},
+	}
+	a.Merge(Assertions{
+		Status:     Statuses{200},
+		Headers:    map[string]string{"Location": "items"},
+		JSON:       []JSONAssertion{{Path: ".name", Matches: "."}},
+		MaxLatency: Duration(time.Second),
+	})
+
+	require.Equal(t, Assertions{
+		Status:     Statuses{200},
+		Headers:    map[string]string{"ETag": ".", "Location": "items"},
+		JSON:       []JSONAssertion{{Path: ".id", Matches: "."}, {Path: ".name", Matches: "."}},
+		MaxLatency: Duration(time.Second),
+	}, a)
+	require.False(t, a.Empty())
+	require.True(t, Assertions{}.Empty())
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		assertions Assertions
+		err        string
+	}{
+		{name: "valid", assertions: Assertions{JSON: []JSONAssertion{{Path: ".id", Matches: `^\d+$`}}}},
+		{name: "header", assertions: Assertions{Headers: map[string]string{"ETag": "("}}, err: "header ETag"},
+		{name: "path", assertions: Assertions{JSON: []JSONAssertion{{Path: ".[", Matches: "."}}}, err: "json path .["},
+		{name: "no check", assertions: Assertions{JSON: []JSONAssertion{{Path: ".id"}}}, err: "expected equals or matches"},
+		{name: "regex", assertions: Assertions{JSON: []JSONAssertion{{Path: ".id", Matches: "("}}}, err: "json path .id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.assertions.Validate()
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseStatuses(t *testing.T) {
+	statuses, err := ParseStatuses("200, 204")
+	require.NoError(t, err)
+	require.Equal(t, Statuses{200, 204}, statuses)
+
+	_, err = ParseStatuses("200,ok")
+	require.EqualError(t, err, `invalid status "ok"`)
+	_, err = ParseStatuses("20")
+	require.EqualError(t, err, `invalid status "20"`)
+}
+
+func TestParseJSONAssertion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected JSONAssertion
+		err      string
+	}{
+		{input: ".id == 42", expected: JSONAssertion{Path: ".id", Equals: float64(42), hasEquals: true}},
+		{input: ".name == book", expected: JSONAssertion{Path: ".name", Equals: "book", hasEquals: true}},
+		{input: ".note == null", expected: JSONAssertion{Path: ".note", hasEquals: true}},
+		{input: `.id =~ ^\d+$`, expected: JSONAssertion{Path: ".id", Matches: `^\d+$`}},
+		{input: ".id", err: `expected "path == value" or "path =~ regex"`},
+		{input: ".id =~ (", err: "missing closing )"},
+		{input: ".[ == 1", err: "."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			j, err := ParseJSONAssertion(tt.input)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, j)
+		})
+	}
+}
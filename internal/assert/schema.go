@@ -0,0 +1,482 @@
+package assert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a JSON Schema. The keywords checked are type, enum, const,
+// the string, number, object and array constraints, allOf, anyOf, oneOf,
+// not and $ref within the schema. Others, such as format, are ignored.
+type Schema struct {
+	// Path is the file the schema is read from, if any.
+	Path string
+
+	root any
+}
+
+// maxDepth stops recursive references that never end.
+const maxDepth = 64
+
+// LoadSchema reads a schema file.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := ParseSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	schema.Path = path
+	return schema, nil
+}
+
+// ParseSchema reads a schema written as JSON.
+func ParseSchema(data []byte) (*Schema, error) {
+	root, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("the schema is not JSON: %w", err)
+	}
+
+	switch root.(type) {
+	case map[string]any, bool:
+		return &Schema{root: root}, nil
+	}
+	return nil, fmt.Errorf("a schema is an object or a boolean")
+}
+
+// Load reads the file of a schema given by its path, relative to dir.
+// Inline schemas are left as they are.
+func (s *Schema) Load(dir string) error {
+	if s.root != nil {
+		return nil
+	}
+
+	path := s.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	loaded, err := LoadSchema(path)
+	if err != nil {
+		return err
+	}
+	s.root = loaded.root
+	return nil
+}
+
+// Validate returns where and why body does not follow the schema.
+func (s *Schema) Validate(body []byte) []string {
+	value, err := decode(body)
+	if err != nil {
+		return []string{fmt.Sprintf("the response is not JSON: %v", err)}
+	}
+
+	v := &validator{root: s.root}
+	v.validate(s.root, value, "$", 0)
+	return v.violations
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// normalize turns a value decoded from YAML into the types decode gives.
+func normalize(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	normalized, err := decode(data)
+	if err != nil {
+		return value
+	}
+	return normalized
+}
+
+type validator struct {
+	root       any
+	violations []string
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.violations = append(v.violations, path+": "+fmt.Sprintf(format, args...))
+}
+
+// passes reports whether value follows schema, without recording why not.
+func (v *validator) passes(schema, value any, path string, depth int) bool {
+	sub := &validator{root: v.root}
+	sub.validate(schema, value, path, depth)
+	return len(sub.violations) == 0
+}
+
+func (v *validator) validate(schema, value any, path string, depth int) {
+	if depth > maxDepth {
+		v.fail(path, "the schema references itself too deeply")
+		return
+	}
+
+	s, ok := schema.(map[string]any)
+	if !ok {
+		if allowed, isBool := schema.(bool); isBool && !allowed {
+			v.fail(path, "no value is allowed")
+		}
+		return
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "%v", err)
+			return
+		}
+		v.validate(target, value, path, depth+1)
+	}
+
+	if !v.validateType(s, value, path) {
+		// Further violations would only repeat the type mismatch.
+		return
+	}
+
+	if enum, ok := s["enum"].([]any); ok && !containsValue(enum, value) {
+		v.fail(path, "expected one of %s, got %s", compact(enum), compact(value))
+	}
+	if constant, ok := s["const"]; ok && !equalValues(constant, value) {
+		v.fail(path, "expected %s, got %s", compact(constant), compact(value))
+	}
+
+	switch value := value.(type) {
+	case string:
+		v.validateString(s, value, path)
+	case json.Number:
+		v.validateNumber(s, value, path)
+	case map[string]any:
+		v.validateObject(s, value, path, depth)
+	case []any:
+		v.validateArray(s, value, path, depth)
+	}
+
+	v.validateCombinations(s, value, path, depth)
+}
+
+func (v *validator) validateType(s map[string]any, value any, path string) bool {
+	var types []string
+	switch t := s["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+	default:
+		return true
+	}
+
+	actual := typeOf(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+
+	v.fail(path, "expected %s, got %s", strings.Join(types, " or "), actual)
+	return false
+}
+
+func (v *validator) validateString(s map[string]any, value, path string) {
+	length := utf8.RuneCountInString(value)
+	if limit, ok := number(s["minLength"]); ok && float64(length) < limit {
+		v.fail(path, "expected at least %v characters, got %d", limit, length)
+	}
+	if limit, ok := number(s["maxLength"]); ok && float64(length) > limit {
+		v.fail(path, "expected at most %v characters, got %d", limit, length)
+	}
+
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.fail(path, "invalid pattern %s: %v", pattern, err)
+		} else if !re.MatchString(value) {
+			v.fail(path, "expected to match %s, got %q", pattern, value)
+		}
+	}
+}
+
+func (v *validator) validateNumber(s map[string]any, value json.Number, path string) {
+	n, _ := number(value)
+	if limit, ok := number(s["minimum"]); ok && n < limit {
+		v.fail(path, "expected at least %v, got %s", limit, value)
+	}
+	if limit, ok := number(s["maximum"]); ok && n > limit {
+		v.fail(path, "expected at most %v, got %s", limit, value)
+	}
+	if limit, ok := number(s["exclusiveMinimum"]); ok && n <= limit {
+		v.fail(path, "expected more than %v, got %s", limit, value)
+	}
+	if limit, ok := number(s["exclusiveMaximum"]); ok && n >= limit {
+		v.fail(path, "expected less than %v, got %s", limit, value)
+	}
+	if factor, ok := number(s["multipleOf"]); ok && factor > 0 {
+		if quotient := n / factor; math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.fail(path, "expected a multiple of %v, got %s", factor, value)
+		}
+	}
+}
+
+func (v *validator) validateObject(s map[string]any, value map[string]any, path string, depth int) {
+	if required, ok := s["required"].([]any); ok {
+		for _, item := range required {
+			if name, ok := item.(string); ok {
+				if _, present := value[name]; !present {
+					v.fail(path, "missing required property %s", name)
+				}
+			}
+		}
+	}
+
+	if limit, ok := number(s["minProperties"]); ok && float64(len(value)) < limit {
+		v.fail(path, "expected at least %v properties, got %d", limit, len(value))
+	}
+	if limit, ok := number(s["maxProperties"]); ok && float64(len(value)) > limit {
+		v.fail(path, "expected at most %v properties, got %d", limit, len(value))
+	}
+
+	properties, _ := s["properties"].(map[string]any)
+	additional, hasAdditional := s["additionalProperties"]
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := path + "." + name
+		if property, defined := properties[name]; defined {
+			v.validate(property, value[name], propertyPath, depth+1)
+			continue
+		}
+		if !hasAdditional {
+			continue
+		}
+		if allowed, isBool := additional.(bool); isBool && !allowed {
+			v.fail(path, "unexpected property %s", name)
+			continue
+		}
+		v.validate(additional, value[name], propertyPath, depth+1)
+	}
+}
+
+func (v *validator) validateArray(s map[string]any, value []any, path string, depth int) {
+	if limit, ok := number(s["minItems"]); ok && float64(len(value)) < limit {
+		v.fail(path, "expected at least %v items, got %d", limit, len(value))
+	}
+	if limit, ok := number(s["maxItems"]); ok && float64(len(value)) > limit {
+		v.fail(path, "expected at most %v items, got %d", limit, len(value))
+	}
+
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if equalValues(value[i], value[j]) {
+					v.fail(path, "expected unique items, items %d and %d are equal", i, j)
+				}
+			}
+		}
+	}
+
+	// A list of schemas checks the items at the same positions.
+	if prefix, ok := s["prefixItems"].([]any); ok {
+		for i := 0; i < len(prefix) && i < len(value); i++ {
+			v.validate(prefix[i], value[i], fmt.Sprintf("%s[%d]", path, i), depth+1)
+		}
+	}
+	switch items := s["items"].(type) {
+	case []any:
+		for i := 0; i < len(items) && i < len(value); i++ {
+			v.validate(items[i], value[i], fmt.Sprintf("%s[%d]", path, i), depth+1)
+		}
+	case map[string]any, bool:
+		for i, item := range value {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, i), depth+1)
+		}
+	}
+}
+
+func (v *validator) validateCombinations(s map[string]any, value any, path string, depth int) {
+	if all, ok := s["allOf"].([]any); ok {
+		for _, sub := range all {
+			v.validate(sub, value, path, depth+1)
+		}
+	}
+
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if v.passes(sub, value, path, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "expected to match one of the anyOf schemas")
+		}
+	}
+
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			if v.passes(sub, value, path, depth+1) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail(path, "expected to match exactly one of the oneOf schemas, matched %d", matched)
+		}
+	}
+
+	if not, ok := s["not"]; ok && v.passes(not, value, path, depth+1) {
+		v.fail(path, "expected not to match the not schema")
+	}
+}
+
+// resolve follows a reference within the schema, such as #/$defs/user.
+func (v *validator) resolve(ref string) (any, error) {
+	pointer, found := strings.CutPrefix(ref, "#")
+	if !found {
+		return nil, fmt.Errorf("unsupported $ref %s, only references within the schema are", ref)
+	}
+
+	target := v.root
+	if pointer == "" {
+		return target, nil
+	}
+
+	for token := range strings.SplitSeq(strings.TrimPrefix(pointer, "/"), "/") {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		switch node := target.(type) {
+		case map[string]any:
+			next, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("$ref %s not found in the schema", ref)
+			}
+			target = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("$ref %s not found in the schema", ref)
+			}
+			target = node[i]
+		default:
+			return nil, fmt.Errorf("$ref %s not found in the schema", ref)
+		}
+	}
+	return target, nil
+}
+
+func typeOf(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if n, ok := number(value); ok && n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func number(value any) (float64, bool) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+// equalValues compares decoded values, numbers by their value so that 1
+// equals 1.0.
+func equalValues(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		x, okA := number(a)
+		y, okB := number(b)
+		return okA && okB && x == y
+	case []any:
+		list, ok := b.([]any)
+		if !ok || len(a) != len(list) {
+			return false
+		}
+		for i := range a {
+			if !equalValues(a[i], list[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		object, ok := b.(map[string]any)
+		if !ok || len(a) != len(object) {
+			return false
+		}
+		for key, value := range a {
+			other, present := object[key]
+			if !present || !equalValues(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func containsValue(list []any, value any) bool {
+	for _, item := range list {
+		if equalValues(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func compact(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
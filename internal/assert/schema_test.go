@@ -0,0 +1,141 @@
+package assert
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustSchema(t *testing.T, schema string) *Schema {
+	t.Helper()
+	s, err := ParseSchema([]byte(schema))
+	require.NoError(t, err)
+	return s
+}
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		body     string
+		expected []string
+	}{
+		{name: "true", schema: `true`, body: `{"a": 1}`},
+		{name: "false", schema: `false`, body: `1`, expected: []string{"$: no value is allowed"}},
+		{name: "type", schema: `{"type": "object"}`, body: `[]`, expected: []string{"$: expected object, got array"}},
+		{name: "types", schema: `{"type": ["string", "null"]}`, body: `null`},
+		{name: "integer", schema: `{"type": "integer"}`, body: `1.0`},
+		{name: "not integer", schema: `{"type": "integer"}`, body: `1.5`, expected: []string{"$: expected integer, got number"}},
+		{name: "number", schema: `{"type": "number"}`, body: `3`},
+		{name: "enum", schema: `{"enum": ["a", 1]}`, body: `1.0`},
+		{name: "not in enum", schema: `{"enum": ["a", "b"]}`, body: `"c"`, expected: []string{`$: expected one of ["a","b"], got "c"`}},
+		{name: "const", schema: `{"const": {"a": [1]}}`, body: `{"a": [2]}`, expected: []string{`$: expected {"a":[1]}, got {"a":[2]}`}},
+		{
+			name:   "string",
+			schema: `{"minLength": 2, "maxLength": 3, "pattern": "^[a-z]+$"}`,
+			body:   `"ABCD"`,
+			expected: []string{
+				"$: expected at most 3 characters, got 4",
+				`$: expected to match ^[a-z]+$, got "ABCD"`,
+			},
+		},
+		{name: "characters", schema: `{"maxLength": 2}`, body: `"éè"`},
+		{
+			name:     "number range",
+			schema:   `{"minimum": 1, "exclusiveMaximum": 10, "multipleOf": 0.5}`,
+			body:     `10`,
+			expected: []string{"$: expected less than 10, got 10"},
+		},
+		{name: "multiple", schema: `{"multipleOf": 0.5}`, body: `1.25`, expected: []string{"$: expected a multiple of 0.5, got 1.25"}},
+		{
+			name: "object",
+			schema: `{
+				"type": "object",
+				"required": ["id", "name"],
+				"properties": {"id": {"type": "integer"}, "data": {"properties": {"id": {"type": "integer"}}}},
+				"additionalProperties": false
+			}`,
+			body: `{"id": "42", "data": {"id": "x"}, "extra": true}`,
+			expected: []string{
+				"$: missing required property name",
+				"$.data.id: expected integer, got string",
+				"$: unexpected property extra",
+				"$.id: expected integer, got string",
+			},
+		},
+		{
+			name:     "additional properties schema",
+			schema:   `{"additionalProperties": {"type": "string"}}`,
+			body:     `{"a": "x", "b": 2}`,
+			expected: []string{"$.b: expected string, got integer"},
+		},
+		{
+			name:   "array",
+			schema: `{"minItems": 3, "uniqueItems": true, "items": {"type": "integer"}}`,
+			body:   `[1, 1.0]`,
+			expected: []string{
+				"$: expected at least 3 items, got 2",
+				"$: expected unique items, items 0 and 1 are equal",
+			},
+		},
+		{name: "items", schema: `{"items": {"type": "string"}}`, body: `["a", 2]`, expected: []string{"$[1]: expected string, got integer"}},
+		{name: "prefix items", schema: `{"prefixItems": [{"type": "string"}, {"type": "integer"}]}`, body: `["a", "b", 3]`, expected: []string{"$[1]: expected integer, got string"}},
+		{name: "all of", schema: `{"allOf": [{"minimum": 1}, {"maximum": 2}]}`, body: `3`, expected: []string{"$: expected at most 2, got 3"}},
+		{name: "any of", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, body: `2`},
+		{name: "none of any of", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, body: `true`, expected: []string{"$: expected to match one of the anyOf schemas"}},
+		{name: "one of", schema: `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, body: `2`, expected: []string{"$: expected to match exactly one of the oneOf schemas, matched 2"}},
+		{name: "not", schema: `{"not": {"type": "null"}}`, body: `null`, expected: []string{"$: expected not to match the not schema"}},
+		{
+			name:     "ref",
+			schema:   `{"$defs": {"id": {"type": "integer"}}, "properties": {"id": {"$ref": "#/$defs/id"}}}`,
+			body:     `{"id": "x"}`,
+			expected: []string{"$.id: expected integer, got string"},
+		},
+		{
+			name:     "recursive ref",
+			schema:   `{"properties": {"name": {"type": "string"}, "children": {"items": {"$ref": "#"}}}}`,
+			body:     `{"name": "a", "children": [{"name": "b", "children": [{"name": 3}]}]}`,
+			expected: []string{"$.children[0].children[0].name: expected string, got integer"},
+		},
+		{name: "missing ref", schema: `{"$ref": "#/$defs/id"}`, body: `1`, expected: []string{"$: $ref #/$defs/id not found in the schema"}},
+		{
+			name:     "remote ref",
+			schema:   `{"$ref": "https://example.com/schema.json"}`,
+			body:     `1`,
+			expected: []string{"$: unsupported $ref https://example.com/schema.json, only references within the schema are"},
+		},
+		{name: "endless ref", schema: `{"$ref": "#"}`, body: `1`, expected: []string{"$: the schema references itself too deeply"}},
+		{name: "not JSON", schema: `true`, body: `oops`, expected: []string{"the response is not JSON: invalid character 'o' looking for beginning of value"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, mustSchema(t, tt.schema).Validate([]byte(tt.body)))
+		})
+	}
+}
+
+func TestParseSchemaInvalid(t *testing.T) {
+	_, err := ParseSchema([]byte(`{"type": `))
+	require.ErrorContains(t, err, "the schema is not JSON")
+
+	_, err = ParseSchema([]byte(`"string"`))
+	require.EqualError(t, err, "a schema is an object or a boolean")
+}
+
+func TestSchemaLoad(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "user.json"), []byte(`{"required": ["id"]}`), 0644))
+
+	s := &Schema{Path: "user.json"}
+	require.NoError(t, s.Load(dir))
+	require.Equal(t, []string{"$: missing required property id"}, s.Validate([]byte(`{}`)))
+
+	loaded, err := LoadSchema(filepath.Join(dir, "user.json"))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "user.json"), loaded.Path)
+
+	require.Error(t, (&Schema{Path: "missing.json"}).Load(dir))
+}
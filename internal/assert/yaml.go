@@ -0,0 +1,92 @@
+package assert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Statuses are the expected statuses, written as a single status or a
+// list.
+type Statuses []int
+
+func (s *Statuses) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		status, err := strconv.Atoi(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid status %q", node.Line, node.Value)
+		}
+		*s = Statuses{status}
+		return nil
+	}
+
+	var statuses []int
+	if err := node.Decode(&statuses); err != nil {
+		return err
+	}
+	*s = statuses
+	return nil
+}
+
+func (s Statuses) String() string {
+	statuses := make([]string, len(s))
+	for i, status := range s {
+		statuses[i] = strconv.Itoa(status)
+	}
+	return strings.Join(statuses, " or ")
+}
+
+// Duration is a time.Duration written as a Go duration string, e.g. 500ms.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q, expected a value such as 500ms", node.Line, node.Value)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (j *JSONAssertion) UnmarshalYAML(node *yaml.Node) error {
+	var raw struct {
+		Path    string    `yaml:"path"`
+		Equals  yaml.Node `yaml:"equals"`
+		Matches string    `yaml:"matches"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+
+	*j = JSONAssertion{Path: raw.Path, Matches: raw.Matches}
+	if raw.Equals.Kind != 0 {
+		j.hasEquals = true
+		if err := raw.Equals.Decode(&j.Equals); err != nil {
+			return err
+		}
+	}
+
+	if j.Path == "" {
+		return fmt.Errorf("line %d: a json assertion needs a path", node.Line)
+	}
+	return nil
+}
+
+// UnmarshalYAML reads a schema written inline, or the path of its file
+// which Load reads later.
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		s.Path = node.Value
+		return nil
+	}
+
+	var root any
+	if err := node.Decode(&root); err != nil {
+		return err
+	}
+	s.root = normalize(root)
+	return nil
+}
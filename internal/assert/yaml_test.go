@@ -0,0 +1,76 @@
+package assert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestUnmarshalYAML(t *testing.T) {
+	var a Assertions
+	require.NoError(t, yaml.Unmarshal([]byte(`
+status: [200, 201]
+headers:
+  Content-Type: ^application/json
+json:
+  - path: .id
+    equals: 42
+  - path: .note
+    equals: null
+  - path: .tags
+    equals: [a, b]
+  - path: .name
+    matches: ^b
+maxLatency: 500ms
+schema:
+  type: object
+  required: [id]
+  properties:
+    id:
+      type: integer
+      minimum: 1
+`), &a))
+
+	require.Equal(t, Statuses{200, 201}, a.Status)
+	require.Equal(t, map[string]string{"Content-Type": "^application/json"}, a.Headers)
+	require.Equal(t, []JSONAssertion{
+		{Path: ".id", Equals: 42, hasEquals: true},
+		{Path: ".note", hasEquals: true},
+		{Path: ".tags", Equals: []any{"a", "b"}, hasEquals: true},
+		{Path: ".name", Matches: "^b"},
+	}, a.JSON)
+	require.Equal(t, Duration(500*time.Millisecond), a.MaxLatency)
+	require.Equal(t, []string{"$.id: expected at least 1, got 0"}, a.Schema.Validate([]byte(`{"id": 0}`)))
+}
+
+func TestUnmarshalYAMLSchemaPath(t *testing.T) {
+	var a Assertions
+	require.NoError(t, yaml.Unmarshal([]byte("status: 204\nschema: schemas/user.json\n"), &a))
+	require.Equal(t, Statuses{204}, a.Status)
+	require.Equal(t, &Schema{Path: "schemas/user.json"}, a.Schema)
+}
+
+func TestUnmarshalYAMLInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "status", content: "status: ok\n", err: `line 1: invalid status "ok"`},
+		{name: "latency", content: "maxLatency: 5\n", err: `line 1: invalid duration "5"`},
+		{name: "json path", content: "json:\n  - equals: 1\n", err: "line 2: a json assertion needs a path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a Assertions
+			require.ErrorContains(t, yaml.Unmarshal([]byte(tt.content), &a), tt.err)
+		})
+	}
+}
+
+func TestStatusesString(t *testing.T) {
+	require.Equal(t, "200 or 204", Statuses{200, 204}.String())
+}
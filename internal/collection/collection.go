@@ -0,0 +1,182 @@
+// Package collection finds the saved requests of the project and of the
+// aliases.
+package collection
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ashttp/internal/config"
+)
+
+// FileName is the file of the requests saved for a project, meant to be
+// committed with it. It is looked for in the working directory and its
+// parents.
+const FileName = "ashttp-requests.json"
+
+// Saved is a saved request and where it is stored: the project file, or
+// the config file when File is empty.
+type Saved struct {
+	Name    string
+	Request config.SavedRequest
+	File    string
+}
+
+// Load lists the requests of the project file found from dir and those
+// saved under aliases, sorted by name.
+func Load(dir string, settings config.SettingByURLAlias) ([]Saved, error) {
+	var saved []Saved
+
+	if file := FindFile(dir); file != "" {
+		requests, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for name, request := range requests {
+			saved = append(saved, Saved{Name: name, Request: request, File: file})
+		}
+	}
+
+	for alias, setting := range settings {
+		for name, request := range setting.Requests {
+			request.Alias = string(alias)
+			saved = append(saved, Saved{Name: name, Request: request})
+		}
+	}
+
+	// The project file comes first so its requests take precedence.
+	slices.SortStableFunc(saved, func(a, b Saved) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		if (a.File == "") != (b.File == "") {
+			if a.File != "" {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Request.Alias, b.Request.Alias)
+	})
+	return saved, nil
+}
+
+// Find returns the request saved as name. One of the project file is
+// preferred to one saved under an alias, and a name saved under several
+// aliases is ambiguous.
+func Find(saved []Saved, name string) (Saved, error) {
+	var found []Saved
+	for _, s := range saved {
+		if s.Name == name {
+			found = append(found, s)
+		}
+	}
+
+	switch {
+	case len(found) == 0:
+		return Saved{}, fmt.Errorf("no request saved as %s", name)
+	case found[0].File != "" || len(found) == 1:
+		return found[0], nil
+	}
+
+	aliases := make([]string, len(found))
+	for i, s := range found {
+		aliases[i] = s.Request.Alias
+	}
+	return Saved{}, fmt.Errorf("%s is saved under the aliases %s, delete all but one", name, strings.Join(aliases, ", "))
+}
+
+// FindFile returns the project file in dir or its closest parent holding
+// one, or an empty string.
+func FindFile(dir string) string {
+	for {
+		file := filepath.Join(dir, FileName)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// SaveLocal saves request in the project file found from dir, creating it
+// in dir when there is none, and returns the path of the file.
+func SaveLocal(dir, name string, request config.SavedRequest) (string, error) {
+	if request.Alias == "" {
+		return "", errors.New("a request saved in the project file needs an alias")
+	}
+
+	file := FindFile(dir)
+	if file == "" {
+		file = filepath.Join(dir, FileName)
+	}
+
+	requests, err := readFile(file)
+	if err != nil {
+		return "", err
+	}
+	requests[name] = request
+
+	return file, writeFile(file, requests)
+}
+
+// Delete removes a saved request from where it is stored.
+func Delete(s Saved) error {
+	if s.File == "" {
+		return config.DeleteRequest(config.URLAlias(s.Request.Alias), s.Name)
+	}
+
+	requests, err := readFile(s.File)
+	if err != nil {
+		return err
+	}
+	delete(requests, s.Name)
+
+	return writeFile(s.File, requests)
+}
+
+func readFile(file string) (map[string]config.SavedRequest, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]config.SavedRequest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	var requests map[string]config.SavedRequest
+	if err := json.Unmarshal(data, &requests); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	for name, request := range requests {
+		if request.Alias == "" || request.Method == "" {
+			return nil, fmt.Errorf("request %s of %s needs an alias and a method", name, file)
+		}
+	}
+	if requests == nil {
+		requests = map[string]config.SavedRequest{}
+	}
+	return requests, nil
+}
+
+func writeFile(file string, requests map[string]config.SavedRequest) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(requests); err != nil {
+		return err
+	}
+	return os.WriteFile(file, b.Bytes(), 0644)
+}
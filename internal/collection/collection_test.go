@@ -0,0 +1,109 @@
+package collection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindFile(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+
+	require.Empty(t, FindFile(sub))
+
+	file := filepath.Join(root, FileName)
+	require.NoError(t, os.WriteFile(file, []byte("{}"), 0644))
+	require.Equal(t, file, FindFile(sub))
+}
+
+func TestSaveLocalAndLoad(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+
+	file, err := SaveLocal(root, "users.list", config.SavedRequest{Alias: "api", Method: "get", Path: []string{"users"}})
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, FileName), file)
+
+	// The file of a parent directory is reused.
+	file, err = SaveLocal(sub, "users.create", config.SavedRequest{Alias: "api", Method: "post", Body: `{"a":"<b>"}`})
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, FileName), file)
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"body": "{\"a\":\"<b>\"}"`)
+
+	_, err = SaveLocal(root, "orphan", config.SavedRequest{Method: "get"})
+	require.EqualError(t, err, "a request saved in the project file needs an alias")
+
+	settings := config.SettingByURLAlias{
+		"api": {Requests: map[string]config.SavedRequest{"users.list": {Method: "get"}}},
+		"ops": {Requests: map[string]config.SavedRequest{"health": {Method: "get", Path: []string{"health"}}}},
+	}
+	saved, err := Load(sub, settings)
+	require.NoError(t, err)
+	require.Equal(t, []Saved{
+		{Name: "health", Request: config.SavedRequest{Alias: "ops", Method: "get", Path: []string{"health"}}},
+		{Name: "users.create", Request: config.SavedRequest{Alias: "api", Method: "post", Body: `{"a":"<b>"}`}, File: file},
+		{Name: "users.list", Request: config.SavedRequest{Alias: "api", Method: "get", Path: []string{"users"}}, File: file},
+		{Name: "users.list", Request: config.SavedRequest{Alias: "api", Method: "get"}},
+	}, saved)
+
+	require.NoError(t, Delete(saved[1]))
+	saved, err = Load(sub, nil)
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	require.Equal(t, "users.list", saved[0].Name)
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, FileName)
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"a": {"method": "get"}}`), 0644))
+	_, err := Load(root, nil)
+	require.ErrorContains(t, err, "request a of "+file+" needs an alias and a method")
+
+	require.NoError(t, os.WriteFile(file, []byte(`[`), 0644))
+	_, err = Load(root, nil)
+	require.ErrorContains(t, err, "failed to parse "+file)
+}
+
+func TestFind(t *testing.T) {
+	saved := []Saved{
+		{Name: "a", Request: config.SavedRequest{Alias: "x"}, File: "ashttp-requests.json"},
+		{Name: "a", Request: config.SavedRequest{Alias: "y"}},
+		{Name: "b", Request: config.SavedRequest{Alias: "x"}},
+		{Name: "c", Request: config.SavedRequest{Alias: "x"}},
+		{Name: "c", Request: config.SavedRequest{Alias: "y"}},
+	}
+
+	tests := []struct {
+		name     string
+		expected Saved
+		err      string
+	}{
+		{name: "a", expected: saved[0]},
+		{name: "b", expected: saved[2]},
+		{name: "c", err: "c is saved under the aliases x, y, delete all but one"},
+		{name: "d", err: "no request saved as d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := Find(saved, tt.name)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, found)
+		})
+	}
+}
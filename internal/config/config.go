@@ -1,8 +1,97 @@
 package config
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 type Setting struct {
-	URL     string
-	Headers map[string]string
+	URL      string
+	Headers  map[string]string
+	Digest   *DigestCredentials
+	TLS      *TLSSetting
+	Timeouts *TimeoutSetting
+	Retry    *RetrySetting
+	Proxy    *ProxySetting
+	Redirect *RedirectSetting
+	Requests map[string]SavedRequest
+}
+
+// SavedRequest is a named call run with ashttp run. Alias is left out of
+// the requests saved under an alias.
+type SavedRequest struct {
+	Alias   string            `json:"alias,omitempty"`
+	Method  string            `json:"method"`
+	Path    []string          `json:"path,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+type DigestCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type TLSSetting struct {
+	Cert               string `json:"cert,omitempty"`
+	Key                string `json:"key,omitempty"`
+	CA                 string `json:"ca,omitempty"`
+	ServerName         string `json:"serverName,omitempty"`
+	MinVersion         string `json:"minVersion,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+type TimeoutSetting struct {
+	Connect        Duration `json:"connect,omitempty"`
+	TLSHandshake   Duration `json:"tlsHandshake,omitempty"`
+	ResponseHeader Duration `json:"responseHeader,omitempty"`
+	Total          Duration `json:"total,omitempty"`
+}
+
+type RetrySetting struct {
+	MaxAttempts    int      `json:"maxAttempts,omitempty"`
+	InitialBackoff Duration `json:"initialBackoff,omitempty"`
+	MaxBackoff     Duration `json:"maxBackoff,omitempty"`
+	Statuses       []int    `json:"statuses,omitempty"`
+	AllMethods     bool     `json:"allMethods,omitempty"`
+}
+
+type ProxySetting struct {
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	NoProxy  string `json:"noProxy,omitempty"`
+}
+
+type RedirectSetting struct {
+	Follow      *bool `json:"follow,omitempty"`
+	Max         int   `json:"max,omitempty"`
+	KeepMethod  bool  `json:"keepMethod,omitempty"`
+	ForwardAuth bool  `json:"forwardAuth,omitempty"`
+}
+
+// Duration is a time.Duration written as a Go duration string, e.g. "1m30s".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5s\": %w", err)
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
 }
 
 type URLAlias string
@@ -18,6 +107,67 @@ func GetSettings() (SettingByURLAlias, error) {
 	return settingsFromExternalSettings(settings), nil
 }
 
+// AddURLAlias writes a new alias to the config file. Existing aliases are
+// never overwritten.
+func AddURLAlias(urlAlias URLAlias, alias ExternalSettingURLAlias) error {
+	settings, err := loadSettingFromFile(defaultFilePath)
+	if err != nil {
+		return err
+	}
+
+	if _, exists := settings[string(urlAlias)]; exists {
+		return fmt.Errorf("alias %s already exists in %s", urlAlias, defaultFilePath)
+	}
+
+	if settings == nil {
+		settings = ExternalSetting{}
+	}
+	settings[string(urlAlias)] = alias
+
+	return saveSettingToFile(defaultFilePath, settings)
+}
+
+// SaveRequest saves request under urlAlias, replacing a request of the
+// same name.
+func SaveRequest(urlAlias URLAlias, name string, request SavedRequest) error {
+	settings, err := loadSettingFromFile(defaultFilePath)
+	if err != nil {
+		return err
+	}
+
+	alias, exists := settings[string(urlAlias)]
+	if !exists {
+		return fmt.Errorf("no config found for %s in %s", urlAlias, defaultFilePath)
+	}
+
+	if alias.Requests == nil {
+		alias.Requests = map[string]SavedRequest{}
+	}
+	request.Alias = ""
+	alias.Requests[name] = request
+	settings[string(urlAlias)] = alias
+
+	return saveSettingToFile(defaultFilePath, settings)
+}
+
+// DeleteRequest removes the request saved as name under urlAlias.
+func DeleteRequest(urlAlias URLAlias, name string) error {
+	settings, err := loadSettingFromFile(defaultFilePath)
+	if err != nil {
+		return err
+	}
+
+	alias := settings[string(urlAlias)]
+	if _, exists := alias.Requests[name]; !exists {
+		return fmt.Errorf("no request %s saved under %s", name, urlAlias)
+	}
+
+	delete(alias.Requests, name)
+	settings[string(urlAlias)] = alias
+
+	return saveSettingToFile(defaultFilePath, settings)
+}
+
 func GetDefaultConfigPath() string {
 	return defaultFilePath
 }
@@ -27,8 +177,15 @@ func settingsFromExternalSettings(externalSettings ExternalSetting) SettingByURL
 	for k, v := range externalSettings {
 		urlAlias := URLAlias(k)
 		settings[urlAlias] = Setting{
-			URL:     v.URL,
-			Headers: v.DefaultHeaders,
+			URL:      v.URL,
+			Headers:  v.DefaultHeaders,
+			Digest:   v.Digest,
+			TLS:      v.TLS,
+			Timeouts: v.Timeouts,
+			Retry:    v.Retry,
+			Proxy:    v.Proxy,
+			Redirect: v.Redirect,
+			Requests: v.Requests,
 		}
 	}
 
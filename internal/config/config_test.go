@@ -1,9 +1,11 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -260,6 +262,54 @@ func TestSettingsFromExternalSettings(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "URL with digest credentials",
+			externalSettings: ExternalSetting{
+				"appliance": ExternalSettingURLAlias{
+					URL: "https://appliance.local",
+					Digest: &DigestCredentials{
+						Username: "admin",
+						Password: "secret",
+					},
+				},
+			},
+			expectedResult: SettingByURLAlias{
+				URLAlias("appliance"): Setting{
+					URL: "https://appliance.local",
+					Digest: &DigestCredentials{
+						Username: "admin",
+						Password: "secret",
+					},
+				},
+			},
+		},
+		{
+			name: "URL with tls setting",
+			externalSettings: ExternalSetting{
+				"internal": ExternalSettingURLAlias{
+					URL: "https://internal.example.com",
+					TLS: &TLSSetting{
+						Cert:       "/certs/client.crt",
+						Key:        "/certs/client.key",
+						CA:         "/certs/ca.pem",
+						ServerName: "internal.service",
+						MinVersion: "1.2",
+					},
+				},
+			},
+			expectedResult: SettingByURLAlias{
+				URLAlias("internal"): Setting{
+					URL: "https://internal.example.com",
+					TLS: &TLSSetting{
+						Cert:       "/certs/client.crt",
+						Key:        "/certs/client.key",
+						CA:         "/certs/ca.pem",
+						ServerName: "internal.service",
+						MinVersion: "1.2",
+					},
+				},
+			},
+		},
 		{
 			name: "URLs with special characters in alias",
 			externalSettings: ExternalSetting{
@@ -404,3 +454,155 @@ func TestGetSettingsIntegration(t *testing.T) {
 		}, devSetting.Headers)
 	})
 }
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		expected    Duration
+		expectError bool
+	}{
+		{
+			name:     "seconds",
+			json:     `"5s"`,
+			expected: Duration(5 * time.Second),
+		},
+		{
+			name:     "composite duration",
+			json:     `"1m30s"`,
+			expected: Duration(90 * time.Second),
+		},
+		{
+			name:        "number is rejected",
+			json:        `5`,
+			expectError: true,
+		},
+		{
+			name:        "invalid duration string",
+			json:        `"five seconds"`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Duration
+			err := json.Unmarshal([]byte(tt.json), &d)
+
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, d)
+
+			data, err := json.Marshal(d)
+			require.NoError(t, err)
+
+			var roundTrip Duration
+			require.NoError(t, json.Unmarshal(data, &roundTrip))
+			require.Equal(t, d, roundTrip)
+		})
+	}
+}
+
+func TestGetSettingsWithTimeouts(t *testing.T) {
+	tmpDir := t.TempDir()
+	mockPath := filepath.Join(tmpDir, "config.json")
+
+	configContent := `{
+		"slow": {
+			"url": "https://slow.example.com",
+			"timeouts": {
+				"connect": "2s",
+				"tlsHandshake": "3s",
+				"responseHeader": "10s",
+				"total": "1m"
+			}
+		}
+	}`
+	require.NoError(t, os.WriteFile(mockPath, []byte(configContent), 0644))
+
+	originalPath := defaultFilePath
+	defaultFilePath = mockPath
+	defer func() {
+		defaultFilePath = originalPath
+	}()
+
+	settings, err := GetSettings()
+	require.NoError(t, err)
+	require.Equal(t, &TimeoutSetting{
+		Connect:        Duration(2 * time.Second),
+		TLSHandshake:   Duration(3 * time.Second),
+		ResponseHeader: Duration(10 * time.Second),
+		Total:          Duration(time.Minute),
+	}, settings["slow"].Timeouts)
+}
+
+func TestAddURLAlias(t *testing.T) {
+	tmpDir := t.TempDir()
+	mockPath := filepath.Join(tmpDir, "config.json")
+
+	originalPath := defaultFilePath
+	defaultFilePath = mockPath
+	defer func() {
+		defaultFilePath = originalPath
+	}()
+
+	err := AddURLAlias("github", ExternalSettingURLAlias{
+		URL: "https://api.github.com",
+		DefaultHeaders: map[string]string{
+			"Accept": "application/vnd.github+json",
+		},
+	})
+	require.NoError(t, err)
+
+	settings, err := GetSettings()
+	require.NoError(t, err)
+	require.Contains(t, settings, URLAlias("httpbin"), "default alias should be kept")
+	require.Equal(t, Setting{
+		URL: "https://api.github.com",
+		Headers: map[string]string{
+			"Accept": "application/vnd.github+json",
+		},
+	}, settings["github"])
+
+	err = AddURLAlias("github", ExternalSettingURLAlias{URL: "https://other.example.com"})
+	require.ErrorContains(t, err, "alias github already exists")
+
+	settings, err = GetSettings()
+	require.NoError(t, err)
+	require.Equal(t, "https://api.github.com", settings["github"].URL)
+}
+
+func TestSaveAndDeleteRequest(t *testing.T) {
+	tmpDir := t.TempDir()
+	mockPath := filepath.Join(tmpDir, "config.json")
+
+	originalPath := defaultFilePath
+	defaultFilePath = mockPath
+	defer func() {
+		defaultFilePath = originalPath
+	}()
+
+	request := SavedRequest{Alias: "httpbin", Method: "post", Path: []string{"users"}, Body: `{"name":"ana"}`}
+	require.NoError(t, SaveRequest("httpbin", "users.create", request))
+
+	settings, err := GetSettings()
+	require.NoError(t, err)
+	require.Equal(t, map[string]SavedRequest{
+		"users.create": {Method: "post", Path: []string{"users"}, Body: `{"name":"ana"}`},
+	}, settings["httpbin"].Requests)
+
+	err = SaveRequest("missing", "users.create", request)
+	require.ErrorContains(t, err, "no config found for missing")
+
+	require.NoError(t, DeleteRequest("httpbin", "users.create"))
+	settings, err = GetSettings()
+	require.NoError(t, err)
+	require.Empty(t, settings["httpbin"].Requests)
+
+	err = DeleteRequest("httpbin", "users.create")
+	require.EqualError(t, err, "no request users.create saved under httpbin")
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,8 +10,16 @@ import (
 )
 
 type ExternalSettingURLAlias struct {
-	URL            string            `json:"url"`
-	DefaultHeaders map[string]string `json:"defaultHeaders"`
+	URL            string             `json:"url"`
+	DefaultHeaders map[string]string  `json:"defaultHeaders"`
+	Digest         *DigestCredentials `json:"digest,omitempty"`
+	TLS            *TLSSetting        `json:"tls,omitempty"`
+	Timeouts       *TimeoutSetting    `json:"timeouts,omitempty"`
+	Retry          *RetrySetting      `json:"retry,omitempty"`
+	Proxy          *ProxySetting      `json:"proxy,omitempty"`
+	Redirect       *RedirectSetting   `json:"redirect,omitempty"`
+
+	Requests map[string]SavedRequest `json:"requests,omitempty"`
 }
 
 type ExternalSetting map[string]ExternalSettingURLAlias
@@ -48,12 +57,21 @@ func loadSettingFromFile(filePath string) (ExternalSetting, error) {
 }
 
 func createDefaultSetting(filePath string) error {
-	data, err := json.MarshalIndent(defaultSetting, "", "  ")
-	if err != nil {
+	return saveSettingToFile(filePath, defaultSetting)
+}
+
+func saveSettingToFile(filePath string, setting ExternalSetting) error {
+	// URLs and bodies keep their & and < as written.
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(setting); err != nil {
 		return err
 	}
+	data := bytes.TrimSuffix(b.Bytes(), []byte("\n"))
 
-	err = os.MkdirAll(filepath.Dir(filePath), 0755)
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
 	if err != nil {
 		return err
 	}
@@ -171,6 +171,28 @@ func TestCreateDefaultSetting(t *testing.T) {
 	}
 }
 
+func TestSaveSettingToFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	setting := ExternalSetting{
+		"search": {
+			URL: "https://api.example.com/search?q=a&b=<c>",
+			Requests: map[string]SavedRequest{
+				"find": {Method: "post", Body: `{"q": "a & b"}`},
+			},
+		},
+	}
+	require.NoError(t, saveSettingToFile(configPath, setting))
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"url": "https://api.example.com/search?q=a&b=<c>"`)
+	require.Contains(t, string(data), `a & b`)
+
+	loaded, err := loadSettingFromFile(configPath)
+	require.NoError(t, err)
+	require.Equal(t, setting, loaded)
+}
+
 func TestLoadSettingIntegration(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -0,0 +1,257 @@
+// Package download streams response bodies to files.
+package download
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/ashttp/internal/config"
+	internalhttp "github.com/ashttp/internal/http"
+)
+
+// partSuffix names the file a download is written to until it completes.
+const partSuffix = ".part"
+
+// validatorSuffix names the file next to the .part file keeping the ETag or
+// Last-Modified of the response it comes from.
+const validatorSuffix = ".validator"
+
+// Options tells where a response is saved.
+type Options struct {
+	// Path is the file to write. When empty the name comes from the
+	// Content-Disposition header, or else the URL, in the current directory.
+	Path string
+	// Progress receives a progress bar when the length of the body is
+	// known, nil disables it.
+	Progress io.Writer
+}
+
+type Result struct {
+	Path string
+	// Size is the size of the saved file, Resumed how much of it was
+	// already on disk from a previous attempt.
+	Size    int64
+	Resumed int64
+	Stream  *internalhttp.Stream
+}
+
+// Run sends req and streams the response body to disk. The body goes to a
+// .part file first, renamed once complete, and a .part file left by a
+// failed attempt is resumed with a Range request. The ETag or Last-Modified
+// of the first response is sent as If-Range, so the download starts over
+// when the resource changed in between.
+func Run(req *http.Request, setting config.Setting, opts Options) (*Result, error) {
+	partPath := opts.Path
+	if partPath == "" {
+		partPath = nameFromURL(req.URL.Path)
+	}
+	partPath += partSuffix
+	validatorPath := partPath + validatorSuffix
+
+	var offset int64
+	var sentValidator string
+	if info, err := os.Stat(partPath); err == nil && info.Size() > 0 {
+		// Without a validator nothing tells the part file still matches the
+		// resource, so it is overwritten.
+		if data, err := os.ReadFile(validatorPath); err == nil && len(data) > 0 {
+			offset, sentValidator = info.Size(), string(data)
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			req.Header.Set("If-Range", sentValidator)
+		}
+	}
+
+	stream, err := internalhttp.Open(req, setting)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Body.Close()
+
+	result := &Result{Path: opts.Path, Stream: stream}
+	if result.Path == "" {
+		result.Path = nameFromResponse(stream.Header, req.URL.Path)
+		if _, err := os.Stat(result.Path); err == nil {
+			return nil, fmt.Errorf("%s already exists, choose another path with -output-file", result.Path)
+		}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	switch {
+	case offset > 0 && stream.StatusCode == http.StatusPartialContent:
+		if received := validator(stream.Header); received != "" && received != sentValidator {
+			// The server ignored If-Range and sent a part of another version.
+			stream.Body.Close()
+			return restart(req, setting, opts, partPath)
+		}
+		start, _, err := contentRange(stream.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			return nil, fmt.Errorf("cannot resume %s: unexpected Content-Range %q", partPath, stream.Header.Get("Content-Range"))
+		}
+		flags = os.O_WRONLY | os.O_APPEND
+		result.Resumed = offset
+	case offset > 0 && stream.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The previous attempt got everything but was not renamed.
+		if _, size, err := contentRange(stream.Header.Get("Content-Range")); err != nil || size != offset {
+			return nil, fmt.Errorf("cannot resume %s: the server rejected the range, remove it to start over", partPath)
+		}
+		result.Size, result.Resumed = offset, offset
+		return result, finish(partPath, result.Path)
+	case stream.StatusCode < 200 || stream.StatusCode > 299:
+		return nil, fmt.Errorf("the server answered %s, nothing was saved", stream.Status)
+	default:
+		if err := saveValidator(validatorPath, validator(stream.Header)); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(partPath, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	var destination io.Writer = file
+	var bar *progressBar
+	if opts.Progress != nil && stream.ContentLength >= 0 {
+		bar = &progressBar{out: opts.Progress, total: result.Resumed + stream.ContentLength, done: result.Resumed}
+		destination = io.MultiWriter(file, bar)
+	}
+
+	written, copyErr := io.Copy(destination, stream.Body)
+	if bar != nil {
+		bar.finish()
+	}
+	closeErr := file.Close()
+	if err := errors.Join(copyErr, closeErr); err != nil {
+		return nil, fmt.Errorf("download interrupted, run the same command again to resume from %s: %w", partPath, err)
+	}
+
+	result.Size = result.Resumed + written
+	return result, finish(partPath, result.Path)
+}
+
+// restart drops the part file and its validator and downloads the whole
+// resource again.
+func restart(req *http.Request, setting config.Setting, opts Options, partPath string) (*Result, error) {
+	if err := errors.Join(os.Remove(partPath), os.Remove(partPath+validatorSuffix)); err != nil {
+		return nil, err
+	}
+
+	req.Header.Del("Range")
+	req.Header.Del("If-Range")
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+
+	return Run(req, setting, opts)
+}
+
+// finish renames the complete part file and removes its validator.
+func finish(partPath, target string) error {
+	if err := os.Rename(partPath, target); err != nil {
+		return err
+	}
+	if err := os.Remove(partPath + validatorSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// validator returns the value to send as If-Range to get the rest of this
+// response: a strong ETag, or else Last-Modified. Weak ETags cannot be used
+// with If-Range.
+func validator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// saveValidator records the validator of a download starting from scratch,
+// or removes the one of a previous attempt when the response has none.
+func saveValidator(path, value string) error {
+	if value == "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path, []byte(value), 0o644)
+}
+
+// contentRange parses "bytes 100-199/200" and "bytes */200", returning the
+// first byte and the complete size, or -1 when the size is unknown.
+func contentRange(value string) (start, size int64, err error) {
+	spec, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+	}
+
+	byteRange, total, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+	}
+
+	size = -1
+	if total != "*" {
+		if size, err = strconv.ParseInt(total, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+		}
+	}
+
+	if byteRange == "*" {
+		return 0, size, nil
+	}
+
+	first, _, _ := strings.Cut(byteRange, "-")
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+	}
+	return start, size, nil
+}
+
+// nameFromResponse picks the filename suggested by Content-Disposition,
+// falling back to the last segment of the URL path.
+func nameFromResponse(header http.Header, urlPath string) string {
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		if name := sanitizeName(params["filename"]); name != "" {
+			return name
+		}
+	}
+	return nameFromURL(urlPath)
+}
+
+func nameFromURL(urlPath string) string {
+	if name := sanitizeName(path.Base(urlPath)); name != "" {
+		return name
+	}
+	return "download"
+}
+
+// sanitizeName keeps a server supplied name inside the current directory:
+// directories, leading dots and control characters are dropped.
+func sanitizeName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimLeft(name, ".")
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+
+	if name == "" || name == "/" || !fs.ValidPath(name) {
+		return ""
+	}
+	return name
+}
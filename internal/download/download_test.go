@@ -0,0 +1,294 @@
+package download
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+const content = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// serveContent answers with content, honouring Range requests, and records
+// the Range header it received.
+func serveContent(t *testing.T, header http.Header) (*httptest.Server, *string) {
+	var gotRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		for name, values := range header {
+			w.Header()[name] = values
+		}
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, &gotRange
+}
+
+func get(t *testing.T, url string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	return req
+}
+
+func TestRun(t *testing.T) {
+	t.Run("saves to the given path", func(t *testing.T) {
+		server, gotRange := serveContent(t, nil)
+		target := filepath.Join(t.TempDir(), "out.txt")
+
+		result, err := Run(get(t, server.URL+"/files/x"), config.Setting{}, Options{Path: target})
+		require.NoError(t, err)
+		require.Equal(t, target, result.Path)
+		require.Equal(t, int64(len(content)), result.Size)
+		require.Empty(t, *gotRange)
+
+		data, err := os.ReadFile(target)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+		require.NoFileExists(t, target+partSuffix)
+	})
+
+	t.Run("names the file after Content-Disposition", func(t *testing.T) {
+		server, _ := serveContent(t, http.Header{"Content-Disposition": {`attachment; filename="../../report 2024.csv"`}})
+		t.Chdir(t.TempDir())
+
+		result, err := Run(get(t, server.URL+"/export"), config.Setting{}, Options{})
+		require.NoError(t, err)
+		require.Equal(t, "report 2024.csv", result.Path)
+		require.FileExists(t, "report 2024.csv")
+	})
+
+	t.Run("names the file after the URL", func(t *testing.T) {
+		server, _ := serveContent(t, nil)
+		t.Chdir(t.TempDir())
+
+		result, err := Run(get(t, server.URL+"/files/data.bin?v=1"), config.Setting{}, Options{})
+		require.NoError(t, err)
+		require.Equal(t, "data.bin", result.Path)
+	})
+
+	t.Run("does not overwrite a derived name", func(t *testing.T) {
+		server, _ := serveContent(t, nil)
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("data.bin", []byte("mine"), 0o644))
+
+		_, err := Run(get(t, server.URL+"/data.bin"), config.Setting{}, Options{})
+		require.EqualError(t, err, "data.bin already exists, choose another path with -output-file")
+
+		data, err := os.ReadFile("data.bin")
+		require.NoError(t, err)
+		require.Equal(t, "mine", string(data))
+	})
+
+	t.Run("resumes a partial file", func(t *testing.T) {
+		server, gotRange := serveContent(t, http.Header{"Etag": {`"v1"`}})
+		target := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, os.WriteFile(target+partSuffix, []byte(content[:10]), 0o644))
+		require.NoError(t, os.WriteFile(target+partSuffix+validatorSuffix, []byte(`"v1"`), 0o644))
+
+		result, err := Run(get(t, server.URL), config.Setting{}, Options{Path: target})
+		require.NoError(t, err)
+		require.Equal(t, "bytes=10-", *gotRange)
+		require.Equal(t, int64(10), result.Resumed)
+		require.Equal(t, int64(len(content)), result.Size)
+		require.NoFileExists(t, target+partSuffix+validatorSuffix)
+
+		data, err := os.ReadFile(target)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+
+	t.Run("starts over when the resource changed", func(t *testing.T) {
+		server, _ := serveContent(t, http.Header{"Etag": {`"v2"`}})
+		target := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, os.WriteFile(target+partSuffix, []byte("old version"), 0o644))
+		require.NoError(t, os.WriteFile(target+partSuffix+validatorSuffix, []byte(`"v1"`), 0o644))
+
+		result, err := Run(get(t, server.URL), config.Setting{}, Options{Path: target})
+		require.NoError(t, err)
+		require.Zero(t, result.Resumed)
+
+		data, err := os.ReadFile(target)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+
+	t.Run("starts over when a partial answer is of another version", func(t *testing.T) {
+		var requests []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Header.Get("Range"))
+			w.Header().Set("ETag", `"v2"`)
+			if r.Header.Get("Range") != "" {
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes 5-%d/%d", len(content)-1, len(content)))
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write([]byte(content[5:]))
+				return
+			}
+			_, _ = w.Write([]byte(content))
+		}))
+		defer server.Close()
+		target := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, os.WriteFile(target+partSuffix, []byte("old v"), 0o644))
+		require.NoError(t, os.WriteFile(target+partSuffix+validatorSuffix, []byte(`"v1"`), 0o644))
+
+		result, err := Run(get(t, server.URL), config.Setting{}, Options{Path: target})
+		require.NoError(t, err)
+		require.Equal(t, []string{"bytes=5-", ""}, requests)
+		require.Zero(t, result.Resumed)
+
+		data, err := os.ReadFile(target)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+
+	t.Run("starts over when the part file has no validator", func(t *testing.T) {
+		server, gotRange := serveContent(t, nil)
+		target := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, os.WriteFile(target+partSuffix, []byte("stale"), 0o644))
+
+		result, err := Run(get(t, server.URL), config.Setting{}, Options{Path: target})
+		require.NoError(t, err)
+		require.Empty(t, *gotRange)
+		require.Zero(t, result.Resumed)
+
+		data, err := os.ReadFile(target)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+
+	t.Run("completes a partial file that has everything", func(t *testing.T) {
+		server, gotRange := serveContent(t, http.Header{"Etag": {`"v1"`}})
+		target := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, os.WriteFile(target+partSuffix, []byte(content), 0o644))
+		require.NoError(t, os.WriteFile(target+partSuffix+validatorSuffix, []byte(`"v1"`), 0o644))
+
+		result, err := Run(get(t, server.URL), config.Setting{}, Options{Path: target})
+		require.NoError(t, err)
+		require.Equal(t, "bytes=36-", *gotRange)
+		require.Equal(t, int64(len(content)), result.Resumed)
+		require.FileExists(t, target)
+	})
+
+	t.Run("starts over when the server ignores the range", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(content))
+		}))
+		defer server.Close()
+		target := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, os.WriteFile(target+partSuffix, []byte("stale"), 0o644))
+		require.NoError(t, os.WriteFile(target+partSuffix+validatorSuffix, []byte(`"v1"`), 0o644))
+
+		result, err := Run(get(t, server.URL), config.Setting{}, Options{Path: target})
+		require.NoError(t, err)
+		require.Zero(t, result.Resumed)
+
+		data, err := os.ReadFile(target)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+
+	t.Run("interrupted download keeps only the part file and its validator", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte(content[:10]))
+		}))
+		defer server.Close()
+		target := filepath.Join(t.TempDir(), "out.txt")
+
+		_, err := Run(get(t, server.URL), config.Setting{}, Options{Path: target})
+		require.ErrorContains(t, err, "download interrupted, run the same command again to resume from "+target+partSuffix)
+		require.NoFileExists(t, target)
+
+		data, err := os.ReadFile(target + partSuffix)
+		require.NoError(t, err)
+		require.Equal(t, content[:10], string(data))
+
+		validator, err := os.ReadFile(target + partSuffix + validatorSuffix)
+		require.NoError(t, err)
+		require.Equal(t, `"v1"`, string(validator))
+	})
+
+	t.Run("error responses are not saved", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		target := filepath.Join(t.TempDir(), "out.txt")
+
+		_, err := Run(get(t, server.URL), config.Setting{}, Options{Path: target})
+		require.EqualError(t, err, "the server answered 404 Not Found, nothing was saved")
+		require.NoFileExists(t, target)
+		require.NoFileExists(t, target+partSuffix)
+	})
+
+	t.Run("draws a progress bar", func(t *testing.T) {
+		server, _ := serveContent(t, nil)
+		var progress bytes.Buffer
+
+		_, err := Run(get(t, server.URL), config.Setting{}, Options{Path: filepath.Join(t.TempDir(), "out"), Progress: &progress})
+		require.NoError(t, err)
+		require.True(t, strings.HasSuffix(progress.String(), "\r[==============================] 100% 36 bytes / 36 bytes\n"), progress.String())
+	})
+}
+
+func TestContentRange(t *testing.T) {
+	tests := []struct {
+		value         string
+		expectedStart int64
+		expectedSize  int64
+		expectError   bool
+	}{
+		{value: "bytes 100-199/200", expectedStart: 100, expectedSize: 200},
+		{value: "bytes 0-9/*", expectedStart: 0, expectedSize: -1},
+		{value: "bytes */36", expectedStart: 0, expectedSize: 36},
+		{value: "items 0-9/10", expectError: true},
+		{value: "bytes 0-9", expectError: true},
+		{value: "bytes x-9/10", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, size, err := contentRange(tt.value)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedStart, start)
+			require.Equal(t, tt.expectedSize, size)
+		})
+	}
+}
+
+func TestNameFromResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		disposition string
+		urlPath     string
+		expected    string
+	}{
+		{name: "quoted filename", disposition: `attachment; filename="a.pdf"`, urlPath: "/x", expected: "a.pdf"},
+		{name: "encoded filename", disposition: `attachment; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`, urlPath: "/x", expected: "résumé.pdf"},
+		{name: "directories are dropped", disposition: `attachment; filename="..\\..\\evil.sh"`, urlPath: "/x", expected: "evil.sh"},
+		{name: "hidden files are not created", disposition: `attachment; filename=".bashrc"`, urlPath: "/x", expected: "bashrc"},
+		{name: "url when there is no disposition", urlPath: "/files/a.zip", expected: "a.zip"},
+		{name: "url when the filename is unusable", disposition: `attachment; filename=".."`, urlPath: "/files/a.zip", expected: "a.zip"},
+		{name: "fallback name", urlPath: "/", expected: "download"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.disposition != "" {
+				header.Set("Content-Disposition", tt.disposition)
+			}
+			require.Equal(t, tt.expected, nameFromResponse(header, tt.urlPath))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package download
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ashttp/internal/output"
+)
+
+const (
+	progressWidth    = 30
+	progressInterval = 100 * time.Millisecond
+)
+
+var now = time.Now
+
+// progressBar redraws a single line such as
+// [=========>          ] 33% 1.0 MiB / 3.0 MiB as bytes are written.
+type progressBar struct {
+	out   io.Writer
+	total int64
+	done  int64
+	drawn time.Time
+}
+
+func (p *progressBar) Write(data []byte) (int, error) {
+	p.done += int64(len(data))
+	if t := now(); t.Sub(p.drawn) >= progressInterval {
+		p.drawn = t
+		p.draw()
+	}
+	return len(data), nil
+}
+
+func (p *progressBar) draw() {
+	fraction := 1.0
+	if p.total > 0 {
+		fraction = min(1, float64(p.done)/float64(p.total))
+	}
+
+	filled := int(fraction * progressWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < progressWidth {
+		bar += ">" + strings.Repeat(" ", progressWidth-filled-1)
+	}
+
+	fmt.Fprintf(p.out, "\r[%s] %3d%% %s / %s", bar, int(fraction*100),
+		output.FormatSize(p.done), output.FormatSize(p.total))
+}
+
+// finish draws the final state and ends the line.
+func (p *progressBar) finish() {
+	p.draw()
+	fmt.Fprintln(p.out)
+}
@@ -0,0 +1,36 @@
+package download
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressBar(t *testing.T) {
+	clock := time.Unix(0, 0)
+	originalNow := now
+	now = func() time.Time { return clock }
+	defer func() {
+		now = originalNow
+	}()
+
+	var out bytes.Buffer
+	bar := &progressBar{out: &out, total: 2048}
+
+	_, err := bar.Write(make([]byte, 512))
+	require.NoError(t, err)
+	require.Equal(t, "\r[=======>                      ]  25% 512 bytes / 2.0 KiB", out.String())
+
+	// Writes closer than the interval are not drawn.
+	out.Reset()
+	_, err = bar.Write(make([]byte, 512))
+	require.NoError(t, err)
+	require.Empty(t, out.String())
+
+	clock = clock.Add(progressInterval)
+	bar.done = 2048
+	bar.finish()
+	require.Equal(t, "\r[==============================] 100% 2.0 KiB / 2.0 KiB\n", out.String())
+}
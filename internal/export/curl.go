@@ -0,0 +1,47 @@
+package export
+
+import (
+	"net/http"
+	"strings"
+
+	internalhttp "github.com/ashttp/internal/http"
+)
+
+// Curl renders req as an equivalent curl command line. Only the request
+// itself is rendered, the TLS, proxy and digest settings of the alias are
+// not.
+func Curl(req *http.Request, showSecrets bool) (string, error) {
+	body, err := internalhttp.RequestBody(req)
+	if err != nil {
+		return "", err
+	}
+
+	url := req.URL.String()
+	command := "curl"
+	// Without -g curl reads brackets and braces as URL globs, so a query
+	// such as filter[id]=1 would not be sent as is.
+	if strings.ContainsAny(url, "[]{}") {
+		command += " -g"
+	}
+	if impliedMethod := impliedCurlMethod(body); req.Method != impliedMethod {
+		command += " -X " + req.Method
+	}
+	parts := []string{command + " " + ShellQuote(url)}
+
+	for _, h := range sortedHeaders(req, showSecrets) {
+		parts = append(parts, "-H "+ShellQuote(h.name+": "+h.value))
+	}
+
+	if len(body) > 0 {
+		parts = append(parts, "--data-raw "+ShellQuote(string(body)))
+	}
+
+	return strings.Join(parts, " \\\n  "), nil
+}
+
+func impliedCurlMethod(body []byte) string {
+	if len(body) > 0 {
+		return http.MethodPost
+	}
+	return http.MethodGet
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ashttp/internal/config"
+	internalhttp "github.com/ashttp/internal/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurl(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     func(t *testing.T) *http.Request
+		showSecrets bool
+		expected    string
+	}{
+		{
+			name: "GET built from an alias with redacted secrets",
+			request: func(t *testing.T) *http.Request {
+				req, err := internalhttp.Request{
+					Path:      "users/456",
+					Method:    "get",
+					Arguments: map[string]string{"include": "posts,comments"},
+				}.ToHTTPRequest(config.Setting{
+					URL:     "https://httpbin.dev/anything",
+					Headers: map[string]string{"authorization": "Bearer abc"},
+				})
+				require.NoError(t, err)
+				return req
+			},
+			expected: "curl 'https://httpbin.dev/anything/users/456?include=posts,comments' \\\n" +
+				"  -H 'Authorization: Bearer [redacted]' \\\n" +
+				"  -H 'Content-Type: application/json'",
+		},
+		{
+			name: "secrets are kept when asked",
+			request: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(http.MethodDelete, "https://api.example.com/posts/1", nil)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", "Bearer abc")
+				return req
+			},
+			showSecrets: true,
+			expected: "curl -X DELETE https://api.example.com/posts/1 \\\n" +
+				"  -H 'Authorization: Bearer abc'",
+		},
+		{
+			name: "brackets and braces turn globbing off",
+			request: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(http.MethodGet, "https://api.example.com/users?filter[id]=1&fields={a,b}", nil)
+				require.NoError(t, err)
+				return req
+			},
+			expected: "curl -g 'https://api.example.com/users?filter[id]=1&fields={a,b}'",
+		},
+		{
+			name: "POST body is implied by the data",
+			request: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(http.MethodPost, "https://api.example.com/orders", strings.NewReader(`{"note": "it's done"}`))
+				require.NoError(t, err)
+				return req
+			},
+			expected: "curl https://api.example.com/orders \\\n" +
+				`  --data-raw '{"note": "it'\''s done"}'`,
+		},
+		{
+			name: "PUT with a body keeps the method",
+			request: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(http.MethodPut, "https://api.example.com/orders/1", strings.NewReader("x=1"))
+				require.NoError(t, err)
+				return req
+			},
+			expected: "curl -X PUT https://api.example.com/orders/1 \\\n" +
+				"  --data-raw x=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := Curl(tt.request(t), tt.showSecrets)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, command)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package export
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	internalhttp "github.com/ashttp/internal/http"
+)
+
+type header struct {
+	name  string
+	value string
+}
+
+// sortedHeaders flattens the request headers in a stable order, redacting
+// the sensitive ones unless showSecrets is set.
+func sortedHeaders(req *http.Request, showSecrets bool) []header {
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	headers := make([]header, 0, len(names))
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			if !showSecrets {
+				value = internalhttp.RedactHeader(name, value)
+			}
+			headers = append(headers, header{name: name, value: value})
+		}
+	}
+
+	return headers
+}
+
+// ShellQuote quotes s for POSIX shells. Words made only of safe characters
+// are left as they are.
+func ShellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool { return !isShellSafe(r) }) == -1 {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func isShellSafe(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-", r)
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "safe word", input: "https://api.example.com/users?id=1", expected: "'https://api.example.com/users?id=1'"},
+		{name: "plain word", input: "api.example.com/users", expected: "api.example.com/users"},
+		{name: "empty string", input: "", expected: "''"},
+		{name: "spaces", input: "Content-Type: application/json", expected: "'Content-Type: application/json'"},
+		{name: "single quote", input: "it's", expected: `'it'\''s'`},
+		{name: "shell expansions", input: "$HOME `id` $(id)", expected: "'$HOME `id` $(id)'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, ShellQuote(tt.input))
+		})
+	}
+}
+
+func TestShellQuote_RoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	inputs := []string{
+		"plain",
+		"with space",
+		`"double" and 'single' quotes`,
+		"$HOME `id` $(id) \\ backslash",
+		"new\nline",
+		"{\"json\": [1, 2]}",
+	}
+
+	quoted := make([]string, len(inputs))
+	for i, input := range inputs {
+		quoted[i] = ShellQuote(input)
+	}
+
+	out, err := exec.Command("sh", "-c", `printf '%s\0' `+strings.Join(quoted, " ")).Output()
+	require.NoError(t, err)
+	require.Equal(t, inputs, strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00"))
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	internalhttp "github.com/ashttp/internal/http"
+)
+
+// Fetch renders req as a JavaScript fetch() call.
+func Fetch(req *http.Request, showSecrets bool) (string, error) {
+	body, err := internalhttp.RequestBody(req)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "fetch(%s, {\n", jsString(req.URL.String()))
+	fmt.Fprintf(&b, "  method: %s,\n", jsString(req.Method))
+
+	headers := sortedHeaders(req, showSecrets)
+	b.WriteString("  headers: {")
+	for i, h := range headers {
+		separator := ","
+		if i == len(headers)-1 {
+			separator = ""
+		}
+		fmt.Fprintf(&b, "\n    %s: %s%s", jsString(h.name), jsString(h.value), separator)
+	}
+	if len(headers) > 0 {
+		b.WriteString("\n  ")
+	}
+	b.WriteString("}")
+
+	if len(body) > 0 {
+		fmt.Fprintf(&b, ",\n  body: %s", jsString(string(body)))
+	}
+	b.WriteString("\n});")
+
+	return b.String(), nil
+}
+
+// jsString quotes s as a JavaScript string literal. JSON strings are valid
+// JavaScript, line separators included since they are escaped.
+func jsString(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetch(t *testing.T) {
+	t.Run("POST with headers and body", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "https://api.example.com/orders?dry=true", strings.NewReader(`{"note": "<b>"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer abc")
+
+		code, err := Fetch(req, false)
+		require.NoError(t, err)
+		require.Equal(t, `fetch("https://api.example.com/orders?dry=true", {
+  method: "POST",
+  headers: {
+    "Authorization": "Bearer [redacted]",
+    "Content-Type": "application/json"
+  },
+  body: "{\"note\": \"<b>\"}"
+});`, code)
+	})
+
+	t.Run("GET without headers", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "https://api.example.com/users", nil)
+		require.NoError(t, err)
+
+		code, err := Fetch(req, false)
+		require.NoError(t, err)
+		require.Equal(t, `fetch("https://api.example.com/users", {
+  method: "GET",
+  headers: {}
+});`, code)
+	})
+}
+
+func TestJSString(t *testing.T) {
+	require.Equal(t, `"line\nbreak"`, jsString("line\nbreak"))
+	require.Equal(t, `"\u2028"`, jsString("\u2028"))
+	require.Equal(t, `"<script>"`, jsString("<script>"))
+}
@@ -0,0 +1,28 @@
+package export
+
+import (
+	"net/http"
+	"strings"
+
+	internalhttp "github.com/ashttp/internal/http"
+)
+
+// HTTPie renders req as an equivalent HTTPie command line.
+func HTTPie(req *http.Request, showSecrets bool) (string, error) {
+	body, err := internalhttp.RequestBody(req)
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{"http"}
+	if len(body) > 0 {
+		parts = append(parts, "--raw "+ShellQuote(string(body)))
+	}
+	parts = append(parts, req.Method, ShellQuote(req.URL.String()))
+
+	for _, h := range sortedHeaders(req, showSecrets) {
+		parts = append(parts, ShellQuote(h.name+":"+h.value))
+	}
+
+	return strings.Join(parts, " "), nil
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPie(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		body        string
+		headers     map[string]string
+		showSecrets bool
+		expected    string
+	}{
+		{
+			name:     "GET with headers",
+			method:   http.MethodGet,
+			url:      "https://api.example.com/users?page=2",
+			headers:  map[string]string{"X-Api-Key": "k-1", "Accept": "application/json"},
+			expected: "http GET 'https://api.example.com/users?page=2' Accept:application/json 'X-Api-Key:[redacted]'",
+		},
+		{
+			name:        "secrets are kept when asked",
+			method:      http.MethodGet,
+			url:         "https://api.example.com/users",
+			headers:     map[string]string{"X-Api-Key": "k-1"},
+			showSecrets: true,
+			expected:    "http GET https://api.example.com/users X-Api-Key:k-1",
+		},
+		{
+			name:     "POST with a raw body",
+			method:   http.MethodPost,
+			url:      "https://api.example.com/orders",
+			body:     `{"id": 1}`,
+			expected: `http --raw '{"id": 1}' POST https://api.example.com/orders`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			require.NoError(t, err)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			command, err := HTTPie(req, tt.showSecrets)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, command)
+		})
+	}
+}
@@ -0,0 +1,670 @@
+package filter
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ashttp/internal/jsonvalue"
+)
+
+// builtinArities lists the supported functions with the number of arguments
+// they accept.
+var builtinArities = map[string][]int{
+	"length": {0}, "keys": {0}, "values": {0}, "type": {0}, "not": {0}, "empty": {0},
+	"sort": {0}, "unique": {0}, "reverse": {0}, "add": {0}, "min": {0}, "max": {0}, "floor": {0},
+	"tostring": {0}, "tonumber": {0}, "tojson": {0}, "fromjson": {0},
+	"to_entries": {0}, "from_entries": {0}, "ascii_downcase": {0}, "ascii_upcase": {0},
+	"first": {0, 1}, "last": {0, 1}, "any": {0, 1, 2}, "all": {0, 1, 2},
+	"select": {1}, "map": {1}, "map_values": {1}, "with_entries": {1},
+	"sort_by": {1}, "unique_by": {1}, "group_by": {1}, "min_by": {1}, "max_by": {1},
+	"has": {1}, "contains": {1}, "join": {1}, "split": {1}, "test": {1},
+	"startswith": {1}, "endswith": {1}, "ltrimstr": {1}, "rtrimstr": {1},
+}
+
+// unsupportedFunctions are jq builtins missing from builtinArities, so a call
+// to one of them is not reported as a typo.
+var unsupportedFunctions = []string{
+	"del", "delpaths", "path", "paths", "leaf_paths", "getpath", "setpath", "pick", "to_array",
+	"limit", "until", "while", "repeat", "range", "recurse", "walk", "env", "input", "inputs",
+	"debug", "stderr", "error", "halt", "halt_error", "input_filename", "isempty", "nth",
+	"flatten", "indices", "index", "rindex", "inside", "in", "combinations", "transpose",
+	"tostream", "fromstream", "truncate_stream", "splits", "sub", "gsub", "match", "capture",
+	"scan", "explode", "implode", "ascii", "trim", "ltrim", "rtrim", "utf8bytelength", "abs",
+	"ceil", "round", "sqrt", "pow", "log", "exp", "fabs", "isnan", "isinfinite", "infinite", "nan",
+	"arrays", "objects", "iterables", "booleans", "numbers", "strings", "nulls", "scalars",
+	"now", "mktime", "gmtime", "localtime", "strftime", "strptime", "todate", "fromdate",
+	"todateiso8601", "fromdateiso8601", "dateadd", "datesub", "date", "builtins",
+	"IN", "INDEX", "significand", "toarray",
+}
+
+type callNode struct {
+	pos  int
+	name string
+	args []node
+}
+
+func (n callNode) eval(input any) ([]any, error) {
+	switch n.name {
+	case "empty":
+		return nil, nil
+	case "select":
+		return n.selectValues(input)
+	case "values":
+		if input == nil {
+			return nil, nil
+		}
+		return []any{input}, nil
+	case "map", "map_values", "with_entries", "sort_by", "unique_by", "group_by", "min_by", "max_by":
+		result, err := n.withFunction(input)
+		if err != nil {
+			return nil, err
+		}
+		return []any{result}, nil
+	case "first", "last":
+		if len(n.args) == 1 {
+			return n.withGenerator(input)
+		}
+	case "any", "all":
+		if len(n.args) > 0 {
+			return n.anyOrAll(input)
+		}
+	case "has", "contains", "join", "split", "test", "startswith", "endswith", "ltrimstr", "rtrimstr":
+		return n.withValueArgument(input)
+	}
+
+	result, err := n.simple(input)
+	if err != nil {
+		return nil, err
+	}
+	return []any{result}, nil
+}
+
+func (n callNode) selectValues(input any) ([]any, error) {
+	conds, err := n.args[0].eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []any
+	for _, cond := range conds {
+		if truthy(cond) {
+			results = append(results, input)
+		}
+	}
+	return results, nil
+}
+
+// simple runs the functions without arguments.
+func (n callNode) simple(input any) (any, error) {
+	switch n.name {
+	case "length":
+		switch v := input.(type) {
+		case nil:
+			return number(0), nil
+		case bool:
+			return nil, n.typeError(input)
+		case json.Number:
+			return number(math.Abs(toFloat(v))), nil
+		case string:
+			return number(float64(utf8.RuneCountInString(v))), nil
+		case []any:
+			return number(float64(len(v))), nil
+		case *jsonvalue.Object:
+			return number(float64(len(v.Keys))), nil
+		}
+	case "keys":
+		switch v := input.(type) {
+		case *jsonvalue.Object:
+			keys := slices.Sorted(slices.Values(v.Keys))
+			result := make([]any, len(keys))
+			for i, key := range keys {
+				result[i] = key
+			}
+			return result, nil
+		case []any:
+			result := make([]any, len(v))
+			for i := range v {
+				result[i] = number(float64(i))
+			}
+			return result, nil
+		}
+	case "type":
+		return typeOf(input), nil
+	case "not":
+		return !truthy(input), nil
+	case "floor":
+		if v, ok := input.(json.Number); ok {
+			return number(math.Floor(toFloat(v))), nil
+		}
+	case "tostring":
+		if v, ok := input.(string); ok {
+			return v, nil
+		}
+		data, err := jsonvalue.Marshal(input)
+		return string(data), err
+	case "tonumber":
+		switch v := input.(type) {
+		case json.Number:
+			return v, nil
+		case string:
+			value, err := jsonvalue.Decode([]byte(v))
+			if num, ok := value.(json.Number); ok && err == nil {
+				return num, nil
+			}
+			return nil, errorAt(n.pos, "cannot parse %q as a number", v)
+		}
+	case "tojson":
+		data, err := jsonvalue.Marshal(input)
+		return string(data), err
+	case "fromjson":
+		if v, ok := input.(string); ok {
+			value, err := jsonvalue.Decode([]byte(v))
+			if err != nil {
+				return nil, errorAt(n.pos, "cannot parse %q as JSON: %v", v, err)
+			}
+			return value, nil
+		}
+	case "ascii_downcase", "ascii_upcase":
+		if v, ok := input.(string); ok {
+			if n.name == "ascii_downcase" {
+				return strings.ToLower(v), nil
+			}
+			return strings.ToUpper(v), nil
+		}
+	case "to_entries":
+		if v, ok := input.(*jsonvalue.Object); ok {
+			entries := make([]any, len(v.Keys))
+			for i, key := range v.Keys {
+				entry := jsonvalue.NewObject()
+				entry.Set("key", key)
+				entry.Set("value", v.Values[key])
+				entries[i] = entry
+			}
+			return entries, nil
+		}
+	case "from_entries":
+		return n.fromEntries(input)
+	default:
+		return n.arrayFunction(input)
+	}
+
+	return nil, n.typeError(input)
+}
+
+// arrayFunction runs the functions without arguments working on arrays.
+func (n callNode) arrayFunction(input any) (any, error) {
+	items, ok := input.([]any)
+	if !ok {
+		return nil, n.typeError(input)
+	}
+
+	switch n.name {
+	case "first", "last":
+		if len(items) == 0 {
+			return nil, nil
+		}
+		if n.name == "first" {
+			return items[0], nil
+		}
+		return items[len(items)-1], nil
+	case "any", "all":
+		for _, item := range items {
+			if truthy(item) == (n.name == "any") {
+				return n.name == "any", nil
+			}
+		}
+		return n.name == "all", nil
+	case "sort":
+		return sortedBy(items, items), nil
+	case "unique":
+		return uniqueBy(items, items), nil
+	case "reverse":
+		reversed := slices.Clone(items)
+		slices.Reverse(reversed)
+		return reversed, nil
+	case "min", "max":
+		return extremeBy(items, items, n.name == "max"), nil
+	case "add":
+		var sum any
+		for _, item := range items {
+			var err error
+			if sum, err = add(n.pos, sum, item); err != nil {
+				return nil, err
+			}
+		}
+		return sum, nil
+	}
+
+	return nil, n.typeError(input)
+}
+
+func (n callNode) fromEntries(input any) (any, error) {
+	items, ok := input.([]any)
+	if !ok {
+		return nil, n.typeError(input)
+	}
+
+	object := jsonvalue.NewObject()
+	for _, item := range items {
+		entry, ok := item.(*jsonvalue.Object)
+		if !ok {
+			return nil, errorAt(n.pos, "from_entries expects objects with key and value, not %s", typeName(item))
+		}
+
+		var key any
+		for _, name := range []string{"key", "k", "name", "Name", "Key", "K"} {
+			if key, ok = entry.Get(name); ok && key != nil {
+				break
+			}
+		}
+		value, ok := entry.Get("value")
+		if !ok {
+			value, _ = entry.Get("v")
+		}
+
+		switch k := key.(type) {
+		case string:
+			object.Set(k, value)
+		case json.Number:
+			object.Set(k.String(), value)
+		case bool:
+			object.Set(strconv.FormatBool(k), value)
+		default:
+			return nil, errorAt(n.pos, "from_entries cannot use %s as a key", typeName(key))
+		}
+	}
+
+	return object, nil
+}
+
+// withFunction runs the functions taking a filter applied to each item.
+func (n callNode) withFunction(input any) (any, error) {
+	if n.name == "map_values" || n.name == "with_entries" {
+		if object, ok := input.(*jsonvalue.Object); ok {
+			return n.objectFunction(object)
+		}
+	}
+
+	items, ok := input.([]any)
+	if !ok {
+		return nil, n.typeError(input)
+	}
+
+	if n.name == "map" || n.name == "map_values" {
+		var results []any
+		for _, item := range items {
+			values, err := n.args[0].eval(item)
+			if err != nil {
+				return nil, err
+			}
+			if n.name == "map_values" && len(values) > 1 {
+				values = values[:1]
+			}
+			results = append(results, values...)
+		}
+		return orEmpty(results), nil
+	}
+
+	keys := make([]any, len(items))
+	for i, item := range items {
+		values, err := n.args[0].eval(item)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = orEmpty(values)
+	}
+
+	switch n.name {
+	case "sort_by":
+		return sortedBy(items, keys), nil
+	case "unique_by":
+		return uniqueBy(items, keys), nil
+	case "group_by":
+		return groupBy(items, keys), nil
+	case "min_by", "max_by":
+		return extremeBy(items, keys, n.name == "max_by"), nil
+	}
+
+	return nil, n.typeError(input)
+}
+
+func (n callNode) objectFunction(object *jsonvalue.Object) (any, error) {
+	if n.name == "map_values" {
+		result := jsonvalue.NewObject()
+		for _, key := range object.Keys {
+			values, err := n.args[0].eval(object.Values[key])
+			if err != nil {
+				return nil, err
+			}
+			if len(values) > 0 {
+				result.Set(key, values[0])
+			}
+		}
+		return result, nil
+	}
+
+	entries, err := callNode{pos: n.pos, name: "to_entries"}.simple(object)
+	if err != nil {
+		return nil, err
+	}
+	mapped, err := callNode{pos: n.pos, name: "map", args: n.args}.withFunction(entries)
+	if err != nil {
+		return nil, err
+	}
+	return n.fromEntries(mapped)
+}
+
+// withGenerator runs first(f) and last(f).
+func (n callNode) withGenerator(input any) ([]any, error) {
+	values, err := n.args[0].eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if n.name == "first" {
+		return values[:1], nil
+	}
+	return values[len(values)-1:], nil
+}
+
+// anyOrAll runs any(cond) and all(cond) on the items of an array, and
+// any(generator; cond) and all(generator; cond) on the generator results.
+func (n callNode) anyOrAll(input any) ([]any, error) {
+	cond := n.args[0]
+	var items []any
+	if len(n.args) == 2 {
+		var err error
+		if items, err = n.args[0].eval(input); err != nil {
+			return nil, err
+		}
+		cond = n.args[1]
+	} else {
+		var ok bool
+		if items, ok = input.([]any); !ok {
+			return nil, n.typeError(input)
+		}
+	}
+
+	wanted := n.name == "any"
+	for _, item := range items {
+		values, err := cond.eval(item)
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(values, func(v any) bool { return truthy(v) == wanted }) {
+			return []any{wanted}, nil
+		}
+	}
+
+	return []any{!wanted}, nil
+}
+
+// withValueArgument runs the functions whose argument is a value computed
+// from the input, e.g. has("id") or join(", ").
+func (n callNode) withValueArgument(input any) ([]any, error) {
+	args, err := n.args[0].eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]any, 0, len(args))
+	for _, arg := range args {
+		result, err := n.valueFunction(input, arg)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (n callNode) valueFunction(input, arg any) (any, error) {
+	switch n.name {
+	case "has":
+		switch v := input.(type) {
+		case *jsonvalue.Object:
+			if key, ok := arg.(string); ok {
+				_, found := v.Get(key)
+				return found, nil
+			}
+		case []any:
+			if i, ok := arg.(json.Number); ok {
+				return toFloat(i) >= 0 && int(toFloat(i)) < len(v), nil
+			}
+		}
+		return nil, errorAt(n.pos, "cannot check whether %s has %s", typeName(input), typeName(arg))
+	case "contains":
+		return contains(input, arg), nil
+	case "join":
+		return n.join(input, arg)
+	}
+
+	s, sok := input.(string)
+	a, aok := arg.(string)
+	if !sok || !aok {
+		return nil, errorAt(n.pos, "%s expects strings, not %s and %s", n.name, typeName(input), typeName(arg))
+	}
+
+	switch n.name {
+	case "split":
+		return splitString(s, a), nil
+	case "startswith":
+		return strings.HasPrefix(s, a), nil
+	case "endswith":
+		return strings.HasSuffix(s, a), nil
+	case "ltrimstr":
+		return strings.TrimPrefix(s, a), nil
+	case "rtrimstr":
+		return strings.TrimSuffix(s, a), nil
+	default:
+		re, err := regexp.Compile(a)
+		if err != nil {
+			return nil, errorAt(n.pos, "invalid regular expression %q: %v", a, err)
+		}
+		return re.MatchString(s), nil
+	}
+}
+
+func (n callNode) join(input, arg any) (any, error) {
+	items, ok := input.([]any)
+	separator, sok := arg.(string)
+	if !ok || !sok {
+		return nil, errorAt(n.pos, "join expects an array and a string separator, not %s and %s", typeName(input), typeName(arg))
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		switch v := item.(type) {
+		case nil:
+		case string:
+			parts[i] = v
+		case json.Number:
+			parts[i] = v.String()
+		case bool:
+			parts[i] = strconv.FormatBool(v)
+		default:
+			return nil, errorAt(n.pos, "cannot join %s", typeName(item))
+		}
+	}
+
+	return strings.Join(parts, separator), nil
+}
+
+func (n callNode) typeError(input any) error {
+	return errorAt(n.pos, "%s cannot be applied to %s", n.name, typeName(input))
+}
+
+func typeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// rank orders values of different types the way jq does.
+func rank(value any) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 2
+		}
+		return 1
+	case json.Number:
+		return 3
+	case string:
+		return 4
+	case []any:
+		return 5
+	default:
+		return 6
+	}
+}
+
+func compare(a, b any) int {
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+
+	switch x := a.(type) {
+	case json.Number:
+		fx, fy := toFloat(x), toFloat(b.(json.Number))
+		switch {
+		case fx < fy:
+			return -1
+		case fx > fy:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(x, b.(string))
+	case []any:
+		y := b.([]any)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	case *jsonvalue.Object:
+		y := b.(*jsonvalue.Object)
+		xKeys, yKeys := slices.Sorted(slices.Values(x.Keys)), slices.Sorted(slices.Values(y.Keys))
+		if c := slices.Compare(xKeys, yKeys); c != 0 {
+			return c
+		}
+		for _, key := range xKeys {
+			if c := compare(x.Values[key], y.Values[key]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return 0
+}
+
+func contains(a, b any) bool {
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && strings.Contains(x, y)
+	case []any:
+		y, ok := b.([]any)
+		if !ok {
+			return false
+		}
+		for _, wanted := range y {
+			if !slices.ContainsFunc(x, func(item any) bool { return contains(item, wanted) }) {
+				return false
+			}
+		}
+		return true
+	case *jsonvalue.Object:
+		y, ok := b.(*jsonvalue.Object)
+		if !ok {
+			return false
+		}
+		for _, key := range y.Keys {
+			value, found := x.Get(key)
+			if !found || !contains(value, y.Values[key]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return compare(a, b) == 0
+	}
+}
+
+// sortedBy sorts items by their keys, keeping the order of equal ones.
+func sortedBy(items, keys []any) []any {
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	slices.SortStableFunc(indexes, func(i, j int) int {
+		return compare(keys[i], keys[j])
+	})
+
+	sorted := make([]any, len(items))
+	for i, index := range indexes {
+		sorted[i] = items[index]
+	}
+	return sorted
+}
+
+func groupBy(items, keys []any) []any {
+	sortedItems, sortedKeys := sortedBy(items, keys), sortedBy(keys, keys)
+
+	groups := []any{}
+	for i, item := range sortedItems {
+		if i == 0 || compare(sortedKeys[i-1], sortedKeys[i]) != 0 {
+			groups = append(groups, []any{})
+		}
+		last := len(groups) - 1
+		groups[last] = append(groups[last].([]any), item)
+	}
+	return groups
+}
+
+func uniqueBy(items, keys []any) []any {
+	unique := []any{}
+	for _, group := range groupBy(items, keys) {
+		unique = append(unique, group.([]any)[0])
+	}
+	return unique
+}
+
+func extremeBy(items, keys []any, greatest bool) any {
+	if len(items) == 0 {
+		return nil
+	}
+
+	best := 0
+	for i := 1; i < len(items); i++ {
+		c := compare(keys[i], keys[best])
+		if (greatest && c >= 0) || (!greatest && c < 0) {
+			best = i
+		}
+	}
+	return items[best]
+}
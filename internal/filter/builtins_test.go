@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		expression string
+		input      string
+		expected   []string
+	}{
+		{expression: "length", input: `"añb"`, expected: []string{"3"}},
+		{expression: "length", input: `{"a": 1, "b": 2}`, expected: []string{"2"}},
+		{expression: "length", input: `-5`, expected: []string{"5"}},
+		{expression: "length", input: `null`, expected: []string{"0"}},
+		{expression: "keys", input: `{"b": 1, "a": 2}`, expected: []string{`["a","b"]`}},
+		{expression: "keys", input: `["x", "y"]`, expected: []string{"[0,1]"}},
+		{expression: "[.[] | values]", input: `[1, null, 2]`, expected: []string{"[1,2]"}},
+		{expression: "map(type)", input: `[null, true, 1, "s", [], {}]`, expected: []string{`["null","boolean","number","string","array","object"]`}},
+		{expression: "map(not)", input: `[null, false, 0, ""]`, expected: []string{"[true,true,false,false]"}},
+		{expression: "[empty, 1]", input: `null`, expected: []string{"[1]"}},
+		{expression: "sort", input: `[3, "a", null, [1], true, 1, {"a": 1}, false]`, expected: []string{`[null,false,true,1,3,"a",[1],{"a":1}]`}},
+		{expression: "unique", input: `[2, 1, 2, 1]`, expected: []string{"[1,2]"}},
+		{expression: "reverse", input: `[1, 2, 3]`, expected: []string{"[3,2,1]"}},
+		{expression: "add", input: `["a", "b", "c"]`, expected: []string{`"abc"`}},
+		{expression: "add", input: `[]`, expected: []string{"null"}},
+		{expression: "[min, max]", input: `[3, 1, 2]`, expected: []string{"[1,3]"}},
+		{expression: "floor", input: `2.7`, expected: []string{"2"}},
+		{expression: "[tostring, tojson]", input: `{"a": [1]}`, expected: []string{`["{\"a\":[1]}","{\"a\":[1]}"]`}},
+		{expression: "tostring", input: `"text"`, expected: []string{`"text"`}},
+		{expression: "tonumber", input: `"42.5"`, expected: []string{"42.5"}},
+		{expression: "fromjson | .a", input: `"{\"a\": 2}"`, expected: []string{"2"}},
+		{expression: "[ascii_downcase, ascii_upcase]", input: `"MiX"`, expected: []string{`["mix","MIX"]`}},
+		{expression: "to_entries", input: `{"a": 1}`, expected: []string{`[{"key":"a","value":1}]`}},
+		{expression: "from_entries", input: `[{"key": "a", "value": 1}, {"name": "b", "value": 2}, {"k": 3, "v": 3}]`, expected: []string{`{"a":1,"b":2,"3":3}`}},
+		{expression: "with_entries(select(.value > 1))", input: `{"a": 1, "b": 2}`, expected: []string{`{"b":2}`}},
+		{expression: "[first, last]", input: `[1, 2, 3]`, expected: []string{"[1,3]"}},
+		{expression: "[first(.[] | select(. > 1)), last(.[])]", input: `[1, 2, 3]`, expected: []string{"[2,3]"}},
+		{expression: "first(empty)", input: `null`, expected: nil},
+		{expression: "[any, all]", input: `[true, false]`, expected: []string{"[true,false]"}},
+		{expression: "[any(. > 2), all(. > 1)]", input: `[1, 2, 3]`, expected: []string{"[true,false]"}},
+		{expression: "[any(.[]; . == 2), all(.[]; . > 0)]", input: `[1, 2, 3]`, expected: []string{"[true,true]"}},
+		{expression: "map_values(. * 2)", input: `{"a": 1, "b": 2}`, expected: []string{`{"a":2,"b":4}`}},
+		{expression: "map_values(empty)", input: `{"a": 1}`, expected: []string{`{}`}},
+		{expression: "sort_by(.age) | map(.name)", input: `[{"name": "b", "age": 30}, {"name": "a", "age": 20}, {"name": "c", "age": 20}]`, expected: []string{`["a","c","b"]`}},
+		{expression: "group_by(.k) | map(length)", input: `[{"k": 2}, {"k": 1}, {"k": 2}]`, expected: []string{"[1,2]"}},
+		{expression: "unique_by(length)", input: `["a", "bb", "c"]`, expected: []string{`["a","bb"]`}},
+		{expression: "[min_by(.v), max_by(.v)] | map(.n)", input: `[{"n": "x", "v": 2}, {"n": "y", "v": 1}, {"n": "z", "v": 2}]`, expected: []string{`["y","z"]`}},
+		{expression: `[has("a"), has("z")]`, input: `{"a": null}`, expected: []string{"[true,false]"}},
+		{expression: "[has(0), has(5)]", input: `[1]`, expected: []string{"[true,false]"}},
+		{expression: `contains({"a": [1]})`, input: `{"a": [1, 2], "b": 3}`, expected: []string{"true"}},
+		{expression: `contains("ell")`, input: `"hello"`, expected: []string{"true"}},
+		{expression: `join(", ")`, input: `["a", 1, null, true]`, expected: []string{`"a, 1, , true"`}},
+		{expression: `split("-")`, input: `"a-b-c"`, expected: []string{`["a","b","c"]`}},
+		{expression: `[startswith("he"), endswith("lo"), test("^h.l+o$")]`, input: `"hello"`, expected: []string{"[true,true,true]"}},
+		{expression: `ltrimstr("v") | rtrimstr(".0")`, input: `"v1.0"`, expected: []string{`"1"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			results, err := run(t, tt.expression, tt.input)
+			require.NoError(t, err)
+			if tt.expected == nil {
+				require.Empty(t, results)
+				return
+			}
+			require.Equal(t, tt.expected, results)
+		})
+	}
+}
+
+func TestBuiltins_Errors(t *testing.T) {
+	tests := []struct {
+		expression string
+		input      string
+		expected   string
+	}{
+		{expression: "keys", input: `1`, expected: "keys cannot be applied to number (1)"},
+		{expression: "length", input: `true`, expected: "length cannot be applied to boolean"},
+		{expression: "tonumber", input: `"abc"`, expected: `cannot parse "abc" as a number`},
+		{expression: `join(",")`, input: `[[1]]`, expected: "cannot join array"},
+		{expression: `test("(")`, input: `"x"`, expected: `invalid regular expression "("`},
+		{expression: `startswith(1)`, input: `"x"`, expected: `startswith expects strings, not string ("x") and number (1)`},
+		{expression: `has("a")`, input: `[1]`, expected: `cannot check whether array has string ("a")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := run(t, tt.expression, tt.input)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
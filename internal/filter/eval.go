@@ -0,0 +1,636 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ashttp/internal/jsonvalue"
+)
+
+type node interface {
+	eval(input any) ([]any, error)
+}
+
+type identityNode struct{}
+
+func (identityNode) eval(input any) ([]any, error) {
+	return []any{input}, nil
+}
+
+type recurseNode struct{}
+
+func (recurseNode) eval(input any) ([]any, error) {
+	var results []any
+	var walk func(value any)
+	walk = func(value any) {
+		results = append(results, value)
+		switch v := value.(type) {
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		case *jsonvalue.Object:
+			for _, key := range v.Keys {
+				walk(v.Values[key])
+			}
+		}
+	}
+	walk(input)
+
+	return results, nil
+}
+
+type literalNode struct {
+	value any
+}
+
+func (n literalNode) eval(any) ([]any, error) {
+	return []any{n.value}, nil
+}
+
+type pipeNode struct {
+	left, right node
+}
+
+func (n pipeNode) eval(input any) ([]any, error) {
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []any
+	for _, left := range lefts {
+		rights, err := n.right.eval(left)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rights...)
+	}
+
+	return results, nil
+}
+
+type commaNode struct {
+	left, right node
+}
+
+func (n commaNode) eval(input any) ([]any, error) {
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(lefts, rights...), nil
+}
+
+// alternativeNode is a // b: the truthy results of a, or the results of b
+// when there are none.
+type alternativeNode struct {
+	left, right node
+}
+
+func (n alternativeNode) eval(input any) ([]any, error) {
+	lefts, err := n.left.eval(input)
+	var results []any
+	if err == nil {
+		for _, left := range lefts {
+			if truthy(left) {
+				results = append(results, left)
+			}
+		}
+	}
+
+	if len(results) > 0 {
+		return results, nil
+	}
+
+	return n.right.eval(input)
+}
+
+type logicalNode struct {
+	and         bool
+	left, right node
+}
+
+func (n logicalNode) eval(input any) ([]any, error) {
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []any
+	for _, left := range lefts {
+		// Short circuit like jq: false and x, true or x.
+		if truthy(left) != n.and {
+			results = append(results, !n.and)
+			continue
+		}
+
+		rights, err := n.right.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, right := range rights {
+			results = append(results, truthy(right))
+		}
+	}
+
+	return results, nil
+}
+
+type binaryNode struct {
+	op          string
+	pos         int
+	left, right node
+}
+
+func (n binaryNode) eval(input any) ([]any, error) {
+	rights, err := n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []any
+	for _, right := range rights {
+		for _, left := range lefts {
+			result, err := n.apply(left, right)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+func (n binaryNode) apply(left, right any) (any, error) {
+	switch n.op {
+	case "==":
+		return compare(left, right) == 0, nil
+	case "!=":
+		return compare(left, right) != 0, nil
+	case "<":
+		return compare(left, right) < 0, nil
+	case "<=":
+		return compare(left, right) <= 0, nil
+	case ">":
+		return compare(left, right) > 0, nil
+	case ">=":
+		return compare(left, right) >= 0, nil
+	case "+":
+		return add(n.pos, left, right)
+	}
+
+	if s, ok := left.(string); ok && n.op == "/" {
+		if sep, ok := right.(string); ok {
+			return splitString(s, sep), nil
+		}
+	}
+	if a, ok := left.([]any); ok && n.op == "-" {
+		if b, ok := right.([]any); ok {
+			var result []any
+			for _, item := range a {
+				if !containsValue(b, item) {
+					result = append(result, item)
+				}
+			}
+			return orEmpty(result), nil
+		}
+	}
+
+	a, aok := left.(json.Number)
+	b, bok := right.(json.Number)
+	if !aok || !bok {
+		return nil, errorAt(n.pos, "%s and %s cannot be used with %s", typeName(left), typeName(right), n.op)
+	}
+
+	x, y := toFloat(a), toFloat(b)
+	switch n.op {
+	case "-":
+		return number(x - y), nil
+	case "*":
+		return number(x * y), nil
+	case "/":
+		if y == 0 {
+			return nil, errorAt(n.pos, "%s cannot be divided by zero", a)
+		}
+		return number(x / y), nil
+	default:
+		if int64(y) == 0 {
+			return nil, errorAt(n.pos, "%s cannot be divided by zero", a)
+		}
+		return number(float64(int64(x) % int64(y))), nil
+	}
+}
+
+func add(pos int, left, right any) (any, error) {
+	if left == nil {
+		return right, nil
+	}
+	if right == nil {
+		return left, nil
+	}
+
+	switch a := left.(type) {
+	case json.Number:
+		if b, ok := right.(json.Number); ok {
+			return number(toFloat(a) + toFloat(b)), nil
+		}
+	case string:
+		if b, ok := right.(string); ok {
+			return a + b, nil
+		}
+	case []any:
+		if b, ok := right.([]any); ok {
+			return append(append([]any{}, a...), b...), nil
+		}
+	case *jsonvalue.Object:
+		if b, ok := right.(*jsonvalue.Object); ok {
+			merged := cloneObject(a)
+			for _, key := range b.Keys {
+				merged.Set(key, b.Values[key])
+			}
+			return merged, nil
+		}
+	}
+
+	return nil, errorAt(pos, "%s and %s cannot be added", typeName(left), typeName(right))
+}
+
+type negateNode struct {
+	pos     int
+	operand node
+}
+
+func (n negateNode) eval(input any) ([]any, error) {
+	values, err := n.operand.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]any, len(values))
+	for i, value := range values {
+		num, ok := value.(json.Number)
+		if !ok {
+			return nil, errorAt(n.pos, "%s cannot be negated", typeName(value))
+		}
+		results[i] = number(-toFloat(num))
+	}
+
+	return results, nil
+}
+
+type indexNode struct {
+	pos    int
+	source node
+	key    node
+}
+
+func (n indexNode) eval(input any) ([]any, error) {
+	sources, err := n.source.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := n.key.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []any
+	for _, source := range sources {
+		for _, key := range keys {
+			value, err := index(n.pos, source, key)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, value)
+		}
+	}
+
+	return results, nil
+}
+
+func index(pos int, source, key any) (any, error) {
+	if source == nil {
+		return nil, nil
+	}
+
+	switch s := source.(type) {
+	case *jsonvalue.Object:
+		if k, ok := key.(string); ok {
+			value, _ := s.Get(k)
+			return value, nil
+		}
+	case []any:
+		if k, ok := key.(json.Number); ok {
+			i := int(toFloat(k))
+			if i < 0 {
+				i += len(s)
+			}
+			if i < 0 || i >= len(s) {
+				return nil, nil
+			}
+			return s[i], nil
+		}
+	}
+
+	if k, ok := key.(string); ok {
+		return nil, errorAt(pos, "cannot index %s with %q", typeName(source), k)
+	}
+	return nil, errorAt(pos, "cannot index %s with %s", typeName(source), typeName(key))
+}
+
+type sliceNode struct {
+	pos      int
+	source   node
+	from, to node
+}
+
+func (n sliceNode) eval(input any) ([]any, error) {
+	sources, err := n.source.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []any
+	for _, source := range sources {
+		var length int
+		switch s := source.(type) {
+		case nil:
+			results = append(results, nil)
+			continue
+		case []any:
+			length = len(s)
+		case string:
+			length = utf8.RuneCountInString(s)
+		default:
+			return nil, errorAt(n.pos, "cannot slice %s", typeName(source))
+		}
+
+		from, err := n.bound(input, n.from, 0, length)
+		if err != nil {
+			return nil, err
+		}
+		to, err := n.bound(input, n.to, length, length)
+		if err != nil {
+			return nil, err
+		}
+		to = max(from, to)
+
+		switch s := source.(type) {
+		case []any:
+			results = append(results, append([]any{}, s[from:to]...))
+		case string:
+			results = append(results, string([]rune(s)[from:to]))
+		}
+	}
+
+	return results, nil
+}
+
+func (n sliceNode) bound(input any, bound node, fallback, length int) (int, error) {
+	if bound == nil {
+		return fallback, nil
+	}
+
+	values, err := bound.eval(input)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) != 1 {
+		return 0, errorAt(n.pos, "slice bounds must produce a single number")
+	}
+
+	num, ok := values[0].(json.Number)
+	if !ok {
+		return 0, errorAt(n.pos, "slice bounds must be numbers, not %s", typeName(values[0]))
+	}
+
+	i := int(math.Floor(toFloat(num)))
+	if i < 0 {
+		i += length
+	}
+	return min(max(i, 0), length), nil
+}
+
+type iterateNode struct {
+	pos    int
+	source node
+}
+
+func (n iterateNode) eval(input any) ([]any, error) {
+	sources, err := n.source.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []any
+	for _, source := range sources {
+		switch s := source.(type) {
+		case []any:
+			results = append(results, s...)
+		case *jsonvalue.Object:
+			for _, key := range s.Keys {
+				results = append(results, s.Values[key])
+			}
+		default:
+			return nil, errorAt(n.pos, "cannot iterate over %s", typeName(source))
+		}
+	}
+
+	return results, nil
+}
+
+// optionalNode is x?: errors raised by x are dropped with its results.
+type optionalNode struct {
+	source node
+}
+
+func (n optionalNode) eval(input any) ([]any, error) {
+	results, err := n.source.eval(input)
+	if err != nil {
+		return nil, nil
+	}
+	return results, nil
+}
+
+type arrayNode struct {
+	body node
+}
+
+func (n arrayNode) eval(input any) ([]any, error) {
+	if n.body == nil {
+		return []any{[]any{}}, nil
+	}
+
+	values, err := n.body.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{orEmpty(values)}, nil
+}
+
+type objectEntry struct {
+	key    node
+	keyPos int
+	value  node
+}
+
+type objectNode struct {
+	entries []objectEntry
+}
+
+// eval builds one object per combination of the entries results, like jq
+// does for {a: (1, 2)}.
+func (n objectNode) eval(input any) ([]any, error) {
+	objects := []*jsonvalue.Object{jsonvalue.NewObject()}
+	for _, entry := range n.entries {
+		keys, err := entry.key.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		values, err := entry.value.eval(input)
+		if err != nil {
+			return nil, err
+		}
+
+		var expanded []*jsonvalue.Object
+		for _, object := range objects {
+			for _, key := range keys {
+				k, ok := key.(string)
+				if !ok {
+					return nil, errorAt(entry.keyPos, "object keys must be strings, not %s", typeName(key))
+				}
+				for _, value := range values {
+					next := cloneObject(object)
+					next.Set(k, value)
+					expanded = append(expanded, next)
+				}
+			}
+		}
+		objects = expanded
+	}
+
+	results := make([]any, len(objects))
+	for i, object := range objects {
+		results[i] = object
+	}
+	return results, nil
+}
+
+type ifNode struct {
+	cond, then, otherwise node
+}
+
+func (n ifNode) eval(input any) ([]any, error) {
+	conds, err := n.cond.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []any
+	for _, cond := range conds {
+		branch := n.otherwise
+		if truthy(cond) {
+			branch = n.then
+		}
+
+		values, err := branch.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, values...)
+	}
+
+	return results, nil
+}
+
+func truthy(value any) bool {
+	return value != nil && value != false
+}
+
+func toFloat(n json.Number) float64 {
+	f, _ := strconv.ParseFloat(string(n), 64)
+	return f
+}
+
+// number formats f the way jq prints numbers: integers without a decimal
+// point and everything else in the shortest form.
+func number(f float64) json.Number {
+	if f == math.Trunc(f) && math.Abs(f) < 1e17 {
+		return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+func typeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number (" + v.String() + ")"
+	case string:
+		if len(v) > 20 {
+			v = v[:17] + "..."
+		}
+		return fmt.Sprintf("string (%q)", v)
+	case []any:
+		return "array"
+	case *jsonvalue.Object:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func cloneObject(o *jsonvalue.Object) *jsonvalue.Object {
+	clone := jsonvalue.NewObject()
+	for _, key := range o.Keys {
+		clone.Set(key, o.Values[key])
+	}
+	return clone
+}
+
+func orEmpty(values []any) []any {
+	if values == nil {
+		return []any{}
+	}
+	return values
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if compare(v, value) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func splitString(s, sep string) []any {
+	if s == "" {
+		return []any{}
+	}
+
+	var parts []any
+	for _, part := range strings.Split(s, sep) {
+		parts = append(parts, part)
+	}
+	return parts
+}
@@ -0,0 +1,91 @@
+// Package filter implements a subset of the jq language to query decoded
+// JSON responses: paths, iteration, slices, pipes, object and array
+// construction, comparisons, conditionals and the most used builtins. Other
+// jq syntax and builtins are rejected as unsupported rather than as typos.
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error reports a problem in the filter expression along with the position
+// it was found at.
+type Error struct {
+	Expression string
+	Position   int
+	Message    string
+}
+
+func newError(expression string, position int, format string, v ...any) *Error {
+	return &Error{Expression: expression, Position: position, Message: fmt.Sprintf(format, v...)}
+}
+
+// unsupported reports jq syntax that exists in jq but is not part of the
+// subset implemented here, so it is not mistaken for a typo.
+func unsupported(expression string, position int, syntax string) *Error {
+	return newError(expression, position, "unsupported jq syntax %q", syntax)
+}
+
+// Error prints the message followed by the expression and a caret under the
+// failing position.
+func (e *Error) Error() string {
+	column := utf8.RuneCountInString(e.Expression[:min(e.Position, len(e.Expression))])
+	return fmt.Sprintf("%s at position %d\n  %s\n  %s^", e.Message, column+1, e.Expression, strings.Repeat(" ", column))
+}
+
+type Filter struct {
+	expression string
+	root       node
+}
+
+// Parse compiles expression, so syntax errors are found before any request
+// is sent.
+func Parse(expression string) (*Filter, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{expression: expression, tokens: tokens}
+	root, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok)
+	}
+
+	return &Filter{expression: expression, root: root}, nil
+}
+
+// Apply runs the filter on a value decoded by jsonvalue.Decode. Like jq, a
+// filter may produce any number of results.
+func (f *Filter) Apply(value any) ([]any, error) {
+	results, err := f.root.eval(value)
+	if err != nil {
+		if evalErr, ok := err.(*evalError); ok {
+			return nil, newError(f.expression, evalErr.pos, "%s", evalErr.message)
+		}
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// evalError is an error found while running the filter, before the
+// expression is attached to it.
+type evalError struct {
+	pos     int
+	message string
+}
+
+func (e *evalError) Error() string {
+	return e.message
+}
+
+func errorAt(pos int, format string, v ...any) error {
+	return &evalError{pos: pos, message: fmt.Sprintf(format, v...)}
+}
@@ -0,0 +1,229 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/ashttp/internal/jsonvalue"
+	"github.com/stretchr/testify/require"
+)
+
+const document = `{
+  "data": [
+    {"id": 1, "name": "ana", "role": "admin", "tags": ["a", "b"], "owner": {"login": "x"}},
+    {"id": 2, "name": "bob", "role": "dev", "tags": [], "owner": null},
+    {"id": 3, "name": "carl", "role": "dev", "tags": ["c"]}
+  ],
+  "meta": {"total": 3, "next": null}
+}`
+
+const compactDocument = `{"data":[{"id":1,"name":"ana","role":"admin","tags":["a","b"],"owner":{"login":"x"}},{"id":2,"name":"bob","role":"dev","tags":[],"owner":null},{"id":3,"name":"carl","role":"dev","tags":["c"]}],"meta":{"total":3,"next":null}}`
+
+// run applies expression to document and returns each result as compact
+// JSON.
+func run(t *testing.T, expression, input string) ([]string, error) {
+	f, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := jsonvalue.Decode([]byte(input))
+	require.NoError(t, err)
+
+	results, err := f.Apply(value)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := make([]string, len(results))
+	for i, result := range results {
+		data, err := jsonvalue.Marshal(result)
+		require.NoError(t, err)
+		encoded[i] = string(data)
+	}
+	return encoded, nil
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   []string
+	}{
+		{expression: ".", expected: []string{compactDocument}},
+		{expression: ".meta.total", expected: []string{"3"}},
+		{expression: `.meta["total"]`, expected: []string{"3"}},
+		{expression: `."meta".next`, expected: []string{"null"}},
+		{expression: ".missing.deeper", expected: []string{"null"}},
+		{expression: ".data[0].name", expected: []string{`"ana"`}},
+		{expression: ".data[-1].id", expected: []string{"3"}},
+		{expression: ".data[10]", expected: []string{"null"}},
+		{expression: ".data[].id", expected: []string{"1", "2", "3"}},
+		{expression: "[.data[1:][].name]", expected: []string{`["bob","carl"]`}},
+		{expression: ".data[0].name[1:]", expected: []string{`"na"`}},
+		{expression: ".data[:-2] | length", expected: []string{"1"}},
+		{expression: ".meta[]", expected: []string{"3", "null"}},
+		{expression: ".data[] | select(.role == \"dev\") | .name", expected: []string{`"bob"`, `"carl"`}},
+		{expression: "[.data[] | {id, login: .owner.login}]", expected: []string{`[{"id":1,"login":"x"},{"id":2,"login":null},{"id":3,"login":null}]`}},
+		{expression: `{(.data[0].name): .meta.total, "k": 1}`, expected: []string{`{"ana":3,"k":1}`}},
+		{expression: "{id: (1, 2)}", expected: []string{`{"id":1}`, `{"id":2}`}},
+		{expression: ".meta.total, .meta.next", expected: []string{"3", "null"}},
+		{expression: ".meta.next // \"none\"", expected: []string{`"none"`}},
+		{expression: ".data | map(.id * 10 + 1)", expected: []string{"[11,21,31]"}},
+		{expression: ".data | map(.id) | add / length", expected: []string{"2"}},
+		{expression: "-.meta.total", expected: []string{"-3"}},
+		{expression: "1 + 2 * 3 - 4 % 3", expected: []string{"6"}},
+		{expression: "(1, 2) + (10, 20)", expected: []string{"11", "12", "21", "22"}},
+		{expression: ".data[0].id == 1 and .meta.next == null", expected: []string{"true"}},
+		{expression: "false or .meta.total > 2", expected: []string{"true"}},
+		{expression: ".data[] | if .id == 1 then \"one\" elif .id == 2 then \"two\" else \"many\" end", expected: []string{`"one"`, `"two"`, `"many"`}},
+		{expression: "if .meta.next then 1 end", expected: []string{compactDocument}},
+		{expression: `"a,b" / ","`, expected: []string{`["a","b"]`}},
+		{expression: "[1, 2, 3, 2] - [2]", expected: []string{"[1,3]"}},
+		{expression: `{"a": 1} + {"b": 2} + null`, expected: []string{`{"a":1,"b":2}`}},
+		{expression: "[..] | length", expected: []string{"26"}},
+		{expression: ".data[0].name.first?", expected: nil},
+		{expression: "[.meta[]?, 1[]?]", expected: []string{"[3,null]"}},
+		{expression: "[]", expected: []string{"[]"}},
+		{expression: "1.50", expected: []string{"1.50"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			results, err := run(t, tt.expression, document)
+			require.NoError(t, err)
+			if tt.expected == nil {
+				require.Empty(t, results)
+				return
+			}
+			require.Equal(t, tt.expected, results)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{
+			expression: ".data | map(.id))",
+			expected:   "unexpected \")\" at position 17\n  .data | map(.id))\n                  ^",
+		},
+		{
+			expression: ".data[0",
+			expected:   "expected \"]\" but the expression ended at position 8\n  .data[0\n         ^",
+		},
+		{
+			expression: ".a | lenght",
+			expected:   "unknown function lenght at position 6\n  .a | lenght\n       ^",
+		},
+		{
+			expression: "map",
+			expected:   "function map does not take 0 arguments at position 1\n  map\n  ^",
+		},
+		{
+			expression: `.name == "ana`,
+			expected:   "unterminated string at position 10\n  .name == \"ana\n           ^",
+		},
+		{
+			expression: ".a & .b",
+			expected:   "unexpected character '&' at position 4\n  .a & .b\n     ^",
+		},
+		{
+			expression: "if .a then 1",
+			expected:   "expected \"end\" but the expression ended at position 13\n  if .a then 1\n              ^",
+		},
+		{
+			expression: "{a: 1 b}",
+			expected:   "expected \"}\" but found \"b\" at position 7\n  {a: 1 b}\n        ^",
+		},
+		{
+			expression: "{1: 2}",
+			expected:   "expected an object key but found \"1\" at position 2\n  {1: 2}\n   ^",
+		},
+		{
+			expression: ".[:]",
+			expected:   "slice needs a start or an end at position 2\n  .[:]\n   ^",
+		},
+		{
+			expression: "",
+			expected:   "unexpected end of the expression at position 1\n  \n  ^",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := Parse(tt.expression)
+			require.EqualError(t, err, tt.expected)
+
+			var filterErr *Error
+			require.ErrorAs(t, err, &filterErr)
+		})
+	}
+}
+
+func TestParse_Unsupported(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{expression: ".data[] as $item | $item.id", expected: `unsupported jq syntax "as" at position 9`},
+		{expression: "$ENV.HOME", expected: `unsupported jq syntax "$ENV" at position 1`},
+		{expression: "reduce .[] as $x (0; . + $x)", expected: `unsupported jq syntax "reduce" at position 1`},
+		{expression: "foreach .[] as $x (0; . + 1)", expected: `unsupported jq syntax "foreach" at position 1`},
+		{expression: "try .a catch .", expected: `unsupported jq syntax "try" at position 1`},
+		{expression: "def f: .; f", expected: `unsupported jq syntax "def" at position 1`},
+		{expression: `"id: \(.id)"`, expected: `unsupported jq syntax "\\(" at position 6`},
+		{expression: ".data[] | [.id, .name] | @csv", expected: `unsupported jq syntax "@csv" at position 26`},
+		{expression: ".a |= 1", expected: `unsupported jq syntax "|=" at position 4`},
+		{expression: ".a += 1", expected: `unsupported jq syntax "+=" at position 4`},
+		{expression: ".a = 1", expected: `unsupported jq syntax "=" at position 4`},
+		{expression: "[.a[] |= 1]", expected: `unsupported jq syntax "|=" at position 7`},
+		{expression: "{$id}", expected: `unsupported jq syntax "$id" at position 2`},
+		{expression: "if .a then 1 else try .b end", expected: `unsupported jq syntax "try" at position 19`},
+		{expression: "del(.a)", expected: `unsupported jq function "del" at position 1`},
+		{expression: "[paths]", expected: `unsupported jq function "paths" at position 2`},
+		{expression: "[limit(2; .[])]", expected: `unsupported jq function "limit" at position 2`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := Parse(tt.expression)
+			require.ErrorContains(t, err, tt.expected+"\n")
+		})
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{
+			expression: ".data.name",
+			expected:   "cannot index array with \"name\" at position 6\n  .data.name\n       ^",
+		},
+		{
+			expression: ".meta.total[]",
+			expected:   "cannot iterate over number (3) at position 12\n  .meta.total[]\n             ^",
+		},
+		{
+			expression: ".data[0].name + 1",
+			expected:   "string (\"ana\") and number (1) cannot be added at position 15\n  .data[0].name + 1\n                ^",
+		},
+		{
+			expression: ".meta | map(.)",
+			expected:   "map cannot be applied to object at position 9\n  .meta | map(.)\n          ^",
+		},
+		{
+			expression: "1 / 0",
+			expected:   "1 cannot be divided by zero at position 3\n  1 / 0\n    ^",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := run(t, tt.expression, document)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package filter
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenDot
+	tokenRecurse
+	tokenField
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+	tokenUnsupported
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value string
+	pos   int
+}
+
+// assignments are the jq update operators. They are not supported but are
+// matched before punctuation so "|=" is not read as a pipe.
+var assignments = []string{"//=", "|=", "+=", "-=", "*=", "/=", "%="}
+
+// punctuation is sorted so longer operators are matched first.
+var punctuation = []string{
+	"==", "!=", "<=", ">=", "//",
+	"|", ",", ":", ";", "(", ")", "[", "]", "{", "}", "<", ">", "+", "-", "*", "/", "%", "?",
+}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '.':
+			switch {
+			case i+1 < len(expression) && expression[i+1] == '.':
+				tokens = append(tokens, token{kind: tokenRecurse, text: "..", pos: i})
+				i += 2
+			case i+1 < len(expression) && isIdentStart(expression[i+1]):
+				end := identEnd(expression, i+1)
+				tokens = append(tokens, token{kind: tokenField, text: expression[i:end], value: expression[i+1 : end], pos: i})
+				i = end
+			default:
+				tokens = append(tokens, token{kind: tokenDot, text: ".", pos: i})
+				i++
+			}
+		case isIdentStart(c):
+			end := identEnd(expression, i)
+			tokens = append(tokens, token{kind: tokenIdent, text: expression[i:end], value: expression[i:end], pos: i})
+			i = end
+		case c >= '0' && c <= '9':
+			end := numberEnd(expression, i)
+			tokens = append(tokens, token{kind: tokenNumber, text: expression[i:end], value: expression[i:end], pos: i})
+			i = end
+		case (c == '$' || c == '@') && i+1 < len(expression) && isIdentStart(expression[i+1]):
+			end := identEnd(expression, i+1)
+			tokens = append(tokens, token{kind: tokenUnsupported, text: expression[i:end], pos: i})
+			i = end
+		case c == '"':
+			tok, err := stringToken(expression, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i += len(tok.text)
+		default:
+			if tok, ok := assignmentToken(expression, i); ok {
+				tokens = append(tokens, tok)
+				i += len(tok.text)
+				continue
+			}
+
+			matched := false
+			for _, p := range punctuation {
+				if strings.HasPrefix(expression[i:], p) {
+					tokens = append(tokens, token{kind: tokenPunct, text: p, pos: i})
+					i += len(p)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, newError(expression, i, "unexpected character %q", rune(c))
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(expression)}), nil
+}
+
+func assignmentToken(expression string, i int) (token, bool) {
+	for _, a := range assignments {
+		if strings.HasPrefix(expression[i:], a) {
+			return token{kind: tokenUnsupported, text: a, pos: i}, true
+		}
+	}
+	if expression[i] == '=' && !strings.HasPrefix(expression[i:], "==") {
+		return token{kind: tokenUnsupported, text: "=", pos: i}, true
+	}
+	return token{}, false
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func identEnd(expression string, start int) int {
+	end := start
+	for end < len(expression) && (isIdentStart(expression[end]) || (expression[end] >= '0' && expression[end] <= '9')) {
+		end++
+	}
+	return end
+}
+
+func numberEnd(expression string, start int) int {
+	end := start
+	for end < len(expression) {
+		c := expression[end]
+		switch {
+		case c >= '0' && c <= '9', c == '.':
+		case (c == 'e' || c == 'E') && end+1 < len(expression):
+			if next := expression[end+1]; next == '+' || next == '-' {
+				end++
+			}
+		default:
+			return end
+		}
+		end++
+	}
+	return end
+}
+
+func stringToken(expression string, start int) (token, error) {
+	for end := start + 1; end < len(expression); end++ {
+		switch expression[end] {
+		case '\\':
+			if end+1 < len(expression) && expression[end+1] == '(' {
+				return token{}, unsupported(expression, end, `\(`)
+			}
+			end++
+		case '"':
+			text := expression[start : end+1]
+			var value string
+			if err := json.Unmarshal([]byte(text), &value); err != nil {
+				return token{}, newError(expression, start, "invalid string literal")
+			}
+			return token{kind: tokenString, text: text, value: value, pos: start}, nil
+		}
+	}
+
+	return token{}, newError(expression, start, "unterminated string")
+}
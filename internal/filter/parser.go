@@ -0,0 +1,534 @@
+package filter
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+)
+
+var keywords = []string{"and", "or", "if", "then", "elif", "else", "end"}
+
+// unsupportedKeywords are jq keywords this subset does not implement. They
+// get their own error so they are not reported as unknown functions.
+var unsupportedKeywords = []string{"as", "reduce", "foreach", "try", "catch", "def", "label", "import", "include"}
+
+type parser struct {
+	expression string
+	tokens     []token
+	current    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.current]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.current]
+	if tok.kind != tokenEOF {
+		p.current++
+	}
+	return tok
+}
+
+func (p *parser) isPunct(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokenPunct && tok.text == text
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && tok.value == keyword
+}
+
+func (p *parser) expectPunct(text string) error {
+	if !p.isPunct(text) {
+		tok := p.peek()
+		if isUnsupported(tok) {
+			return p.unexpected(tok)
+		}
+		if tok.kind == tokenEOF {
+			return newError(p.expression, tok.pos, "expected %q but the expression ended", text)
+		}
+		return newError(p.expression, tok.pos, "expected %q but found %q", text, tok.text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.isKeyword(keyword) {
+		tok := p.peek()
+		if isUnsupported(tok) {
+			return p.unexpected(tok)
+		}
+		if tok.kind == tokenEOF {
+			return newError(p.expression, tok.pos, "expected %q but the expression ended", keyword)
+		}
+		return newError(p.expression, tok.pos, "expected %q but found %q", keyword, tok.text)
+	}
+	p.next()
+	return nil
+}
+
+// isUnsupported reports whether tok is jq syntax missing from the subset, so
+// it is reported as such instead of as a misplaced token.
+func isUnsupported(tok token) bool {
+	return tok.kind == tokenUnsupported || (tok.kind == tokenIdent && slices.Contains(unsupportedKeywords, tok.value))
+}
+
+func (p *parser) unexpected(tok token) error {
+	if tok.kind == tokenEOF {
+		return newError(p.expression, tok.pos, "unexpected end of the expression")
+	}
+	if isUnsupported(tok) {
+		return unsupported(p.expression, tok.pos, tok.text)
+	}
+	return newError(p.expression, tok.pos, "unexpected %q", tok.text)
+}
+
+// parsePipe parses the lowest precedence level: a | b.
+func (p *parser) parsePipe() (node, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isPunct("|") {
+		p.next()
+		right, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return pipeNode{left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseComma() (node, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isPunct(",") {
+		p.next()
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		left = commaNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAlternative() (node, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isPunct("//") {
+		p.next()
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		return alternativeNode{left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{and: false, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{and: true, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.isPunct(op) {
+			tok := p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return binaryNode{op: op, pos: tok.pos, left: left, right: right}, nil
+		}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isPunct("+") || p.isPunct("-") {
+		tok := p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: tok.text, pos: tok.pos, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isPunct("*") || p.isPunct("/") || p.isPunct("%") {
+		tok := p.next()
+		right, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: tok.text, pos: tok.pos, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parsePostfix parses a term followed by any number of .field, [..] and ?
+// suffixes.
+func (p *parser) parsePostfix() (node, error) {
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenField:
+			p.next()
+			term = indexNode{pos: tok.pos, source: term, key: literalNode{value: tok.value}}
+		case tok.kind == tokenDot && p.tokens[p.current+1].kind == tokenString:
+			p.next()
+			key := p.next()
+			term = indexNode{pos: tok.pos, source: term, key: literalNode{value: key.value}}
+		case p.isPunct("["):
+			term, err = p.parseBracket(term)
+			if err != nil {
+				return nil, err
+			}
+		case p.isPunct("?"):
+			p.next()
+			term = optionalNode{source: term}
+		default:
+			return term, nil
+		}
+	}
+}
+
+// parseBracket parses [], [index] and [from:to] applied to source.
+func (p *parser) parseBracket(source node) (node, error) {
+	open := p.next()
+	if p.isPunct("]") {
+		p.next()
+		return iterateNode{pos: open.pos, source: source}, nil
+	}
+
+	var from node
+	if !p.isPunct(":") {
+		var err error
+		from, err = p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.isPunct(":") {
+		if err := p.expectPunct("]"); err != nil {
+			return nil, err
+		}
+		return indexNode{pos: open.pos, source: source, key: from}, nil
+	}
+
+	p.next()
+	var to node
+	if !p.isPunct("]") {
+		var err error
+		to, err = p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if from == nil && to == nil {
+		return nil, newError(p.expression, open.pos, "slice needs a start or an end")
+	}
+	if err := p.expectPunct("]"); err != nil {
+		return nil, err
+	}
+
+	return sliceNode{pos: open.pos, source: source, from: from, to: to}, nil
+}
+
+func (p *parser) parseTerm() (node, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenDot:
+		p.next()
+		if p.peek().kind == tokenString {
+			key := p.next()
+			return indexNode{pos: tok.pos, source: identityNode{}, key: literalNode{value: key.value}}, nil
+		}
+		return identityNode{}, nil
+	case tokenRecurse:
+		p.next()
+		return recurseNode{}, nil
+	case tokenField:
+		p.next()
+		return indexNode{pos: tok.pos, source: identityNode{}, key: literalNode{value: tok.value}}, nil
+	case tokenString:
+		p.next()
+		return literalNode{value: tok.value}, nil
+	case tokenNumber:
+		p.next()
+		if _, err := strconv.ParseFloat(tok.value, 64); err != nil {
+			return nil, newError(p.expression, tok.pos, "invalid number %q", tok.value)
+		}
+		return literalNode{value: json.Number(tok.value)}, nil
+	case tokenIdent:
+		return p.parseIdent()
+	case tokenPunct:
+		return p.parsePunctTerm()
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
+
+func (p *parser) parsePunctTerm() (node, error) {
+	tok := p.peek()
+	switch tok.text {
+	case "(":
+		p.next()
+		inner, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case "[":
+		p.next()
+		if p.isPunct("]") {
+			p.next()
+			return arrayNode{}, nil
+		}
+		inner, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct("]"); err != nil {
+			return nil, err
+		}
+		return arrayNode{body: inner}, nil
+	case "{":
+		return p.parseObject()
+	case "-":
+		p.next()
+		operand, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return negateNode{pos: tok.pos, operand: operand}, nil
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
+
+func (p *parser) parseIdent() (node, error) {
+	tok := p.next()
+	switch tok.value {
+	case "true":
+		return literalNode{value: true}, nil
+	case "false":
+		return literalNode{value: false}, nil
+	case "null":
+		return literalNode{value: nil}, nil
+	case "if":
+		return p.parseIf()
+	}
+
+	if slices.Contains(keywords, tok.value) || slices.Contains(unsupportedKeywords, tok.value) {
+		return nil, p.unexpected(tok)
+	}
+
+	call := callNode{pos: tok.pos, name: tok.value}
+	if !p.isPunct("(") {
+		return call, p.checkCall(call)
+	}
+
+	p.next()
+	for {
+		arg, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+
+		if p.isPunct(";") {
+			p.next()
+			continue
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		return call, p.checkCall(call)
+	}
+}
+
+func (p *parser) checkCall(call callNode) error {
+	arities, ok := builtinArities[call.name]
+	if !ok && slices.Contains(unsupportedFunctions, call.name) {
+		return newError(p.expression, call.pos, "unsupported jq function %q", call.name)
+	}
+	if !ok {
+		return newError(p.expression, call.pos, "unknown function %s", call.name)
+	}
+	if !slices.Contains(arities, len(call.args)) {
+		return newError(p.expression, call.pos, "function %s does not take %d arguments", call.name, len(call.args))
+	}
+	return nil
+}
+
+// parseIf parses the rest of "if c then a elif c then b else d end". The
+// else branch is optional and defaults to the input.
+func (p *parser) parseIf() (node, error) {
+	cond, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("then"); err != nil {
+		return nil, err
+	}
+	then, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+
+	n := ifNode{cond: cond, then: then, otherwise: identityNode{}}
+	switch {
+	case p.isKeyword("elif"):
+		p.next()
+		n.otherwise, err = p.parseIf()
+		return n, err
+	case p.isKeyword("else"):
+		p.next()
+		n.otherwise, err = p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return n, p.expectKeyword("end")
+}
+
+// parseObject parses {a: .x, "b": .y, (.k): .v, c}. Like in jq, values
+// cannot contain a comma unless they are parenthesized.
+func (p *parser) parseObject() (node, error) {
+	p.next()
+	var n objectNode
+	for !p.isPunct("}") {
+		entry, err := p.parseObjectEntry()
+		if err != nil {
+			return nil, err
+		}
+		n.entries = append(n.entries, entry)
+
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+
+	return n, p.expectPunct("}")
+}
+
+func (p *parser) parseObjectEntry() (objectEntry, error) {
+	tok := p.peek()
+	var entry objectEntry
+	switch {
+	case tok.kind == tokenIdent || tok.kind == tokenString:
+		p.next()
+		entry.key = literalNode{value: tok.value}
+		entry.value = indexNode{pos: tok.pos, source: identityNode{}, key: literalNode{value: tok.value}}
+	case p.isPunct("("):
+		p.next()
+		key, err := p.parsePipe()
+		if err != nil {
+			return objectEntry{}, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return objectEntry{}, err
+		}
+		entry.key = key
+		entry.keyPos = tok.pos
+	case isUnsupported(tok):
+		return objectEntry{}, p.unexpected(tok)
+	default:
+		return objectEntry{}, newError(p.expression, tok.pos, "expected an object key but found %q", tok.text)
+	}
+
+	if !p.isPunct(":") {
+		if entry.value == nil {
+			return objectEntry{}, newError(p.expression, p.peek().pos, "expected \":\" after a computed key")
+		}
+		return entry, nil
+	}
+
+	p.next()
+	value, err := p.parseAlternative()
+	if err != nil {
+		return objectEntry{}, err
+	}
+	entry.value = value
+
+	return entry, nil
+}
@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Body is the JSON body of a GraphQL request.
+func Body(query string, variables map[string]any) ([]byte, error) {
+	payload := map[string]any{"query": query}
+	if len(variables) > 0 {
+		payload["variables"] = variables
+	}
+	return json.Marshal(payload)
+}
+
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations"`
+	Path      []any      `json:"path"`
+}
+
+// String prints an error as message (path users.0.name, line 2:3).
+func (e Error) String() string {
+	var details []string
+	if len(e.Path) > 0 {
+		path := make([]string, len(e.Path))
+		for i, segment := range e.Path {
+			path[i] = fmt.Sprint(segment)
+		}
+		details = append(details, "path "+strings.Join(path, "."))
+	}
+	for _, location := range e.Locations {
+		details = append(details, fmt.Sprintf("line %d:%d", location.Line, location.Column))
+	}
+
+	if len(details) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, strings.Join(details, ", "))
+}
+
+// Response is a GraphQL response. Data is the raw JSON of the data
+// member, nil when missing or null.
+type Response struct {
+	Data   json.RawMessage
+	Errors []Error
+}
+
+// ParseResponse splits a response body into its data and errors.
+func ParseResponse(body []byte) (*Response, error) {
+	var raw struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []Error         `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, errors.New("the response is not a GraphQL response")
+	}
+	if raw.Data == nil && raw.Errors == nil {
+		return nil, errors.New("the response has neither data nor errors")
+	}
+
+	response := &Response{Errors: raw.Errors}
+	if string(raw.Data) != "null" {
+		response.Data = raw.Data
+	}
+	return response, nil
+}
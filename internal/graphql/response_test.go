@@ -0,0 +1,85 @@
+package graphql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBody(t *testing.T) {
+	body, err := Body("{ a }", nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"query":"{ a }"}`, string(body))
+
+	body, err = Body("query($id: ID!) { a(id: $id) }", map[string]any{"id": "1"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"query":"query($id: ID!) { a(id: $id) }","variables":{"id":"1"}}`, string(body))
+}
+
+func TestParseResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected *Response
+		err      string
+	}{
+		{
+			name:     "data",
+			body:     `{"data":{"user":{"id":"1"}}}`,
+			expected: &Response{Data: json.RawMessage(`{"user":{"id":"1"}}`)},
+		},
+		{
+			name: "errors without data",
+			body: `{"data":null,"errors":[{"message":"not found","path":["user"]}]}`,
+			expected: &Response{Errors: []Error{
+				{Message: "not found", Path: []any{"user"}},
+			}},
+		},
+		{
+			name: "not json",
+			body: `<html>`,
+			err:  "the response is not a GraphQL response",
+		},
+		{
+			name: "not graphql",
+			body: `{"status":"ok"}`,
+			err:  "the response has neither data nor errors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := ParseResponse([]byte(tt.body))
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, response)
+		})
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	tests := []struct {
+		err      Error
+		expected string
+	}{
+		{err: Error{Message: "boom"}, expected: "boom"},
+		{
+			err:      Error{Message: "no name", Path: []any{"users", float64(0), "name"}},
+			expected: "no name (path users.0.name)",
+		},
+		{
+			err:      Error{Message: "syntax", Locations: []Location{{Line: 2, Column: 3}}},
+			expected: "syntax (line 2:3)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.err.String())
+		})
+	}
+}
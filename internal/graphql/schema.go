@@ -0,0 +1,242 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// IntrospectionQuery asks for what Schema needs to describe the types.
+const IntrospectionQuery = `query Introspection {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types {
+      kind
+      name
+      description
+      fields(includeDeprecated: true) {
+        name
+        description
+        args { name type { ...TypeRef } defaultValue }
+        type { ...TypeRef }
+        isDeprecated
+      }
+      inputFields { name description type { ...TypeRef } defaultValue }
+      interfaces { name }
+      enumValues(includeDeprecated: true) { name description isDeprecated }
+      possibleTypes { name }
+    }
+  }
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } }
+}`
+
+type Schema struct {
+	QueryType        *named       `json:"queryType"`
+	MutationType     *named       `json:"mutationType"`
+	SubscriptionType *named       `json:"subscriptionType"`
+	Types            []SchemaType `json:"types"`
+}
+
+type named struct {
+	Name string `json:"name"`
+}
+
+type SchemaType struct {
+	Kind          string       `json:"kind"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Fields        []field      `json:"fields"`
+	InputFields   []inputValue `json:"inputFields"`
+	Interfaces    []named      `json:"interfaces"`
+	EnumValues    []enumValue  `json:"enumValues"`
+	PossibleTypes []named      `json:"possibleTypes"`
+}
+
+type field struct {
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	Args         []inputValue `json:"args"`
+	Type         typeRef      `json:"type"`
+	IsDeprecated bool         `json:"isDeprecated"`
+}
+
+type inputValue struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Type         typeRef `json:"type"`
+	DefaultValue *string `json:"defaultValue"`
+}
+
+type enumValue struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	IsDeprecated bool   `json:"isDeprecated"`
+}
+
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+func (t typeRef) String() string {
+	switch t.Kind {
+	case "NON_NULL":
+		if t.OfType != nil {
+			return t.OfType.String() + "!"
+		}
+	case "LIST":
+		if t.OfType != nil {
+			return "[" + t.OfType.String() + "]"
+		}
+	}
+	return t.Name
+}
+
+// ParseSchema reads the data of the introspection query.
+func ParseSchema(data json.RawMessage) (*Schema, error) {
+	var wrapper struct {
+		Schema *Schema `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil || wrapper.Schema == nil {
+		return nil, fmt.Errorf("the introspection response has no __schema")
+	}
+	return wrapper.Schema, nil
+}
+
+// keywords are the SDL keywords of each kind of type.
+var keywords = map[string]string{
+	"OBJECT":       "type",
+	"INTERFACE":    "interface",
+	"UNION":        "union",
+	"ENUM":         "enum",
+	"INPUT_OBJECT": "input",
+	"SCALAR":       "scalar",
+}
+
+// Summary lists the root operations and every type, one per line, with
+// the first line of its description. Introspection types are left out.
+func (s *Schema) Summary() string {
+	var b strings.Builder
+	for _, root := range []struct {
+		operation string
+		t         *named
+	}{{"query", s.QueryType}, {"mutation", s.MutationType}, {"subscription", s.SubscriptionType}} {
+		if root.t != nil {
+			fmt.Fprintf(&b, "%s: %s\n", root.operation, root.t.Name)
+		}
+	}
+	b.WriteString("\n")
+
+	for _, t := range s.Types {
+		if strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		line := keywords[t.Kind] + " " + t.Name
+		if description := firstLine(t.Description); description != "" {
+			line += "  # " + description
+		}
+		b.WriteString(line + "\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Describe prints a type in the schema definition language.
+func (s *Schema) Describe(name string) (string, error) {
+	for _, t := range s.Types {
+		if strings.EqualFold(t.Name, name) {
+			return t.sdl(), nil
+		}
+	}
+	return "", fmt.Errorf("type %s not found in the schema", name)
+}
+
+func (t SchemaType) sdl() string {
+	var b strings.Builder
+	writeDescription(&b, t.Description, "")
+
+	header := keywords[t.Kind] + " " + t.Name
+	if len(t.Interfaces) > 0 {
+		names := make([]string, len(t.Interfaces))
+		for i, iface := range t.Interfaces {
+			names[i] = iface.Name
+		}
+		header += " implements " + strings.Join(names, " & ")
+	}
+
+	switch t.Kind {
+	case "UNION":
+		names := make([]string, len(t.PossibleTypes))
+		for i, possible := range t.PossibleTypes {
+			names[i] = possible.Name
+		}
+		b.WriteString(header + " = " + strings.Join(names, " | "))
+	case "SCALAR":
+		b.WriteString(header)
+	case "ENUM":
+		b.WriteString(header + " {\n")
+		for _, value := range t.EnumValues {
+			writeDescription(&b, value.Description, "  ")
+			b.WriteString("  " + value.Name + deprecated(value.IsDeprecated) + "\n")
+		}
+		b.WriteString("}")
+	case "INPUT_OBJECT":
+		b.WriteString(header + " {\n")
+		for _, input := range t.InputFields {
+			writeDescription(&b, input.Description, "  ")
+			b.WriteString("  " + input.String() + "\n")
+		}
+		b.WriteString("}")
+	default:
+		b.WriteString(header + " {\n")
+		for _, f := range t.Fields {
+			writeDescription(&b, f.Description, "  ")
+			b.WriteString("  " + f.Name)
+			if len(f.Args) > 0 {
+				args := make([]string, len(f.Args))
+				for i, arg := range f.Args {
+					args[i] = arg.String()
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String() + deprecated(f.IsDeprecated) + "\n")
+		}
+		b.WriteString("}")
+	}
+
+	return b.String()
+}
+
+func (v inputValue) String() string {
+	s := v.Name + ": " + v.Type.String()
+	if v.DefaultValue != nil {
+		s += " = " + *v.DefaultValue
+	}
+	return s
+}
+
+func deprecated(isDeprecated bool) string {
+	if isDeprecated {
+		return " @deprecated"
+	}
+	return ""
+}
+
+func writeDescription(b *strings.Builder, description, indent string) {
+	for line := range strings.Lines(strings.TrimSpace(description)) {
+		b.WriteString(indent + "# " + strings.TrimRight(line, "\n") + "\n")
+	}
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
@@ -0,0 +1,106 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const introspection = `{"__schema": {
+  "queryType": {"name": "Query"},
+  "mutationType": null,
+  "subscriptionType": null,
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "args": [
+        {"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}
+      ], "type": {"kind": "OBJECT", "name": "User"}},
+      {"name": "users", "args": [
+        {"name": "first", "type": {"kind": "SCALAR", "name": "Int"}, "defaultValue": "10"}
+      ], "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "NON_NULL", "ofType": {"kind": "OBJECT", "name": "User"}}}}}
+    ]},
+    {"kind": "OBJECT", "name": "User", "description": "A person.\nWith an account.", "interfaces": [{"name": "Node"}], "fields": [
+      {"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}},
+      {"name": "login", "description": "Old name.", "type": {"kind": "SCALAR", "name": "String"}, "isDeprecated": true}
+    ]},
+    {"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}, {"name": "GUEST"}]},
+    {"kind": "UNION", "name": "Result", "possibleTypes": [{"name": "User"}, {"name": "Team"}]},
+    {"kind": "INPUT_OBJECT", "name": "UserFilter", "inputFields": [
+      {"name": "role", "type": {"kind": "ENUM", "name": "Role"}, "defaultValue": "GUEST"}
+    ]},
+    {"kind": "SCALAR", "name": "ID"},
+    {"kind": "OBJECT", "name": "__Type"}
+  ]
+}}`
+
+func TestParseSchema(t *testing.T) {
+	schema, err := ParseSchema([]byte(introspection))
+	require.NoError(t, err)
+	require.Equal(t, "Query", schema.QueryType.Name)
+	require.Nil(t, schema.MutationType)
+	require.Len(t, schema.Types, 7)
+
+	_, err = ParseSchema([]byte(`{"user": null}`))
+	require.EqualError(t, err, "the introspection response has no __schema")
+}
+
+func TestSummary(t *testing.T) {
+	schema, err := ParseSchema([]byte(introspection))
+	require.NoError(t, err)
+
+	expected := `query: Query
+
+type Query
+type User  # A person.
+enum Role
+union Result
+input UserFilter
+scalar ID`
+	require.Equal(t, expected, schema.Summary())
+}
+
+func TestDescribe(t *testing.T) {
+	schema, err := ParseSchema([]byte(introspection))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		expected string
+		err      string
+	}{
+		{
+			name: "Query",
+			expected: `type Query {
+  user(id: ID!): User
+  users(first: Int = 10): [User!]!
+}`,
+		},
+		{
+			name: "user",
+			expected: `# A person.
+# With an account.
+type User implements Node {
+  id: ID!
+  # Old name.
+  login: String @deprecated
+}`,
+		},
+		{name: "Role", expected: "enum Role {\n  ADMIN\n  GUEST\n}"},
+		{name: "Result", expected: "union Result = User | Team"},
+		{name: "UserFilter", expected: "input UserFilter {\n  role: Role = GUEST\n}"},
+		{name: "ID", expected: "scalar ID"},
+		{name: "Team", err: "type Team not found in the schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description, err := schema.Describe(tt.name)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, description)
+		})
+	}
+}
@@ -0,0 +1,272 @@
+// Package graphql builds GraphQL requests and reads their responses.
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Type is a GraphQL type reference such as [ID!]!.
+type Type struct {
+	Name    string
+	List    *Type
+	NonNull bool
+}
+
+func (t Type) String() string {
+	s := t.Name
+	if t.List != nil {
+		s = "[" + t.List.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Variables turns option values into the variables declared by query,
+// typed after their declaration: "42" is a number for an Int and a string
+// for an ID. Lists and input objects are written as JSON.
+func Variables(query string, options map[string]string) (map[string]any, error) {
+	definitions, err := VariableDefinitions(query)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	variables := make(map[string]any, len(options))
+	for _, name := range names {
+		t, declared := definitions[name]
+		if !declared {
+			return nil, fmt.Errorf("variable $%s is not declared by the query", name)
+		}
+
+		value, err := coerce(options[name], t)
+		if err != nil {
+			return nil, fmt.Errorf("variable $%s: %w", name, err)
+		}
+		variables[name] = value
+	}
+
+	return variables, nil
+}
+
+func coerce(value string, t Type) (any, error) {
+	if value == "null" {
+		if t.NonNull {
+			return nil, fmt.Errorf("null given for the non-null type %s", t)
+		}
+		return nil, nil
+	}
+
+	if t.List != nil {
+		var items []json.RawMessage
+		if err := json.Unmarshal([]byte(value), &items); err != nil {
+			// A single value stands for a list of one, as in GraphQL.
+			item, err := coerce(value, *t.List)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+
+		list := make([]any, len(items))
+		for i, item := range items {
+			var err error
+			if list[i], err = coerceJSON(item, *t.List); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	}
+
+	switch t.Name {
+	case "Int":
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an Int", value)
+		}
+		return n, nil
+	case "Float":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a Float", value)
+		}
+		return f, nil
+	case "Boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a Boolean", value)
+		}
+		return b, nil
+	case "String", "ID":
+		return value, nil
+	}
+
+	// Input objects and custom scalars are taken as JSON when they parse,
+	// enums and other scalars as strings.
+	var decoded any
+	if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+		return decoded, nil
+	}
+	return value, nil
+}
+
+// coerceJSON checks a list item given as JSON.
+func coerceJSON(item json.RawMessage, t Type) (any, error) {
+	var decoded any
+	if err := json.Unmarshal(item, &decoded); err != nil {
+		return nil, err
+	}
+	if s, ok := decoded.(string); ok {
+		return coerce(s, t)
+	}
+	if decoded == nil {
+		return coerce("null", t)
+	}
+	return coerce(string(item), t)
+}
+
+// VariableDefinitions reads the variables declared by the first operation
+// of query, as in query Users($first: Int = 10, $role: Role!).
+func VariableDefinitions(query string) (map[string]Type, error) {
+	tokens := tokenize(query)
+	definitions := map[string]Type{}
+
+	// Only the operation header, before the selection set, is read.
+	start := slices.Index(tokens, "(")
+	if brace := slices.Index(tokens, "{"); start < 0 || (brace >= 0 && brace < start) {
+		return definitions, nil
+	}
+
+	p := &typeParser{tokens: tokens, pos: start + 1}
+	for p.peek() != ")" {
+		if p.peek() == "" {
+			return nil, fmt.Errorf("unterminated variable definitions")
+		}
+
+		name := p.next()
+		if !strings.HasPrefix(name, "$") || len(name) == 1 {
+			return nil, fmt.Errorf("expected a variable, got %q", name)
+		}
+		if p.next() != ":" {
+			return nil, fmt.Errorf("expected : after %s", name)
+		}
+
+		t, err := p.parseType()
+		if err != nil {
+			return nil, fmt.Errorf("type of %s: %w", name, err)
+		}
+		definitions[name[1:]] = t
+
+		// Default values and directives are skipped up to the next variable.
+		for p.peek() != ")" && p.peek() != "" && !strings.HasPrefix(p.peek(), "$") {
+			p.next()
+		}
+	}
+
+	return definitions, nil
+}
+
+type typeParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *typeParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *typeParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *typeParser) parseType() (Type, error) {
+	var t Type
+	switch token := p.next(); {
+	case token == "[":
+		item, err := p.parseType()
+		if err != nil {
+			return Type{}, err
+		}
+		if p.next() != "]" {
+			return Type{}, fmt.Errorf("expected ]")
+		}
+		t.List = &item
+	case token != "" && isNameStart(rune(token[0])):
+		t.Name = token
+	default:
+		return Type{}, fmt.Errorf("unexpected %q", token)
+	}
+
+	if p.peek() == "!" {
+		p.next()
+		t.NonNull = true
+	}
+	return t, nil
+}
+
+// tokenize splits a GraphQL document into names, variables, punctuation
+// and string literals, dropping comments, commas and white space.
+func tokenize(document string) []string {
+	var tokens []string
+	for i := 0; i < len(document); {
+		r, size := utf8.DecodeRuneInString(document[i:])
+		end := i + size
+		switch {
+		case r == '#':
+			end = strings.IndexByte(document[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end
+			continue
+		case unicode.IsSpace(r) || r == ',' || r == '\ufeff':
+			i = end
+			continue
+		case strings.HasPrefix(document[i:], `"""`):
+			end = len(document)
+			if closing := strings.Index(document[i+3:], `"""`); closing >= 0 {
+				end = i + 3 + closing + 3
+			}
+		case r == '"':
+			for end < len(document) && document[end] != '"' && document[end] != '\n' {
+				if document[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(document))
+		case r == '$' || r == '-' || isNameStart(r) || unicode.IsDigit(r):
+			for end < len(document) {
+				c := rune(document[end])
+				if !isNameStart(c) && !unicode.IsDigit(c) && c != '.' {
+					break
+				}
+				end++
+			}
+		}
+		tokens = append(tokens, document[i:end])
+		i = end
+	}
+	return tokens
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
@@ -0,0 +1,132 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariableDefinitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected map[string]Type
+		err      string
+	}{
+		{
+			name:     "no variables",
+			query:    `{ users { id } }`,
+			expected: map[string]Type{},
+		},
+		{
+			name:  "scalars, lists and defaults",
+			query: "query Users($first: Int = 10, $ids: [ID!]!, $role: Role @deprecated) {\n users(first: $first) { id }\n}",
+			expected: map[string]Type{
+				"first": {Name: "Int"},
+				"ids":   {List: &Type{Name: "ID", NonNull: true}, NonNull: true},
+				"role":  {Name: "Role"},
+			},
+		},
+		{
+			name:     "comments and strings",
+			query:    "# list users\nquery($q: String! # the search\n = \"a, b\") { search(q: $q) }",
+			expected: map[string]Type{"q": {Name: "String", NonNull: true}},
+		},
+		{
+			name:     "arguments of fields are not definitions",
+			query:    `{ user(id: 1) { name } }`,
+			expected: map[string]Type{},
+		},
+		{
+			name:  "unterminated",
+			query: `query($id: ID!`,
+			err:   "unterminated variable definitions",
+		},
+		{
+			name:  "missing type",
+			query: `query($id) { a }`,
+			err:   "expected : after $id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := VariableDefinitions(tt.query)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, definitions)
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	query := `query($id: ID!, $count: Int, $ratio: Float, $on: Boolean, $tags: [String], $ids: [Int!], $filter: UserFilter, $role: Role) { a }`
+
+	tests := []struct {
+		name     string
+		options  map[string]string
+		expected map[string]any
+		err      string
+	}{
+		{
+			name:     "scalars",
+			options:  map[string]string{"id": "42", "count": "3", "ratio": "0.5", "on": "true"},
+			expected: map[string]any{"id": "42", "count": int64(3), "ratio": 0.5, "on": true},
+		},
+		{
+			name:     "lists",
+			options:  map[string]string{"tags": `["a","b"]`, "ids": "7"},
+			expected: map[string]any{"tags": []any{"a", "b"}, "ids": []any{int64(7)}},
+		},
+		{
+			name:     "list of numbers",
+			options:  map[string]string{"ids": "[1, 2]"},
+			expected: map[string]any{"ids": []any{int64(1), int64(2)}},
+		},
+		{
+			name:     "input object and enum",
+			options:  map[string]string{"filter": `{"active":true}`, "role": "ADMIN"},
+			expected: map[string]any{"filter": map[string]any{"active": true}, "role": "ADMIN"},
+		},
+		{
+			name:     "null",
+			options:  map[string]string{"count": "null"},
+			expected: map[string]any{"count": nil},
+		},
+		{
+			name:    "null for a non-null type",
+			options: map[string]string{"id": "null"},
+			err:     "variable $id: null given for the non-null type ID!",
+		},
+		{
+			name:    "not an int",
+			options: map[string]string{"count": "three"},
+			err:     `variable $count: "three" is not an Int`,
+		},
+		{
+			name:    "null in a non-null list",
+			options: map[string]string{"ids": "[1, null]"},
+			err:     "variable $ids: null given for the non-null type Int!",
+		},
+		{
+			name:    "undeclared",
+			options: map[string]string{"name": "x"},
+			err:     "variable $name is not declared by the query",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variables, err := Variables(query, tt.options)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, variables)
+		})
+	}
+}
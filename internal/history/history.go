@@ -0,0 +1,247 @@
+// Package history keeps an append-only log of the requests sent, so they
+// can be listed, searched and replayed.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ashttp/internal/config"
+	"github.com/ashttp/internal/jsonvalue"
+)
+
+// Entry is a request as it was sent, secrets redacted, and how it went.
+type Entry struct {
+	ID       int               `json:"id"`
+	Time     time.Time         `json:"time"`
+	Alias    string            `json:"alias"`
+	Method   string            `json:"method"`
+	Path     []string          `json:"path,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	URL      string            `json:"url"`
+	Status   int               `json:"status,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Duration config.Duration   `json:"duration"`
+
+	// BodyOmitted is set when the body was too large, binary or of a type
+	// whose secrets cannot be found to be kept.
+	BodyOmitted bool `json:"bodyOmitted,omitempty"`
+
+	// ContentType is the type of the body as sent, which tells how to
+	// redact it.
+	ContentType string `json:"-"`
+}
+
+var defaultFilePath = path.Join(os.ExpandEnv("$HOME"), ".config", "ashttp", "history.jsonl")
+
+func GetDefaultPath() string {
+	return defaultFilePath
+}
+
+// Append redacts entry, numbers it after the last entry and adds it to the
+// history file.
+func Append(entry Entry) (Entry, error) {
+	return appendToFile(defaultFilePath, entry)
+}
+
+// Load reads every entry of the history file, oldest first.
+func Load() ([]Entry, error) {
+	return loadFromFile(defaultFilePath)
+}
+
+func appendToFile(filePath string, entry Entry) (Entry, error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return Entry{}, err
+	}
+
+	// Another ashttp process appending between lastID and the write would
+	// get the same id.
+	unlock, err := lock(filePath)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer unlock()
+
+	last, err := lastID(filePath)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry = Redact(entry)
+	entry.ID = last + 1
+
+	data, err := jsonvalue.Marshal(entry)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	// Requests may carry personal data, the file is only readable by its owner.
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+const (
+	// lockTimeout is how long to wait for another process to finish
+	// appending to the history.
+	lockTimeout = 5 * time.Second
+	// staleLockAge is the age after which a lock file is considered left by
+	// a process that crashed.
+	staleLockAge = 30 * time.Second
+)
+
+// lock takes the lock file next to the history file, created with O_EXCL so
+// it works the same on every platform, and returns the function releasing
+// it.
+func lock(filePath string) (func(), error) {
+	lockPath := filePath + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to lock history file: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to lock history file: %s is held by another ashttp process, remove it if none is running", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// tailChunkSize is how much of the end of the history file is read at a
+// time to find its last entry.
+const tailChunkSize = 4 << 10
+
+// lastID returns the id of the last entry, reading the file backwards
+// so that appending does not get slower as the history grows.
+func lastID(filePath string) (int, error) {
+	file, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read history file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	var tail []byte
+	for offset := info.Size(); ; {
+		start := max(offset-tailChunkSize, 0)
+		chunk := make([]byte, offset-start)
+		if _, err := file.ReadAt(chunk, start); err != nil {
+			return 0, fmt.Errorf("failed to read history file: %w", err)
+		}
+		tail = append(chunk, tail...)
+		offset = start
+
+		last := bytes.TrimRight(tail, "\n")
+		newline := bytes.LastIndexByte(last, '\n')
+		if newline < 0 && offset > 0 {
+			continue
+		}
+
+		last = last[newline+1:]
+		if len(last) == 0 {
+			return 0, nil
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(last, &entry); err != nil {
+			return 0, fmt.Errorf("failed to parse the last entry of %s: %w", filePath, err)
+		}
+		return entry.ID, nil
+	}
+}
+
+func loadFromFile(filePath string) ([]Entry, error) {
+	file, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse line %d of %s: %w", line, filePath, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// Find returns the entry numbered id.
+func Find(entries []Entry, id int) (Entry, error) {
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return Entry{}, fmt.Errorf("no entry %d in the history", id)
+}
+
+// Search keeps the entries matching every term, ignoring case. A term
+// matches the alias, the method, the URL or the status.
+func Search(entries []Entry, terms []string) []Entry {
+	var found []Entry
+	for _, entry := range entries {
+		text := strings.ToLower(strings.Join([]string{
+			entry.Alias, entry.Method, entry.URL, fmt.Sprint(entry.Status),
+		}, " "))
+
+		matches := true
+		for _, term := range terms {
+			if !strings.Contains(text, strings.ToLower(term)) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
@@ -0,0 +1,158 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "ashttp", "history.jsonl")
+
+	entries, err := loadFromFile(filePath)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	sent := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	first, err := appendToFile(filePath, Entry{
+		Time:    sent,
+		Alias:   "api",
+		Method:  "get",
+		Path:    []string{"users"},
+		Options: map[string]string{"page": "2", "token": "abc"},
+		URL:     "https://api.example.com/users?page=2&token=abc",
+		Status:  200,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, first.ID)
+	require.Equal(t, Redacted, first.Options["token"])
+
+	second, err := appendToFile(filePath, Entry{Time: sent, Alias: "api", Method: "delete", URL: "https://api.example.com/users/1"})
+	require.NoError(t, err)
+	require.Equal(t, 2, second.ID)
+
+	entries, err = loadFromFile(filePath)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{first, second}, entries)
+
+	info, err := os.Stat(filePath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestAppendConcurrently(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "history.jsonl")
+
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Go(func() {
+			_, errs[i] = appendToFile(filePath, Entry{Alias: "api", Method: "get"})
+		})
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	entries, err := loadFromFile(filePath)
+	require.NoError(t, err)
+	ids := map[int]bool{}
+	for _, entry := range entries {
+		ids[entry.ID] = true
+	}
+	require.Len(t, ids, 20)
+	require.NoFileExists(t, filePath+".lock")
+}
+
+func TestAppendStaleLock(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(filePath+".lock", nil, 0600))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filePath+".lock", old, old))
+
+	entry, err := appendToFile(filePath, Entry{Alias: "api", Method: "get"})
+	require.NoError(t, err)
+	require.Equal(t, 1, entry.ID)
+}
+
+func TestLastID(t *testing.T) {
+	long := `{"id":7,"body":"` + strings.Repeat("a", 3*tailChunkSize) + `"}`
+	tests := []struct {
+		name     string
+		content  string
+		expected int
+	}{
+		{name: "empty", content: ""},
+		{name: "blank lines", content: "\n\n"},
+		{name: "single entry", content: "{\"id\":3}\n", expected: 3},
+		{name: "last entry", content: "{\"id\":1}\n{\"id\":2}\n\n", expected: 2},
+		{name: "entry longer than a chunk", content: "{\"id\":6}\n" + long + "\n", expected: 7},
+		{name: "after an entry longer than a chunk", content: long + "\n{\"id\":8}\n", expected: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "history.jsonl")
+			require.NoError(t, os.WriteFile(filePath, []byte(tt.content), 0600))
+
+			id, err := lastID(filePath)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, id)
+		})
+	}
+}
+
+func TestLoadInvalidLine(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(filePath, []byte("{\"id\":1}\n\nnot json\n"), 0600))
+
+	_, err := loadFromFile(filePath)
+	require.ErrorContains(t, err, "failed to parse line 3")
+}
+
+func TestFind(t *testing.T) {
+	entries := []Entry{{ID: 1}, {ID: 2}}
+
+	entry, err := Find(entries, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, entry.ID)
+
+	_, err = Find(entries, 3)
+	require.EqualError(t, err, "no entry 3 in the history")
+}
+
+func TestSearch(t *testing.T) {
+	entries := []Entry{
+		{ID: 1, Alias: "api", Method: "get", URL: "https://api.example.com/users", Status: 200},
+		{ID: 2, Alias: "api", Method: "post", URL: "https://api.example.com/users", Status: 422},
+		{ID: 3, Alias: "github", Method: "get", URL: "https://api.github.com/repos", Status: 200},
+	}
+
+	tests := []struct {
+		name     string
+		terms    []string
+		expected []int
+	}{
+		{name: "no terms", expected: []int{1, 2, 3}},
+		{name: "alias", terms: []string{"github"}, expected: []int{3}},
+		{name: "every term", terms: []string{"USERS", "post"}, expected: []int{2}},
+		{name: "status", terms: []string{"422"}, expected: []int{2}},
+		{name: "nothing", terms: []string{"orders"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []int
+			for _, entry := range Search(entries, tt.terms) {
+				ids = append(ids, entry.ID)
+			}
+			require.Equal(t, tt.expected, ids)
+		})
+	}
+}
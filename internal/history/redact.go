@@ -0,0 +1,208 @@
+package history
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ashttp/internal/http"
+	"github.com/ashttp/internal/jsonvalue"
+)
+
+// Redacted replaces the secrets of an entry.
+const Redacted = "[redacted]"
+
+// maxBodySize is the size above which bodies are not kept.
+const maxBodySize = 64 << 10
+
+// IsSecret reports whether a header, option, query parameter or JSON field
+// named name holds a secret, as api_key or Authorization do.
+func IsSecret(name string) bool {
+	return http.IsSensitiveHeader(strings.ReplaceAll(name, "_", "-"))
+}
+
+// Redact hides the secrets of entry: sensitive options, headers, query
+// parameters, URL passwords and the fields of JSON, form and multipart
+// bodies. Other bodies are not kept, as they may hold secrets too.
+func Redact(entry Entry) Entry {
+	entry.Options = redactValues(entry.Options, func(name, value string) string { return Redacted })
+	entry.Headers = redactValues(entry.Headers, http.RedactHeader)
+	entry.URL = RedactURL(entry.URL)
+
+	if entry.Body == "" {
+		return entry
+	}
+
+	body, ok := "", len(entry.Body) <= maxBodySize && utf8.ValidString(entry.Body)
+	if ok {
+		body, ok = redactBody(entry.Body, entry.ContentType)
+	}
+	if !ok {
+		body = ""
+		entry.BodyOmitted = true
+	}
+	entry.Body = body
+
+	return entry
+}
+
+func redactValues(values map[string]string, redact func(name, value string) string) map[string]string {
+	if values == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(values))
+	for name, value := range values {
+		if IsSecret(name) {
+			value = redact(name, value)
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// RedactURL hides the sensitive query parameters and the password of
+// rawURL.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	if u.RawQuery != "" {
+		u.RawQuery = redactQuery(u.RawQuery)
+	}
+
+	user := u.User
+	if _, hasPassword := user.Password(); !hasPassword {
+		return u.String()
+	}
+	u.User = nil
+	return strings.Replace(u.String(), "://", "://"+url.PathEscape(user.Username())+":"+Redacted+"@", 1)
+}
+
+// redactQuery hides the sensitive values of a query string, rewritten pair
+// by pair to keep its order and escaping.
+func redactQuery(query string) string {
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		name, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil && IsSecret(unescaped) {
+			pairs[i] = name + "=" + Redacted
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// redactBody hides the sensitive fields of a body. It fails for bodies of
+// other types than JSON, forms and multipart forms, or that cannot be
+// parsed as their type.
+func redactBody(body, contentType string) (string, bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// Without a valid type, only JSON is recognized.
+		mediaType = "application/json"
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return redactJSONBody(body)
+	case mediaType == "application/x-www-form-urlencoded":
+		if _, err := url.ParseQuery(body); err != nil {
+			return "", false
+		}
+		return redactQuery(body), true
+	case mediaType == "multipart/form-data":
+		return redactMultipart(body, params["boundary"])
+	}
+	return "", false
+}
+
+// redactJSONBody hides the sensitive fields of a JSON body, at any depth.
+// A body without any is kept as written.
+func redactJSONBody(body string) (string, bool) {
+	value, err := jsonvalue.Decode([]byte(body))
+	if err != nil {
+		return "", false
+	}
+	if !redactJSON(value) {
+		return body, true
+	}
+
+	data, err := jsonvalue.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// redactMultipart hides the content of the sensitive fields of a multipart
+// form, its other parts being kept with their headers.
+func redactMultipart(body, boundary string) (string, bool) {
+	if boundary == "" {
+		return "", false
+	}
+
+	reader := multipart.NewReader(strings.NewReader(body), boundary)
+	var redacted bytes.Buffer
+	writer := multipart.NewWriter(&redacted)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return "", false
+	}
+
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return "", false
+		}
+		if IsSecret(part.FormName()) {
+			content = []byte(Redacted)
+		}
+
+		w, err := writer.CreatePart(textproto.MIMEHeader(part.Header))
+		if err != nil {
+			return "", false
+		}
+		if _, err := w.Write(content); err != nil {
+			return "", false
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", false
+	}
+	return redacted.String(), true
+}
+
+func redactJSON(value any) bool {
+	changed := false
+	switch v := value.(type) {
+	case *jsonvalue.Object:
+		for _, key := range v.Keys {
+			if IsSecret(key) {
+				v.Values[key] = Redacted
+				changed = true
+				continue
+			}
+			changed = redactJSON(v.Values[key]) || changed
+		}
+	case []any:
+		for _, item := range v {
+			changed = redactJSON(item) || changed
+		}
+	}
+	return changed
+}
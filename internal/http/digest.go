@@ -0,0 +1,188 @@
+package http
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ashttp/internal/config"
+)
+
+var errUnsupportedDigest = errors.New("unsupported digest challenge")
+
+type digestChallenge struct {
+	Realm     string
+	Nonce     string
+	Opaque    string
+	Algorithm string
+	QOP       []string
+}
+
+var digestAlgorithms = map[string]func() hash.Hash{
+	"MD5":          md5.New,
+	"MD5-SESS":     md5.New,
+	"SHA-256":      sha256.New,
+	"SHA-256-SESS": sha256.New,
+}
+
+var newCnonce = func() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// parseDigestChallenge picks the strongest supported Digest challenge from
+// the WWW-Authenticate header values of a 401 response.
+func parseDigestChallenge(headers []string) (digestChallenge, bool, error) {
+	var challenges []digestChallenge
+	for _, header := range headers {
+		scheme, params, _ := strings.Cut(strings.TrimSpace(header), " ")
+		if !strings.EqualFold(scheme, "digest") {
+			continue
+		}
+
+		challenge := digestChallenge{Algorithm: "MD5"}
+		for k, v := range parseAuthParams(params) {
+			switch k {
+			case "realm":
+				challenge.Realm = v
+			case "nonce":
+				challenge.Nonce = v
+			case "opaque":
+				challenge.Opaque = v
+			case "algorithm":
+				challenge.Algorithm = strings.ToUpper(v)
+			case "qop":
+				for _, qop := range strings.Split(v, ",") {
+					challenge.QOP = append(challenge.QOP, strings.TrimSpace(qop))
+				}
+			}
+		}
+		challenges = append(challenges, challenge)
+	}
+
+	if len(challenges) == 0 {
+		return digestChallenge{}, false, nil
+	}
+
+	var supported []digestChallenge
+	for _, c := range challenges {
+		_, knownAlgorithm := digestAlgorithms[c.Algorithm]
+		if knownAlgorithm && (len(c.QOP) == 0 || slices.Contains(c.QOP, "auth")) {
+			supported = append(supported, c)
+		}
+	}
+
+	if len(supported) == 0 {
+		return digestChallenge{}, true, fmt.Errorf(
+			"%w: algorithm %s, qop %s", errUnsupportedDigest, challenges[0].Algorithm, strings.Join(challenges[0].QOP, ","))
+	}
+
+	for _, c := range supported {
+		if strings.HasPrefix(c.Algorithm, "SHA-256") {
+			return c, true, nil
+		}
+	}
+
+	return supported[0], true, nil
+}
+
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimLeft(s, ", ") {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimSpace(rest)
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var quoted strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				quoted.WriteByte(rest[i])
+			}
+			value = quoted.String()
+			s = rest[min(i+1, len(rest)):]
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+		params[key] = value
+	}
+
+	return params
+}
+
+func digestAuthorization(c digestChallenge, creds config.DigestCredentials, method, uri, cnonce string) string {
+	newHash := digestAlgorithms[c.Algorithm]
+	h := func(parts ...string) string {
+		hasher := newHash()
+		hasher.Write([]byte(strings.Join(parts, ":")))
+		return hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	ha1 := h(creds.Username, c.Realm, creds.Password)
+	if strings.HasSuffix(c.Algorithm, "-SESS") {
+		ha1 = h(ha1, c.Nonce, cnonce)
+	}
+	ha2 := h(method, uri)
+
+	const nc = "00000001"
+	fields := []string{
+		fmt.Sprintf(`username="%s"`, creds.Username),
+		fmt.Sprintf(`realm="%s"`, c.Realm),
+		fmt.Sprintf(`nonce="%s"`, c.Nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf("algorithm=%s", c.Algorithm),
+	}
+
+	if len(c.QOP) == 0 {
+		fields = append(fields, fmt.Sprintf(`response="%s"`, h(ha1, c.Nonce, ha2)))
+	} else {
+		fields = append(fields,
+			"qop=auth",
+			"nc="+nc,
+			fmt.Sprintf(`cnonce="%s"`, cnonce),
+			fmt.Sprintf(`response="%s"`, h(ha1, c.Nonce, nc, cnonce, "auth", ha2)),
+		)
+	}
+
+	if c.Opaque != "" {
+		fields = append(fields, fmt.Sprintf(`opaque="%s"`, c.Opaque))
+	}
+
+	return "Digest " + strings.Join(fields, ", ")
+}
+
+// withDigestAuth answers a Digest challenge by returning a copy of req
+// carrying the computed Authorization header. The second return value is
+// false when the response holds no Digest challenge.
+func withDigestAuth(req *http.Request, resp *http.Response, creds config.DigestCredentials) (*http.Request, bool, error) {
+	challenge, found, err := parseDigestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if err != nil || !found {
+		return nil, found, err
+	}
+
+	retry, err := rewind(req)
+	if err != nil {
+		return nil, true, err
+	}
+
+	retry.Header.Set("Authorization",
+		digestAuthorization(challenge, creds, req.Method, req.URL.RequestURI(), newCnonce()))
+
+	return retry, true, nil
+}
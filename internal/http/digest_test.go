@@ -0,0 +1,269 @@
+package http
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDigestChallenge(t *testing.T) {
+	tests := []struct {
+		name              string
+		headers           []string
+		expectedChallenge digestChallenge
+		expectedFound     bool
+		expectError       bool
+	}{
+		{
+			name: "MD5 challenge with qop",
+			headers: []string{
+				`Digest realm="testrealm@host.com", qop="auth,auth-int", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", opaque="5ccc069c403ebaf9f0171e9517f40e41"`,
+			},
+			expectedChallenge: digestChallenge{
+				Realm:     "testrealm@host.com",
+				Nonce:     "dcd98b7102dd2f0e8b11d0f600bfb0c093",
+				Opaque:    "5ccc069c403ebaf9f0171e9517f40e41",
+				Algorithm: "MD5",
+				QOP:       []string{"auth", "auth-int"},
+			},
+			expectedFound: true,
+		},
+		{
+			name: "SHA-256 preferred over MD5",
+			headers: []string{
+				`Digest realm="r", nonce="n1", algorithm=MD5, qop="auth"`,
+				`Digest realm="r", nonce="n2", algorithm=SHA-256, qop="auth"`,
+			},
+			expectedChallenge: digestChallenge{
+				Realm:     "r",
+				Nonce:     "n2",
+				Algorithm: "SHA-256",
+				QOP:       []string{"auth"},
+			},
+			expectedFound: true,
+		},
+		{
+			name:    "legacy challenge without qop",
+			headers: []string{`Digest realm="r", nonce="n"`},
+			expectedChallenge: digestChallenge{
+				Realm:     "r",
+				Nonce:     "n",
+				Algorithm: "MD5",
+			},
+			expectedFound: true,
+		},
+		{
+			name:    "escaped quotes in realm",
+			headers: []string{`Digest realm="say \"hi\"", nonce="n", qop=auth`},
+			expectedChallenge: digestChallenge{
+				Realm:     `say "hi"`,
+				Nonce:     "n",
+				Algorithm: "MD5",
+				QOP:       []string{"auth"},
+			},
+			expectedFound: true,
+		},
+		{
+			name:          "basic challenge only",
+			headers:       []string{`Basic realm="r"`},
+			expectedFound: false,
+		},
+		{
+			name:          "only auth-int qop is unsupported",
+			headers:       []string{`Digest realm="r", nonce="n", qop="auth-int"`},
+			expectedFound: true,
+			expectError:   true,
+		},
+		{
+			name:          "unknown algorithm is unsupported",
+			headers:       []string{`Digest realm="r", nonce="n", algorithm=SHA-512-256, qop="auth"`},
+			expectedFound: true,
+			expectError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge, found, err := parseDigestChallenge(tt.headers)
+
+			require.Equal(t, tt.expectedFound, found)
+			if tt.expectError {
+				require.ErrorIs(t, err, errUnsupportedDigest)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedChallenge, challenge)
+		})
+	}
+}
+
+func TestDigestAuthorization(t *testing.T) {
+	tests := []struct {
+		name             string
+		challenge        digestChallenge
+		credentials      config.DigestCredentials
+		cnonce           string
+		expectedResponse string
+	}{
+		{
+			name: "RFC 2617 MD5 example",
+			challenge: digestChallenge{
+				Realm:     "testrealm@host.com",
+				Nonce:     "dcd98b7102dd2f0e8b11d0f600bfb0c093",
+				Opaque:    "5ccc069c403ebaf9f0171e9517f40e41",
+				Algorithm: "MD5",
+				QOP:       []string{"auth"},
+			},
+			credentials:      config.DigestCredentials{Username: "Mufasa", Password: "Circle Of Life"},
+			cnonce:           "0a4f113b",
+			expectedResponse: "6629fae49393a05397450978507c4ef1",
+		},
+		{
+			name: "RFC 7616 SHA-256 example",
+			challenge: digestChallenge{
+				Realm:     "http-auth@example.org",
+				Nonce:     "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+				Opaque:    "FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS",
+				Algorithm: "SHA-256",
+				QOP:       []string{"auth"},
+			},
+			credentials:      config.DigestCredentials{Username: "Mufasa", Password: "Circle of Life"},
+			cnonce:           "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ",
+			expectedResponse: "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := digestAuthorization(tt.challenge, tt.credentials, http.MethodGet, "/dir/index.html", tt.cnonce)
+
+			require.True(t, strings.HasPrefix(header, "Digest "))
+			params := parseAuthParams(strings.TrimPrefix(header, "Digest "))
+			require.Equal(t, tt.expectedResponse, params["response"])
+			require.Equal(t, "auth", params["qop"])
+			require.Equal(t, "00000001", params["nc"])
+			require.Equal(t, tt.challenge.Opaque, params["opaque"])
+			require.Equal(t, tt.credentials.Username, params["username"])
+		})
+	}
+}
+
+func digestProtectedHandler(t *testing.T, algorithm string, newHash func() hash.Hash, username, password string) http.Handler {
+	const realm = "appliance"
+	const nonce = "abc123nonce"
+
+	h := func(parts ...string) string {
+		hasher := newHash()
+		hasher.Write([]byte(strings.Join(parts, ":")))
+		return hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, "Digest ") {
+			w.Header().Add("WWW-Authenticate", `Basic realm="fallback"`)
+			w.Header().Add("WWW-Authenticate",
+				fmt.Sprintf(`Digest realm="%s", nonce="%s", algorithm=%s, qop="auth"`, realm, nonce, algorithm))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		params := parseAuthParams(strings.TrimPrefix(authorization, "Digest "))
+		require.Equal(t, r.URL.RequestURI(), params["uri"])
+
+		ha1 := h(username, realm, password)
+		ha2 := h(r.Method, params["uri"])
+		expected := h(ha1, nonce, params["nc"], params["cnonce"], params["qop"], ha2)
+		if params["response"] != expected {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, `{"authenticated": true}`)
+	})
+}
+
+func TestExecute_DigestAuth(t *testing.T) {
+	tests := []struct {
+		name           string
+		algorithm      string
+		newHash        func() hash.Hash
+		setting        config.Setting
+		expectedBody   string
+		expectedStatus int
+	}{
+		{
+			name:      "MD5 credentials are accepted",
+			algorithm: "MD5",
+			newHash:   md5.New,
+			setting: config.Setting{
+				Digest: &config.DigestCredentials{Username: "admin", Password: "secret"},
+			},
+			expectedBody: `{"authenticated": true}`,
+		},
+		{
+			name:      "SHA-256 credentials are accepted",
+			algorithm: "SHA-256",
+			newHash:   sha256.New,
+			setting: config.Setting{
+				Digest: &config.DigestCredentials{Username: "admin", Password: "secret"},
+			},
+			expectedBody: `{"authenticated": true}`,
+		},
+		{
+			name:      "wrong password keeps the 401 body",
+			algorithm: "MD5",
+			newHash:   md5.New,
+			setting: config.Setting{
+				Digest: &config.DigestCredentials{Username: "admin", Password: "wrong"},
+			},
+			expectedBody: "",
+		},
+		{
+			name:         "no digest credentials does not retry",
+			algorithm:    "MD5",
+			newHash:      md5.New,
+			setting:      config.Setting{},
+			expectedBody: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(digestProtectedHandler(t, tt.algorithm, tt.newHash, "admin", "secret"))
+			defer server.Close()
+
+			tt.setting.URL = server.URL
+			req, err := Request{Path: "status", Method: "get", Arguments: map[string]string{"id": "1"}}.ToHTTPRequest(tt.setting)
+			require.NoError(t, err)
+
+			resp, err := Execute(req, tt.setting)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedBody, string(resp.Body))
+		})
+	}
+}
+
+func TestExecute_DigestUnsupportedChallenge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Digest realm="r", nonce="n", qop="auth-int"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = Execute(req, config.Setting{Digest: &config.DigestCredentials{Username: "u", Password: "p"}})
+	require.ErrorIs(t, err, errUnsupportedDigest)
+}
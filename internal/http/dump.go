@@ -0,0 +1,97 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+var sensitiveHeaderNames = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+var sensitiveHeaderWords = []string{"token", "secret", "password", "api-key", "apikey", "session"}
+
+func IsSensitiveHeader(name string) bool {
+	if slices.Contains(sensitiveHeaderNames, http.CanonicalHeaderKey(name)) {
+		return true
+	}
+
+	lower := strings.ToLower(name)
+	for _, word := range sensitiveHeaderWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactHeader hides the value of sensitive headers. The authorization
+// scheme, e.g. "Bearer", is kept since it helps debugging without leaking
+// the credentials.
+func RedactHeader(name, value string) string {
+	if !IsSensitiveHeader(name) {
+		return value
+	}
+
+	if scheme, _, found := strings.Cut(value, " "); found && strings.HasSuffix(http.CanonicalHeaderKey(name), "Authorization") {
+		return scheme + " " + redacted
+	}
+
+	return redacted
+}
+
+// RequestBody returns the body of req without consuming it.
+func RequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request body cannot be read without being consumed")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
+
+// DumpRequest renders req as it is about to be sent: the method and final
+// URL, the headers sorted by name and the body.
+func DumpRequest(req *http.Request, showSecrets bool) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", req.Method, req.URL)
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			if !showSecrets {
+				value = RedactHeader(name, value)
+			}
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+
+	body, err := RequestBody(req)
+	if err != nil {
+		return "", err
+	}
+
+	if len(body) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		expected string
+	}{
+		{name: "authorization keeps the scheme", header: "Authorization", value: "Bearer abc.def", expected: "Bearer [redacted]"},
+		{name: "authorization without scheme", header: "authorization", value: "123", expected: "[redacted]"},
+		{name: "proxy authorization", header: "Proxy-Authorization", value: "Basic Zm9vOmJhcg==", expected: "Basic [redacted]"},
+		{name: "cookie", header: "Cookie", value: "session=abc; theme=dark", expected: "[redacted]"},
+		{name: "api key header", header: "X-Api-Key", value: "k-123", expected: "[redacted]"},
+		{name: "token header", header: "X-Auth-Token", value: "t-123", expected: "[redacted]"},
+		{name: "regular header", header: "Content-Type", value: "application/json", expected: "application/json"},
+		{name: "regular custom header", header: "X-Request-Id", value: "42", expected: "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, RedactHeader(tt.header, tt.value))
+		})
+	}
+}
+
+func TestDumpRequest(t *testing.T) {
+	t.Run("request built from an alias", func(t *testing.T) {
+		req, err := Request{
+			Path:      "users/456",
+			Method:    "get",
+			Arguments: map[string]string{"include": "posts"},
+			Headers:   map[string]string{"X-Trace": "on"},
+		}.ToHTTPRequest(config.Setting{
+			URL:     "https://api.example.com/v1",
+			Headers: map[string]string{"Authorization": "Bearer secret-token"},
+		})
+		require.NoError(t, err)
+
+		dump, err := DumpRequest(req, false)
+		require.NoError(t, err)
+		require.Equal(t, ""+
+			"GET https://api.example.com/v1/users/456?include=posts\n"+
+			"Authorization: Bearer [redacted]\n"+
+			"Content-Type: application/json\n"+
+			"X-Trace: on\n", dump)
+
+		dump, err = DumpRequest(req, true)
+		require.NoError(t, err)
+		require.Contains(t, dump, "Authorization: Bearer secret-token\n")
+	})
+
+	t.Run("body is printed and left unconsumed", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "https://api.example.com/orders", strings.NewReader(`{"id": 1}`))
+		require.NoError(t, err)
+
+		dump, err := DumpRequest(req, false)
+		require.NoError(t, err)
+		require.Equal(t, "POST https://api.example.com/orders\n\n{\"id\": 1}\n", dump)
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"id": 1}`, string(body))
+	})
+
+	t.Run("body that cannot be replayed", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "https://api.example.com/orders", io.NopCloser(strings.NewReader("x")))
+		require.NoError(t, err)
+
+		_, err = DumpRequest(req, false)
+		require.Error(t, err)
+	})
+}
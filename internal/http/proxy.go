@@ -0,0 +1,171 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ashttp/internal/config"
+)
+
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// newProxyFunc resolves the proxy of each request. The alias proxy wins over
+// the HTTP_PROXY and HTTPS_PROXY environment variables, and hosts matching
+// the alias noProxy list, or NO_PROXY, are always reached directly. An alias
+// proxy without a url keeps the environment proxy, with its own noProxy
+// list and credentials.
+func newProxyFunc(setting *config.ProxySetting) (func(*http.Request) (*url.URL, error), error) {
+	if setting == nil {
+		return proxyFromEnvironment, nil
+	}
+
+	noProxy := setting.NoProxy
+	if noProxy == "" {
+		noProxy = getenv("NO_PROXY")
+	}
+
+	var credentials *url.Userinfo
+	if setting.Username != "" {
+		credentials = url.UserPassword(setting.Username, os.ExpandEnv(setting.Password))
+	}
+
+	if strings.TrimSpace(setting.URL) == "" {
+		return func(req *http.Request) (*url.URL, error) {
+			if isLoopback(req.URL) || matchesNoProxy(noProxy, req.URL) {
+				return nil, nil
+			}
+
+			proxyURL, err := environmentProxy(req)
+			if proxyURL != nil && credentials != nil {
+				proxyURL.User = credentials
+			}
+			return proxyURL, err
+		}, nil
+	}
+
+	proxyURL, err := parseProxyURL(setting.URL)
+	if err != nil {
+		return nil, err
+	}
+	if credentials != nil {
+		proxyURL.User = credentials
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if matchesNoProxy(noProxy, req.URL) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+func proxyFromEnvironment(req *http.Request) (*url.URL, error) {
+	if isLoopback(req.URL) || matchesNoProxy(getenv("NO_PROXY"), req.URL) {
+		return nil, nil
+	}
+	return environmentProxy(req)
+}
+
+// environmentProxy is the proxy HTTP_PROXY or HTTPS_PROXY sets for the
+// scheme of req, if any.
+func environmentProxy(req *http.Request) (*url.URL, error) {
+	proxy := getenv("HTTP_PROXY")
+	if req.URL.Scheme == "https" {
+		proxy = getenv("HTTPS_PROXY")
+	}
+
+	if proxy == "" {
+		return nil, nil
+	}
+
+	return parseProxyURL(proxy)
+}
+
+func isLoopback(target *url.URL) bool {
+	if target.Hostname() == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(target.Hostname())
+	return ip != nil && ip.IsLoopback()
+}
+
+func parseProxyURL(raw string) (*url.URL, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+
+	for _, scheme := range proxySchemes {
+		if proxyURL.Scheme == scheme {
+			return proxyURL, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported proxy scheme %q, expected one of %s", proxyURL.Scheme, strings.Join(proxySchemes, ", "))
+}
+
+// matchesNoProxy follows the usual NO_PROXY conventions: "*" matches every
+// host, IPs and CIDR ranges match addresses, and a domain matches itself and
+// its subdomains. An entry may be restricted to a port with host:port.
+func matchesNoProxy(noProxy string, target *url.URL) bool {
+	host := strings.ToLower(target.Hostname())
+	port := target.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[target.Scheme]
+	}
+	ip := net.ParseIP(host)
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		domain := strings.TrimPrefix(strings.TrimPrefix(entryHost, "*"), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getenv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return os.Getenv(strings.ToLower(key))
+}
@@ -0,0 +1,376 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+// newForwardProxy stands in for a corporate HTTP proxy: it answers the
+// absolute-form requests itself instead of reaching the target.
+func newForwardProxy(t *testing.T, username, password string) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+
+		if username != "" {
+			expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+			if r.Header.Get("Proxy-Authorization") != expected {
+				w.WriteHeader(http.StatusProxyAuthRequired)
+				return
+			}
+		}
+
+		fmt.Fprintf(w, "proxied %s", r.URL)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+// newSOCKS5Proxy is a minimal RFC 1928 server with RFC 1929 username and
+// password authentication that only supports CONNECT.
+func newSOCKS5Proxy(t *testing.T, username, password string) (string, *atomic.Int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	var hits atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			hits.Add(1)
+			go serveSOCKS5(conn, username, password)
+		}
+	}()
+
+	return listener.Addr().String(), &hits
+}
+
+func serveSOCKS5(conn net.Conn, username, password string) {
+	defer conn.Close()
+
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(conn, greeting); err != nil {
+		return
+	}
+	methods := make([]byte, greeting[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 2})
+
+	authHeader := make([]byte, 2)
+	if _, err := io.ReadFull(conn, authHeader); err != nil {
+		return
+	}
+	user := make([]byte, authHeader[1])
+	io.ReadFull(conn, user)
+	passLength := make([]byte, 1)
+	io.ReadFull(conn, passLength)
+	pass := make([]byte, passLength[0])
+	io.ReadFull(conn, pass)
+
+	if string(user) != username || string(pass) != password {
+		conn.Write([]byte{1, 1})
+		return
+	}
+	conn.Write([]byte{1, 0})
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 3:
+		length := make([]byte, 1)
+		io.ReadFull(conn, length)
+		name := make([]byte, length[0])
+		io.ReadFull(conn, name)
+		host = string(name)
+	default:
+		return
+	}
+	portBytes := make([]byte, 2)
+	io.ReadFull(conn, portBytes)
+	port := strconv.Itoa(int(binary.BigEndian.Uint16(portBytes)))
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+func TestExecute_Proxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "direct")
+	}))
+	defer target.Close()
+
+	t.Run("HTTP proxy with credentials", func(t *testing.T) {
+		proxy, hits := newForwardProxy(t, "corp", "s3cret")
+
+		req, err := http.NewRequest(http.MethodGet, "http://api.external.test/users?page=2", nil)
+		require.NoError(t, err)
+
+		resp, err := Execute(req, config.Setting{Proxy: &config.ProxySetting{
+			URL:      proxy.URL,
+			Username: "corp",
+			Password: "s3cret",
+		}})
+		require.NoError(t, err)
+		require.Equal(t, "proxied http://api.external.test/users?page=2", string(resp.Body))
+		require.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("HTTP proxy password from the environment", func(t *testing.T) {
+		proxy, _ := newForwardProxy(t, "corp", "from-env")
+		t.Setenv("ASHTTP_TEST_PROXY_PASSWORD", "from-env")
+
+		req, err := http.NewRequest(http.MethodGet, "http://api.external.test/", nil)
+		require.NoError(t, err)
+
+		resp, err := Execute(req, config.Setting{Proxy: &config.ProxySetting{
+			URL:      proxy.URL,
+			Username: "corp",
+			Password: "$ASHTTP_TEST_PROXY_PASSWORD",
+		}})
+		require.NoError(t, err)
+		require.Equal(t, "proxied http://api.external.test/", string(resp.Body))
+	})
+
+	t.Run("SOCKS5 proxy with credentials", func(t *testing.T) {
+		proxyAddr, hits := newSOCKS5Proxy(t, "corp", "s3cret")
+
+		req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := Execute(req, config.Setting{Proxy: &config.ProxySetting{
+			URL:      "socks5://" + proxyAddr,
+			Username: "corp",
+			Password: "s3cret",
+		}})
+		require.NoError(t, err)
+		require.Equal(t, "direct", string(resp.Body))
+		require.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("SOCKS5 proxy rejects wrong credentials", func(t *testing.T) {
+		proxyAddr, _ := newSOCKS5Proxy(t, "corp", "s3cret")
+
+		req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+		require.NoError(t, err)
+
+		_, err = Execute(req, config.Setting{Proxy: &config.ProxySetting{
+			URL:      "socks5://" + proxyAddr,
+			Username: "corp",
+			Password: "wrong",
+		}})
+		require.Error(t, err)
+	})
+
+	t.Run("noProxy hosts bypass the alias proxy", func(t *testing.T) {
+		proxy, hits := newForwardProxy(t, "", "")
+
+		req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := Execute(req, config.Setting{Proxy: &config.ProxySetting{
+			URL:     proxy.URL,
+			NoProxy: "internal.example.com, 127.0.0.0/8",
+		}})
+		require.NoError(t, err)
+		require.Equal(t, "direct", string(resp.Body))
+		require.Equal(t, int32(0), hits.Load())
+	})
+
+	t.Run("environment proxy is the fallback", func(t *testing.T) {
+		proxy, hits := newForwardProxy(t, "", "")
+		t.Setenv("HTTP_PROXY", proxy.URL)
+		t.Setenv("NO_PROXY", "")
+
+		req, err := http.NewRequest(http.MethodGet, "http://api.external.test/", nil)
+		require.NoError(t, err)
+
+		resp, err := Execute(req, config.Setting{})
+		require.NoError(t, err)
+		require.Equal(t, "proxied http://api.external.test/", string(resp.Body))
+		require.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("alias proxy without url keeps the environment proxy", func(t *testing.T) {
+		proxy, hits := newForwardProxy(t, "corp", "s3cret")
+		t.Setenv("HTTP_PROXY", proxy.URL)
+		t.Setenv("NO_PROXY", "")
+
+		setting := config.Setting{Proxy: &config.ProxySetting{
+			Username: "corp",
+			Password: "s3cret",
+			NoProxy:  "internal.test",
+		}}
+
+		req, err := http.NewRequest(http.MethodGet, "http://api.external.test/", nil)
+		require.NoError(t, err)
+		resp, err := Execute(req, setting)
+		require.NoError(t, err)
+		require.Equal(t, "proxied http://api.external.test/", string(resp.Body))
+		require.Equal(t, int32(1), hits.Load())
+
+		proxyFunc, err := newProxyFunc(setting.Proxy)
+		require.NoError(t, err)
+		for _, target := range []string{"http://api.internal.test/", target.URL} {
+			req, err := http.NewRequest(http.MethodGet, target, nil)
+			require.NoError(t, err)
+			proxyURL, err := proxyFunc(req)
+			require.NoError(t, err)
+			require.Nil(t, proxyURL, target)
+		}
+	})
+
+	t.Run("alias proxy without url nor environment proxy", func(t *testing.T) {
+		t.Setenv("HTTP_PROXY", "")
+		t.Setenv("http_proxy", "")
+
+		req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := Execute(req, config.Setting{Proxy: &config.ProxySetting{NoProxy: "internal.test"}})
+		require.NoError(t, err)
+		require.Equal(t, "direct", string(resp.Body))
+	})
+
+	t.Run("unsupported proxy scheme", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+		require.NoError(t, err)
+
+		_, err = Execute(req, config.Setting{Proxy: &config.ProxySetting{URL: "ftp://proxy.local"}})
+		require.ErrorContains(t, err, `unsupported proxy scheme "ftp"`)
+	})
+}
+
+func TestProxyFromEnvironment(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		target        string
+		expectedProxy string
+	}{
+		{
+			name:          "http target uses HTTP_PROXY",
+			env:           map[string]string{"HTTP_PROXY": "http://proxy:3128", "HTTPS_PROXY": "http://secure-proxy:3128"},
+			target:        "http://api.example.com",
+			expectedProxy: "http://proxy:3128",
+		},
+		{
+			name:          "https target uses HTTPS_PROXY",
+			env:           map[string]string{"HTTP_PROXY": "http://proxy:3128", "HTTPS_PROXY": "http://secure-proxy:3128"},
+			target:        "https://api.example.com",
+			expectedProxy: "http://secure-proxy:3128",
+		},
+		{
+			name:          "lowercase variables are honored",
+			env:           map[string]string{"https_proxy": "socks5://proxy:1080"},
+			target:        "https://api.example.com",
+			expectedProxy: "socks5://proxy:1080",
+		},
+		{
+			name:          "scheme defaults to http",
+			env:           map[string]string{"HTTPS_PROXY": "proxy:3128"},
+			target:        "https://api.example.com",
+			expectedProxy: "http://proxy:3128",
+		},
+		{
+			name:   "NO_PROXY bypasses the proxy",
+			env:    map[string]string{"HTTPS_PROXY": "http://proxy:3128", "NO_PROXY": ".example.com"},
+			target: "https://api.example.com",
+		},
+		{
+			name:   "loopback is never proxied",
+			env:    map[string]string{"HTTP_PROXY": "http://proxy:3128"},
+			target: "http://localhost:8080",
+		},
+		{
+			name:   "no variables means no proxy",
+			env:    map[string]string{},
+			target: "https://api.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			req, err := http.NewRequest(http.MethodGet, tt.target, nil)
+			require.NoError(t, err)
+
+			proxyURL, err := proxyFromEnvironment(req)
+			require.NoError(t, err)
+
+			if tt.expectedProxy == "" {
+				require.Nil(t, proxyURL)
+				return
+			}
+			require.Equal(t, tt.expectedProxy, proxyURL.String())
+		})
+	}
+}
+
+func TestMatchesNoProxy(t *testing.T) {
+	tests := []struct {
+		name     string
+		noProxy  string
+		target   string
+		expected bool
+	}{
+		{name: "empty list", noProxy: "", target: "https://api.example.com", expected: false},
+		{name: "wildcard", noProxy: "*", target: "https://api.example.com", expected: true},
+		{name: "exact domain", noProxy: "api.example.com", target: "https://api.example.com", expected: true},
+		{name: "domain matches subdomains", noProxy: "example.com", target: "https://api.example.com", expected: true},
+		{name: "leading dot matches subdomains", noProxy: ".example.com", target: "https://api.example.com", expected: true},
+		{name: "wildcard domain", noProxy: "*.example.com", target: "https://api.example.com", expected: true},
+		{name: "suffix is not a subdomain", noProxy: "example.com", target: "https://badexample.com", expected: false},
+		{name: "case insensitive", noProxy: "API.Example.COM", target: "https://api.example.com", expected: true},
+		{name: "IP address", noProxy: "10.0.0.12", target: "http://10.0.0.12:8080", expected: true},
+		{name: "CIDR range", noProxy: "10.0.0.0/8", target: "http://10.20.30.40", expected: true},
+		{name: "CIDR range does not match hostnames", noProxy: "10.0.0.0/8", target: "http://internal", expected: false},
+		{name: "port restricted entry matches", noProxy: "example.com:8443", target: "https://example.com:8443", expected: true},
+		{name: "port restricted entry with other port", noProxy: "example.com:8443", target: "https://example.com", expected: false},
+		{name: "default port is considered", noProxy: "example.com:443", target: "https://example.com", expected: true},
+		{name: "several entries with spaces", noProxy: "localhost, .internal ,10.0.0.0/8", target: "http://db.internal", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := url.Parse(tt.target)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, matchesNoProxy(tt.noProxy, target))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/ashttp/internal/config"
+)
+
+const defaultMaxRedirects = 10
+
+// sensitiveHeaders are dropped when a redirect leaves the original host,
+// unless forwarding them is explicitly allowed, and when it goes from https
+// to plain http.
+var sensitiveHeaders = []string{"Authorization", "Www-Authenticate", "Cookie", "Cookie2"}
+
+type redirectPolicy struct {
+	follow      bool
+	max         int
+	keepMethod  bool
+	forwardAuth bool
+}
+
+func newRedirectPolicy(setting *config.RedirectSetting) redirectPolicy {
+	policy := redirectPolicy{follow: true, max: defaultMaxRedirects}
+	if setting == nil {
+		return policy
+	}
+
+	if setting.Follow != nil {
+		policy.follow = *setting.Follow
+	}
+	if setting.Max > 0 {
+		policy.max = setting.Max
+	}
+	policy.keepMethod = setting.KeepMethod
+	policy.forwardAuth = setting.ForwardAuth
+
+	return policy
+}
+
+func doFollowingRedirects(client *http.Client, req *http.Request, setting config.Setting) (*http.Response, error) {
+	policy := newRedirectPolicy(setting.Redirect)
+	current := req
+
+	for hops := 0; ; hops++ {
+		hopSetting := setting
+		if policy.stripsAuth(req.URL, current.URL) {
+			hopSetting.Digest = nil
+		}
+
+		resp, err := do(client, current, hopSetting)
+		if err != nil || !policy.follow {
+			return resp, err
+		}
+
+		next, err := policy.nextRequest(req, current, resp)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if next == nil {
+			return resp, nil
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if hops >= policy.max {
+			return nil, fmt.Errorf("stopped after %d redirects", policy.max)
+		}
+
+		logf("redirect %d: %s %s -> %s %s", hops+1, current.URL, resp.Status, next.Method, next.URL)
+		current = next
+	}
+}
+
+// nextRequest builds the request following a redirect response, or returns
+// nil when resp is not a redirect. Headers always come from the original
+// request so nothing computed for a single hop, such as a Digest answer,
+// leaks into the next one.
+func (p redirectPolicy) nextRequest(origin, current *http.Request, resp *http.Response) (*http.Request, error) {
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return nil, nil
+	}
+
+	method := current.Method
+	keepBody := true
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound:
+		if !p.keepMethod && method != http.MethodGet && method != http.MethodHead {
+			method = http.MethodGet
+			keepBody = false
+		}
+	case http.StatusSeeOther:
+		if method != http.MethodHead {
+			method = http.MethodGet
+		}
+		keepBody = false
+	case http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil, nil
+	}
+
+	target, err := current.URL.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect location %q: %w", location, err)
+	}
+
+	next, err := http.NewRequestWithContext(current.Context(), method, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	next.Header = origin.Header.Clone()
+	if keepBody && origin.GetBody != nil {
+		body, err := origin.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+		next.GetBody = origin.GetBody
+		next.ContentLength = origin.ContentLength
+	}
+
+	if p.stripsAuth(origin.URL, target) {
+		for _, header := range sensitiveHeaders {
+			next.Header.Del(header)
+		}
+	}
+
+	return next, nil
+}
+
+// stripsAuth reports whether the credentials sent to origin must be kept
+// from target: on another host unless forwarding them is allowed, and always
+// over plain http after https, where they would travel in clear text, as
+// curl does.
+func (p redirectPolicy) stripsAuth(origin, target *url.URL) bool {
+	if origin.Scheme == "https" && target.Scheme != "https" {
+		return true
+	}
+	return !p.forwardAuth && target.Hostname() != origin.Hostname()
+}
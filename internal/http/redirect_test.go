@@ -0,0 +1,262 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+// newRedirectServer serves /hop/N redirecting to /hop/N-1 with the given
+// status, and echoes the final request at /hop/0.
+func newRedirectServer(t *testing.T, status int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var hop int
+		fmt.Sscanf(r.URL.Path, "/hop/%d", &hop)
+		if hop > 0 {
+			w.Header().Set("Location", fmt.Sprintf("/hop/%d", hop-1))
+			w.WriteHeader(status)
+			fmt.Fprint(w, "moved")
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s", r.Method, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExecute_Redirects(t *testing.T) {
+	follow := false
+
+	tests := []struct {
+		name         string
+		status       int
+		method       string
+		body         string
+		hops         int
+		redirect     *config.RedirectSetting
+		expectedBody string
+		expectError  string
+	}{
+		{
+			name:         "follows redirects by default",
+			status:       http.StatusFound,
+			method:       http.MethodGet,
+			hops:         3,
+			expectedBody: "GET ",
+		},
+		{
+			name:         "does not follow when disabled",
+			status:       http.StatusFound,
+			method:       http.MethodGet,
+			hops:         3,
+			redirect:     &config.RedirectSetting{Follow: &follow},
+			expectedBody: "moved",
+		},
+		{
+			name:        "stops after the maximum count",
+			status:      http.StatusMovedPermanently,
+			method:      http.MethodGet,
+			hops:        3,
+			redirect:    &config.RedirectSetting{Max: 2},
+			expectError: "stopped after 2 redirects",
+		},
+		{
+			name:         "reaches the end with exactly the maximum count",
+			status:       http.StatusMovedPermanently,
+			method:       http.MethodGet,
+			hops:         2,
+			redirect:     &config.RedirectSetting{Max: 2},
+			expectedBody: "GET ",
+		},
+		{
+			name:         "302 turns POST into GET by default",
+			status:       http.StatusFound,
+			method:       http.MethodPost,
+			body:         "payload",
+			hops:         1,
+			expectedBody: "GET ",
+		},
+		{
+			name:         "302 keeps POST and its body when asked to",
+			status:       http.StatusFound,
+			method:       http.MethodPost,
+			body:         "payload",
+			hops:         1,
+			redirect:     &config.RedirectSetting{KeepMethod: true},
+			expectedBody: "POST payload",
+		},
+		{
+			name:         "301 keeps DELETE when asked to",
+			status:       http.StatusMovedPermanently,
+			method:       http.MethodDelete,
+			hops:         1,
+			redirect:     &config.RedirectSetting{KeepMethod: true},
+			expectedBody: "DELETE ",
+		},
+		{
+			name:         "303 always turns into GET",
+			status:       http.StatusSeeOther,
+			method:       http.MethodPost,
+			body:         "payload",
+			hops:         1,
+			redirect:     &config.RedirectSetting{KeepMethod: true},
+			expectedBody: "GET ",
+		},
+		{
+			name:         "307 keeps the method and body",
+			status:       http.StatusTemporaryRedirect,
+			method:       http.MethodPost,
+			body:         "payload",
+			hops:         2,
+			expectedBody: "POST payload",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newRedirectServer(t, tt.status)
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, err := http.NewRequest(tt.method, fmt.Sprintf("%s/hop/%d", server.URL, tt.hops), body)
+			require.NoError(t, err)
+
+			resp, err := Execute(req, config.Setting{Redirect: tt.redirect})
+			if tt.expectError != "" {
+				require.ErrorContains(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedBody, string(resp.Body))
+		})
+	}
+}
+
+func TestExecute_RedirectChainIsLogged(t *testing.T) {
+	server := newRedirectServer(t, http.StatusFound)
+
+	var verbose bytes.Buffer
+	originalVerbose := Verbose
+	Verbose = &verbose
+	defer func() {
+		Verbose = originalVerbose
+	}()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/hop/2", nil)
+	require.NoError(t, err)
+
+	_, err = Execute(req, config.Setting{})
+	require.NoError(t, err)
+
+	log := verbose.String()
+	require.Contains(t, log, fmt.Sprintf("[verbose] redirect 1: %s/hop/2 302 Found -> GET %s/hop/1", server.URL, server.URL))
+	require.Contains(t, log, fmt.Sprintf("[verbose] redirect 2: %s/hop/1 302 Found -> GET %s/hop/0", server.URL, server.URL))
+}
+
+func TestExecute_RedirectAuthHeaders(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "authorization=%q custom=%q", r.Header.Get("Authorization"), r.Header.Get("X-Custom"))
+	}))
+	defer target.Close()
+
+	// The target is reached through "localhost" while the origin uses
+	// 127.0.0.1, so the redirect crosses hosts.
+	crossHostTarget := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		location := target.URL
+		if r.URL.Path == "/cross" {
+			location = crossHostTarget
+		}
+		http.Redirect(w, r, location, http.StatusFound)
+	}))
+	defer origin.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		redirect     *config.RedirectSetting
+		expectedBody string
+	}{
+		{
+			name:         "same host keeps the authorization header",
+			path:         "/same",
+			expectedBody: `authorization="Bearer token" custom="value"`,
+		},
+		{
+			name:         "cross host strips the authorization header",
+			path:         "/cross",
+			expectedBody: `authorization="" custom="value"`,
+		},
+		{
+			name:         "cross host forwards the authorization header when allowed",
+			path:         "/cross",
+			redirect:     &config.RedirectSetting{ForwardAuth: true},
+			expectedBody: `authorization="Bearer token" custom="value"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, origin.URL+tt.path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("X-Custom", "value")
+
+			resp, err := Execute(req, config.Setting{Redirect: tt.redirect})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedBody, string(resp.Body))
+		})
+	}
+}
+
+func TestRedirectPolicy_NextRequestAuthHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		origin      string
+		location    string
+		forwardAuth bool
+		expected    string
+	}{
+		{name: "same host and scheme", origin: "https://api.example.com/a", location: "/b", expected: "Bearer token"},
+		{name: "upgrade to https", origin: "http://api.example.com/a", location: "https://api.example.com/b", expected: "Bearer token"},
+		{name: "downgrade to http", origin: "https://api.example.com/a", location: "http://api.example.com/b"},
+		{name: "downgrade to http even when forwarding", origin: "https://api.example.com/a", location: "http://api.example.com/b", forwardAuth: true},
+		{name: "other host", origin: "https://api.example.com/a", location: "https://cdn.example.com/b"},
+		{name: "other host when forwarding", origin: "https://api.example.com/a", location: "https://cdn.example.com/b", forwardAuth: true, expected: "Bearer token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.origin, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			resp := &http.Response{StatusCode: http.StatusFound, Header: http.Header{"Location": {tt.location}}}
+
+			next, err := redirectPolicy{follow: true, max: 1, forwardAuth: tt.forwardAuth}.nextRequest(req, req, resp)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, next.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestNewRedirectPolicy(t *testing.T) {
+	follow := false
+
+	require.Equal(t, redirectPolicy{follow: true, max: defaultMaxRedirects}, newRedirectPolicy(nil))
+	require.Equal(t,
+		redirectPolicy{follow: false, max: 3, keepMethod: true, forwardAuth: true},
+		newRedirectPolicy(&config.RedirectSetting{Follow: &follow, Max: 3, KeepMethod: true, ForwardAuth: true}))
+}
@@ -1,10 +1,13 @@
 package http
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
+	"time"
 
 	"github.com/ashttp/internal/config"
 )
@@ -14,6 +17,7 @@ type Request struct {
 	Method    string
 	Headers   map[string]string
 	Arguments map[string]string
+	Body      []byte
 }
 
 func (r Request) ToHTTPRequest(setting config.Setting) (*http.Request, error) {
@@ -37,31 +41,201 @@ func (r Request) ToHTTPRequest(setting config.Setting) (*http.Request, error) {
 func (r Request) buildHTTPRequest(setting config.Setting) (*http.Request, error) {
 	httpMethod := strings.ToUpper(r.Method)
 	switch httpMethod {
-	case http.MethodGet, http.MethodDelete:
+	case http.MethodGet, http.MethodDelete, http.MethodPost, http.MethodPut, http.MethodPatch:
 		queryString := QueryString(r.Arguments).ToURL()
 		url := fmt.Sprintf("%s/%s", setting.URL, r.Path)
 		if queryString != "" {
 			url = fmt.Sprintf("%s?%s", url, queryString)
 		}
 
-		return http.NewRequest(httpMethod, url, nil)
+		var body io.Reader
+		if len(r.Body) > 0 {
+			body = bytes.NewReader(r.Body)
+		}
+
+		return http.NewRequest(httpMethod, url, body)
 	default:
 		return nil, fmt.Errorf("method not suported")
 	}
 }
 
-func Execute(req *http.Request) ([]byte, error) {
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// Verbose receives a trace of each exchange, such as the retried attempts.
+// It discards everything unless the CLI runs in verbose mode.
+var Verbose io.Writer = io.Discard
+
+func logf(format string, v ...any) {
+	fmt.Fprintf(Verbose, "[verbose] %s\n", fmt.Sprintf(format, v...))
+}
+
+type Response struct {
+	Proto      string
+	Status     string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Timing     Timing
+}
+
+func Execute(req *http.Request, setting config.Setting) (*Response, error) {
+	stream, err := Open(req, setting)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return stream.ReadAll()
+}
+
+// Stream is a response whose body is left to the caller, for bodies too
+// large to be held in memory or that never end.
+type Stream struct {
+	Proto         string
+	Status        string
+	StatusCode    int
+	Header        http.Header
+	ContentLength int64
+	// Body must be closed, the request deadline runs until then.
+	Body io.ReadCloser
+
+	recorder *timingRecorder
+}
+
+// Timing returns the timing breakdown up to now, call it once the body has
+// been read.
+func (s *Stream) Timing() Timing {
+	return s.recorder.timing(now())
+}
+
+// ReadAll reads and closes the body.
+func (s *Stream) ReadAll() (*Response, error) {
+	defer s.Body.Close()
+
+	body, err := io.ReadAll(s.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Proto:      s.Proto,
+		Status:     s.Status,
+		StatusCode: s.StatusCode,
+		Header:     s.Header,
+		Body:       body,
+		Timing:     s.Timing(),
+	}, nil
+}
+
+// Open sends req like Execute without reading the response body.
+func Open(req *http.Request, setting config.Setting) (*Stream, error) {
+	transport, err := newTransport(setting)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := newTimingRecorder()
+	ctx, cancel := withDeadline(httptrace.WithClientTrace(req.Context(), recorder.trace()), setting.Timeouts)
+
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := doWithRetries(client, req.WithContext(ctx), setting)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &Stream{
+		Proto:         resp.Proto,
+		Status:        resp.Status,
+		StatusCode:    resp.StatusCode,
+		Header:        resp.Header,
+		ContentLength: resp.ContentLength,
+		Body: &cancelOnClose{ReadCloser: resp.Body, ctx: ctx, cancel: func(error) {
+			cancel()
+		}},
+		recorder: recorder,
+	}, nil
+}
+
+func doWithRetries(client *http.Client, req *http.Request, setting config.Setting) (*http.Response, error) {
+	policy := newRetryPolicy(setting.Retry)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := doFollowingRedirects(client, req, setting)
+		if err != nil {
+			logf("attempt %d/%d: %s %s failed: %v", attempt, policy.maxAttempts, req.Method, req.URL, err)
+		} else {
+			logf("attempt %d/%d: %s %s %s", attempt, policy.maxAttempts, req.Method, req.URL, resp.Status)
+		}
+
+		if !policy.shouldRetry(attempt, req.Method, resp, err) {
+			return resp, err
+		}
+
+		delay, fromHeader := policy.delay(attempt, resp)
+		// A server asking for a longer wait than maxBackoff gets its answer
+		// returned rather than a process sleeping for hours.
+		if fromHeader && delay > policy.maxBackoff {
+			logf("not retrying, Retry-After asks to wait %s, more than the maximum backoff of %s", delay, policy.maxBackoff)
+			return resp, nil
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if fromHeader {
+			logf("retrying in %s as requested by Retry-After", delay)
+		} else {
+			logf("retrying in %s", delay.Round(time.Millisecond))
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
 
-	body, err := io.ReadAll(resp.Body)
+func do(client *http.Client, req *http.Request, setting config.Setting) (*http.Response, error) {
+	resp, err := send(client, req, setting)
 	if err != nil {
 		return nil, err
 	}
 
-	return body, nil
+	if resp.StatusCode != http.StatusUnauthorized || setting.Digest == nil {
+		return resp, nil
+	}
+
+	retry, found, err := withDigestAuth(req, resp, *setting.Digest)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("digest authentication: %w", err)
+	}
+
+	if !found {
+		return resp, nil
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return send(client, retry, setting)
+}
+
+func send(client *http.Client, req *http.Request, setting config.Setting) (*http.Response, error) {
+	ctx, cancel := withPhaseTimeouts(req.Context(), setting.Timeouts)
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel(nil)
+		return nil, timeoutCause(ctx, err)
+	}
+
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, ctx: ctx, cancel: cancel}
+	return resp, nil
 }
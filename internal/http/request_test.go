@@ -2,6 +2,7 @@ package http
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -19,6 +20,7 @@ func TestRequest_ToHTTPRequest(t *testing.T) {
 		possibleURLs    []string
 		expectedMethod  string
 		expectedHeaders map[string]string
+		expectedBody    string
 		expectError     bool
 	}{
 		{
@@ -85,11 +87,78 @@ func TestRequest_ToHTTPRequest(t *testing.T) {
 				"X-Custom-Header": "custom-value",
 			},
 		},
+		{
+			name: "POST request with a body",
+			request: Request{
+				Path:   "users",
+				Method: "post",
+				Body:   []byte(`{"name": "ana"}`),
+			},
+			setting: config.Setting{
+				URL: "https://api.example.com",
+			},
+			possibleURLs:   []string{"https://api.example.com/users"},
+			expectedMethod: http.MethodPost,
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+			expectedBody: `{"name": "ana"}`,
+		},
+		{
+			name: "PUT request with a body",
+			request: Request{
+				Path:   "users/1",
+				Method: "put",
+				Body:   []byte(`{"name": "bob"}`),
+			},
+			setting: config.Setting{
+				URL: "https://api.example.com",
+			},
+			possibleURLs:   []string{"https://api.example.com/users/1"},
+			expectedMethod: http.MethodPut,
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+			expectedBody: `{"name": "bob"}`,
+		},
+		{
+			name: "PATCH request with a body and its own content type",
+			request: Request{
+				Path:    "users/1",
+				Method:  "patch",
+				Headers: map[string]string{"Content-Type": "application/merge-patch+json"},
+				Body:    []byte(`{"name": null}`),
+			},
+			setting: config.Setting{
+				URL: "https://api.example.com",
+			},
+			possibleURLs:   []string{"https://api.example.com/users/1"},
+			expectedMethod: http.MethodPatch,
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/merge-patch+json",
+			},
+			expectedBody: `{"name": null}`,
+		},
+		{
+			name: "POST request without a body",
+			request: Request{
+				Path:   "jobs",
+				Method: "post",
+			},
+			setting: config.Setting{
+				URL: "https://api.example.com",
+			},
+			possibleURLs:   []string{"https://api.example.com/jobs"},
+			expectedMethod: http.MethodPost,
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+		},
 		{
 			name: "request with unsupported method",
 			request: Request{
 				Path:   "users",
-				Method: "post", // Not supported by buildHTTPRequest
+				Method: "trace", // Not supported by buildHTTPRequest
 			},
 			setting: config.Setting{
 				URL: "https://api.example.com",
@@ -152,7 +221,13 @@ func TestRequest_ToHTTPRequest(t *testing.T) {
 			require.Contains(t, tt.possibleURLs, req.URL.String(), "URL should match one of the possible valid URLs")
 			require.Equal(t, tt.expectedMethod, req.Method, "HTTP method should match expected value")
 
-			require.True(t, req.Body == nil || req.ContentLength == 0, "Request body should be empty")
+			if tt.expectedBody == "" {
+				require.True(t, req.Body == nil || req.ContentLength == 0, "Request body should be empty")
+			} else {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedBody, string(body), "Request body should match expected value")
+			}
 
 			for expectedKey, expectedValue := range tt.expectedHeaders {
 				actualValue := req.Header.Get(expectedKey)
@@ -240,7 +315,7 @@ func TestExecute(t *testing.T) {
 			req, err := http.NewRequest("GET", server.URL, nil)
 			require.NoError(t, err, "Should be able to create test request")
 
-			body, err := Execute(req)
+			resp, err := Execute(req, config.Setting{})
 
 			if tt.expectError {
 				require.Error(t, err, "Execute() should return an error")
@@ -248,7 +323,8 @@ func TestExecute(t *testing.T) {
 			}
 
 			require.NoError(t, err, "Execute() should not return an error")
-			require.Equal(t, tt.expectedBody, string(body), "Response body should match expected value")
+			require.Equal(t, tt.expectedBody, string(resp.Body), "Response body should match expected value")
+			require.Equal(t, tt.serverStatus, resp.StatusCode, "Response status should match expected value")
 		})
 	}
 }
@@ -257,7 +333,7 @@ func TestExecute_NetworkError(t *testing.T) {
 	req, err := http.NewRequest("GET", "http://invalid-url-that-does-not-exist.test", nil)
 	require.NoError(t, err, "Should be able to create test request")
 
-	_, err = Execute(req)
+	_, err = Execute(req, config.Setting{})
 	require.Error(t, err, "Execute() should return a network error")
 }
 
@@ -296,10 +372,10 @@ func TestExecute_Integration(t *testing.T) {
 		httpReq, err := ashttpRequest.ToHTTPRequest(cfg)
 		require.NoError(t, err, "ToHTTPRequest() should not fail")
 
-		responseBody, err := Execute(httpReq)
+		resp, err := Execute(httpReq, cfg)
 		require.NoError(t, err, "Execute() should not fail")
 
-		require.Equal(t, expectedResponse, string(responseBody), "Response body should match expected value")
+		require.Equal(t, expectedResponse, string(resp.Body), "Response body should match expected value")
 	})
 }
 
@@ -341,7 +417,30 @@ func BenchmarkExecute(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := Execute(req)
+		_, err := Execute(req, config.Setting{})
 		require.NoError(b, err, "Execute() should not fail")
 	}
 }
+
+func TestOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", "5")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, "hello")
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	stream, err := Open(req, config.Setting{URL: server.URL})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, stream.StatusCode)
+	require.Equal(t, int64(5), stream.ContentLength)
+
+	body, err := io.ReadAll(stream.Body)
+	require.NoError(t, err)
+	require.NoError(t, stream.Body.Close())
+	require.Equal(t, "hello", string(body))
+	require.Positive(t, stream.Timing().Total)
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/ashttp/internal/config"
+)
+
+const (
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 200 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+var defaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+var idempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodTrace,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+var now = time.Now
+
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	statuses       []int
+	allMethods     bool
+}
+
+func newRetryPolicy(setting *config.RetrySetting) retryPolicy {
+	if setting == nil {
+		return retryPolicy{maxAttempts: 1}
+	}
+
+	policy := retryPolicy{
+		maxAttempts:    setting.MaxAttempts,
+		initialBackoff: time.Duration(setting.InitialBackoff),
+		maxBackoff:     time.Duration(setting.MaxBackoff),
+		statuses:       setting.Statuses,
+		allMethods:     setting.AllMethods,
+	}
+
+	if policy.maxAttempts <= 0 {
+		policy.maxAttempts = defaultMaxAttempts
+	}
+	if policy.initialBackoff <= 0 {
+		policy.initialBackoff = defaultInitialBackoff
+	}
+	if policy.maxBackoff <= 0 {
+		policy.maxBackoff = defaultMaxBackoff
+	}
+	if len(policy.statuses) == 0 {
+		policy.statuses = defaultRetryStatuses
+	}
+
+	return policy
+}
+
+// shouldRetry tells whether the outcome of the given attempt is worth
+// another one.
+func (p retryPolicy) shouldRetry(attempt int, method string, resp *http.Response, err error) bool {
+	if attempt >= p.maxAttempts {
+		return false
+	}
+
+	if !p.allMethods && !slices.Contains(idempotentMethods, method) {
+		return false
+	}
+
+	if err != nil {
+		return isRetryableError(err)
+	}
+
+	return slices.Contains(p.statuses, resp.StatusCode)
+}
+
+// delay is the wait before the attempt following the given one. A valid
+// Retry-After header takes precedence over the exponential backoff.
+func (p retryPolicy) delay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return d, true
+		}
+	}
+
+	backoff := p.initialBackoff << (attempt - 1)
+	if backoff > p.maxBackoff || backoff <= 0 {
+		backoff = p.maxBackoff
+	}
+
+	// Equal jitter: half of the backoff is kept, the other half is random.
+	half := backoff / 2
+	return half + rand.N(half+1), false
+}
+
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now()), 0), true
+	}
+
+	return 0, false
+}
+
+func isRetryableError(err error) bool {
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return timeoutErr.Phase != PhaseTotal
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) || errors.Is(err, errUnsupportedDigest) {
+		return false
+	}
+
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &opErr) || errors.As(err, &dnsErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// rewind returns a copy of req whose body can be sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+
+	return clone, nil
+}
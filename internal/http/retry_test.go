@@ -0,0 +1,318 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func stubSleep(t *testing.T) *[]time.Duration {
+	delays := []time.Duration{}
+	originalSleep := sleep
+	sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	t.Cleanup(func() {
+		sleep = originalSleep
+	})
+	return &delays
+}
+
+func TestExecute_Retries(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		body             string
+		statuses         []int
+		headers          map[string]string
+		retry            *config.RetrySetting
+		expectedHits     int32
+		expectedBody     string
+		expectedDelays   []time.Duration
+		expectedRequests []string
+	}{
+		{
+			name:         "succeeds after retryable statuses",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			retry:        &config.RetrySetting{MaxAttempts: 3},
+			expectedHits: 3,
+			expectedBody: "attempt 3",
+		},
+		{
+			name:         "returns the last response once attempts are exhausted",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout},
+			retry:        &config.RetrySetting{MaxAttempts: 2},
+			expectedHits: 2,
+			expectedBody: "attempt 2",
+		},
+		{
+			name:         "non retryable status is returned right away",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusInternalServerError, http.StatusOK},
+			retry:        &config.RetrySetting{MaxAttempts: 3},
+			expectedHits: 1,
+			expectedBody: "attempt 1",
+		},
+		{
+			name:         "custom statuses replace the defaults",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusInternalServerError, http.StatusOK},
+			retry:        &config.RetrySetting{MaxAttempts: 3, Statuses: []int{http.StatusInternalServerError}},
+			expectedHits: 2,
+			expectedBody: "attempt 2",
+		},
+		{
+			name:         "no retry setting means a single attempt",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			retry:        nil,
+			expectedHits: 1,
+			expectedBody: "attempt 1",
+		},
+		{
+			name:           "Retry-After in seconds is honored",
+			method:         http.MethodGet,
+			statuses:       []int{http.StatusTooManyRequests, http.StatusOK},
+			headers:        map[string]string{"Retry-After": "7"},
+			retry:          &config.RetrySetting{MaxAttempts: 2},
+			expectedHits:   2,
+			expectedBody:   "attempt 2",
+			expectedDelays: []time.Duration{7 * time.Second},
+		},
+		{
+			name:           "Retry-After longer than the maximum backoff is not waited for",
+			method:         http.MethodGet,
+			statuses:       []int{http.StatusTooManyRequests, http.StatusOK},
+			headers:        map[string]string{"Retry-After": "86400"},
+			retry:          &config.RetrySetting{MaxAttempts: 2, MaxBackoff: config.Duration(time.Minute)},
+			expectedHits:   1,
+			expectedBody:   "attempt 1",
+			expectedDelays: []time.Duration{},
+		},
+		{
+			name:         "non idempotent method is not retried by default",
+			method:       http.MethodPost,
+			body:         `{"name": "order"}`,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			retry:        &config.RetrySetting{MaxAttempts: 3},
+			expectedHits: 1,
+			expectedBody: "attempt 1",
+		},
+		{
+			name:             "non idempotent method is retried with its body when allowed",
+			method:           http.MethodPost,
+			body:             `{"name": "order"}`,
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusOK},
+			retry:            &config.RetrySetting{MaxAttempts: 3, AllMethods: true},
+			expectedHits:     2,
+			expectedBody:     "attempt 2",
+			expectedRequests: []string{`{"name": "order"}`, `{"name": "order"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays := stubSleep(t)
+
+			var hits atomic.Int32
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hit := hits.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				requests = append(requests, string(body))
+
+				for k, v := range tt.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.statuses[hit-1])
+				fmt.Fprintf(w, "attempt %d", hit)
+			}))
+			defer server.Close()
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, err := http.NewRequest(tt.method, server.URL, body)
+			require.NoError(t, err)
+
+			resp, err := Execute(req, config.Setting{Retry: tt.retry})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedBody, string(resp.Body))
+			require.Equal(t, tt.expectedHits, hits.Load())
+
+			if tt.expectedDelays != nil {
+				require.Equal(t, tt.expectedDelays, *delays)
+			}
+			if tt.expectedRequests != nil {
+				require.Equal(t, tt.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestExecute_RetriesNetworkErrors(t *testing.T) {
+	stubSleep(t)
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if hits.Add(1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+		fmt.Fprint(w, "recovered")
+	}))
+	defer server.Close()
+
+	var verbose bytes.Buffer
+	originalVerbose := Verbose
+	Verbose = &verbose
+	defer func() {
+		Verbose = originalVerbose
+	}()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := Execute(req, config.Setting{Retry: &config.RetrySetting{MaxAttempts: 2}})
+	require.NoError(t, err)
+	require.Equal(t, "recovered", string(resp.Body))
+
+	log := verbose.String()
+	require.Contains(t, log, "[verbose] attempt 1/2: GET "+server.URL+" failed:")
+	require.Contains(t, log, "[verbose] retrying in")
+	require.Contains(t, log, "[verbose] attempt 2/2: GET "+server.URL+" 200 OK")
+}
+
+func TestExecute_RetryStopsAtTotalTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = Execute(req, config.Setting{
+		Retry:    &config.RetrySetting{MaxAttempts: 5, MaxBackoff: config.Duration(2 * time.Minute)},
+		Timeouts: &config.TimeoutSetting{Total: config.Duration(100 * time.Millisecond)},
+	})
+
+	var timeoutErr *TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, PhaseTotal, timeoutErr.Phase)
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := newRetryPolicy(&config.RetrySetting{
+		InitialBackoff: config.Duration(100 * time.Millisecond),
+		MaxBackoff:     config.Duration(time.Second),
+	})
+
+	tests := []struct {
+		attempt int
+		minimum time.Duration
+		maximum time.Duration
+	}{
+		{attempt: 1, minimum: 50 * time.Millisecond, maximum: 100 * time.Millisecond},
+		{attempt: 2, minimum: 100 * time.Millisecond, maximum: 200 * time.Millisecond},
+		{attempt: 3, minimum: 200 * time.Millisecond, maximum: 400 * time.Millisecond},
+		{attempt: 5, minimum: 500 * time.Millisecond, maximum: time.Second},
+		{attempt: 80, minimum: 500 * time.Millisecond, maximum: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			for range 20 {
+				delay, fromHeader := policy.delay(tt.attempt, nil)
+				require.False(t, fromHeader)
+				require.GreaterOrEqual(t, delay, tt.minimum)
+				require.LessOrEqual(t, delay, tt.maximum)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	fixedNow := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	originalNow := now
+	now = func() time.Time { return fixedNow }
+	defer func() {
+		now = originalNow
+	}()
+
+	tests := []struct {
+		name          string
+		value         string
+		expectedDelay time.Duration
+		expectedOK    bool
+	}{
+		{name: "empty", value: "", expectedOK: false},
+		{name: "seconds", value: "120", expectedDelay: 2 * time.Minute, expectedOK: true},
+		{name: "zero seconds", value: "0", expectedDelay: 0, expectedOK: true},
+		{name: "negative seconds", value: "-3", expectedOK: false},
+		{name: "HTTP date in the future", value: "Wed, 01 Jan 2025 12:00:30 GMT", expectedDelay: 30 * time.Second, expectedOK: true},
+		{name: "HTTP date in the past", value: "Wed, 01 Jan 2025 11:00:00 GMT", expectedDelay: 0, expectedOK: true},
+		{name: "garbage", value: "soon", expectedOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, ok := retryAfter(tt.value)
+			require.Equal(t, tt.expectedOK, ok)
+			require.Equal(t, tt.expectedDelay, delay)
+		})
+	}
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	require.Equal(t, retryPolicy{maxAttempts: 1}, newRetryPolicy(nil))
+
+	require.Equal(t, retryPolicy{
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		statuses:       defaultRetryStatuses,
+	}, newRetryPolicy(&config.RetrySetting{}))
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, expected: true},
+		{name: "dns failure", err: &net.DNSError{Err: "no such host", Name: "api.invalid"}, expected: true},
+		{name: "unexpected EOF", err: fmt.Errorf("read: %w", io.ErrUnexpectedEOF), expected: true},
+		{name: "phase timeout", err: &TimeoutError{Phase: PhaseResponseHeader}, expected: true},
+		{name: "total timeout", err: &TimeoutError{Phase: PhaseTotal}, expected: false},
+		{name: "cancelled", err: context.Canceled, expected: false},
+		{name: "unsupported digest", err: fmt.Errorf("digest authentication: %w", errUnsupportedDigest), expected: false},
+		{name: "unrelated error", err: errors.New("boom"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, isRetryableError(tt.err))
+		})
+	}
+}
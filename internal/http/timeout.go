@@ -0,0 +1,170 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/ashttp/internal/config"
+)
+
+const (
+	PhaseConnect        = "connect"
+	PhaseTLSHandshake   = "tls handshake"
+	PhaseResponseHeader = "response header"
+	PhaseTotal          = "total"
+)
+
+// TimeoutError is the cancellation cause of a request that exceeded one of
+// the configured timeouts.
+type TimeoutError struct {
+	Phase string
+	After time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timeout exceeded after %s", e.Phase, e.After)
+}
+
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// withDeadline bounds the whole exchange, body included, by the total timeout.
+func withDeadline(ctx context.Context, timeouts *config.TimeoutSetting) (context.Context, context.CancelFunc) {
+	if timeouts == nil || timeouts.Total <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	total := time.Duration(timeouts.Total)
+	return context.WithTimeoutCause(ctx, total, &TimeoutError{Phase: PhaseTotal, After: total})
+}
+
+type phaseTimer struct {
+	mu     sync.Mutex
+	cancel context.CancelCauseFunc
+	phase  string
+	timer  *time.Timer
+}
+
+func (p *phaseTimer) start(phase string, timeout config.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.phase == phase {
+		return
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+
+	d := time.Duration(timeout)
+	p.phase = phase
+	p.timer = time.AfterFunc(d, func() {
+		p.cancel(&TimeoutError{Phase: phase, After: d})
+	})
+}
+
+func (p *phaseTimer) stop(phase string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.phase != phase {
+		return
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.phase, p.timer = "", nil
+}
+
+func (p *phaseTimer) stopAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.phase, p.timer = "", nil
+}
+
+// withPhaseTimeouts returns a context cancelled as soon as the connect, TLS
+// handshake or response header phase of the request takes longer than its
+// configured timeout.
+func withPhaseTimeouts(ctx context.Context, timeouts *config.TimeoutSetting) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if timeouts == nil {
+		return ctx, cancel
+	}
+
+	timer := &phaseTimer{cancel: cancel}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			timer.start(PhaseConnect, timeouts.Connect)
+		},
+		ConnectStart: func(string, string) {
+			timer.start(PhaseConnect, timeouts.Connect)
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				timer.stop(PhaseConnect)
+			}
+		},
+		TLSHandshakeStart: func() {
+			timer.start(PhaseTLSHandshake, timeouts.TLSHandshake)
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, _ error) {
+			timer.stop(PhaseTLSHandshake)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			timer.start(PhaseResponseHeader, timeouts.ResponseHeader)
+		},
+		GotFirstResponseByte: func() {
+			timer.stop(PhaseResponseHeader)
+		},
+	}
+
+	return httptrace.WithClientTrace(ctx, trace), func(cause error) {
+		timer.stopAll()
+		cancel(cause)
+	}
+}
+
+// timeoutCause replaces err with the TimeoutError that cancelled ctx, if any.
+func timeoutCause(ctx context.Context, err error) error {
+	var timeoutErr *TimeoutError
+	if err != nil && errors.As(context.Cause(ctx), &timeoutErr) {
+		return timeoutErr
+	}
+	return err
+}
+
+// cancelOnClose releases the request context once the body has been consumed.
+type cancelOnClose struct {
+	io.ReadCloser
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+func (c *cancelOnClose) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = timeoutCause(c.ctx, err)
+	}
+	return n, err
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel(nil)
+	return err
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+	"time"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute_Timeouts(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       http.HandlerFunc
+		timeouts      *config.TimeoutSetting
+		expectedPhase string
+		expectedBody  string
+	}{
+		{
+			name: "response header timeout",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				time.Sleep(300 * time.Millisecond)
+				fmt.Fprint(w, "late")
+			},
+			timeouts: &config.TimeoutSetting{
+				ResponseHeader: config.Duration(50 * time.Millisecond),
+			},
+			expectedPhase: PhaseResponseHeader,
+		},
+		{
+			name: "total timeout while reading the body",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(w, "partial")
+				w.(http.Flusher).Flush()
+				time.Sleep(300 * time.Millisecond)
+				fmt.Fprint(w, "rest")
+			},
+			timeouts: &config.TimeoutSetting{
+				ResponseHeader: config.Duration(time.Second),
+				Total:          config.Duration(100 * time.Millisecond),
+			},
+			expectedPhase: PhaseTotal,
+		},
+		{
+			name: "total timeout before the response",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				time.Sleep(300 * time.Millisecond)
+				fmt.Fprint(w, "late")
+			},
+			timeouts: &config.TimeoutSetting{
+				Total: config.Duration(50 * time.Millisecond),
+			},
+			expectedPhase: PhaseTotal,
+		},
+		{
+			name: "fast response within every timeout",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(w, "fast")
+			},
+			timeouts: &config.TimeoutSetting{
+				Connect:        config.Duration(time.Second),
+				TLSHandshake:   config.Duration(time.Second),
+				ResponseHeader: config.Duration(time.Second),
+				Total:          config.Duration(time.Second),
+			},
+			expectedBody: "fast",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := Execute(req, config.Setting{Timeouts: tt.timeouts})
+			if tt.expectedPhase == "" {
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedBody, string(resp.Body))
+				return
+			}
+
+			var timeoutErr *TimeoutError
+			require.ErrorAs(t, err, &timeoutErr)
+			require.Equal(t, tt.expectedPhase, timeoutErr.Phase)
+			require.Contains(t, err.Error(), tt.expectedPhase)
+		})
+	}
+}
+
+func TestExecute_TLSHandshakeTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	req, err := http.NewRequest(http.MethodGet, "https://"+listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	_, err = Execute(req, config.Setting{
+		Timeouts: &config.TimeoutSetting{TLSHandshake: config.Duration(50 * time.Millisecond)},
+	})
+
+	var timeoutErr *TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, PhaseTLSHandshake, timeoutErr.Phase)
+}
+
+func TestWithPhaseTimeouts(t *testing.T) {
+	t.Run("connect phase exceeding its timeout cancels the context", func(t *testing.T) {
+		ctx, cancel := withPhaseTimeouts(context.Background(), &config.TimeoutSetting{
+			Connect: config.Duration(20 * time.Millisecond),
+		})
+		defer cancel(nil)
+
+		trace := httptrace.ContextClientTrace(ctx)
+		require.NotNil(t, trace)
+		trace.ConnectStart("tcp", "10.0.0.1:443")
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("context was not cancelled by the connect timeout")
+		}
+
+		require.Equal(t, &TimeoutError{Phase: PhaseConnect, After: 20 * time.Millisecond}, context.Cause(ctx))
+		require.EqualError(t, context.Cause(ctx), "connect timeout exceeded after 20ms")
+	})
+
+	t.Run("completed phase does not cancel the context", func(t *testing.T) {
+		ctx, cancel := withPhaseTimeouts(context.Background(), &config.TimeoutSetting{
+			Connect: config.Duration(20 * time.Millisecond),
+		})
+		defer cancel(nil)
+
+		trace := httptrace.ContextClientTrace(ctx)
+		trace.DNSStart(httptrace.DNSStartInfo{Host: "example.com"})
+		trace.ConnectStart("tcp", "10.0.0.1:443")
+		trace.ConnectDone("tcp", "10.0.0.1:443", nil)
+
+		time.Sleep(50 * time.Millisecond)
+		require.NoError(t, ctx.Err())
+	})
+
+	t.Run("nil timeouts only add cancellation", func(t *testing.T) {
+		ctx, cancel := withPhaseTimeouts(context.Background(), nil)
+		require.Nil(t, httptrace.ContextClientTrace(ctx))
+
+		cancel(nil)
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timing breaks down the last exchange of a request. TimeToFirstByte is the
+// wait between the request being written and the first response byte, i.e.
+// the server processing time. Total covers the whole call, retries and
+// redirects included.
+type Timing struct {
+	DNSLookup        time.Duration
+	Connect          time.Duration
+	TLSHandshake     time.Duration
+	TimeToFirstByte  time.Duration
+	ContentTransfer  time.Duration
+	Total            time.Duration
+	ConnectionReused bool
+}
+
+func (t Timing) String() string {
+	rows := []struct {
+		label string
+		value string
+	}{
+		{"DNS lookup", formatDuration(t.DNSLookup)},
+		{"TCP connect", formatDuration(t.Connect)},
+		{"TLS handshake", formatDuration(t.TLSHandshake)},
+		{"Time to first byte", formatDuration(t.TimeToFirstByte)},
+		{"Content transfer", formatDuration(t.ContentTransfer)},
+		{"Total", formatDuration(t.Total)},
+		{"Connection reused", fmt.Sprint(t.ConnectionReused)},
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		fmt.Fprintf(&b, "%-20s%s\n", row.label+":", row.value)
+	}
+	return b.String()
+}
+
+func (t Timing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		DNSLookup        float64 `json:"dnsLookupMs"`
+		Connect          float64 `json:"connectMs"`
+		TLSHandshake     float64 `json:"tlsHandshakeMs"`
+		TimeToFirstByte  float64 `json:"timeToFirstByteMs"`
+		ContentTransfer  float64 `json:"contentTransferMs"`
+		Total            float64 `json:"totalMs"`
+		ConnectionReused bool    `json:"connectionReused"`
+	}{
+		DNSLookup:        milliseconds(t.DNSLookup),
+		Connect:          milliseconds(t.Connect),
+		TLSHandshake:     milliseconds(t.TLSHandshake),
+		TimeToFirstByte:  milliseconds(t.TimeToFirstByte),
+		ContentTransfer:  milliseconds(t.ContentTransfer),
+		Total:            milliseconds(t.Total),
+		ConnectionReused: t.ConnectionReused,
+	})
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
+
+// timingRecorder collects the httptrace events of a request. Every new
+// exchange, such as a retry or a redirect hop, starts a fresh breakdown.
+type timingRecorder struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	reused       bool
+}
+
+func newTimingRecorder() *timingRecorder {
+	return &timingRecorder{start: now()}
+}
+
+func (r *timingRecorder) record(field *time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*field = now()
+}
+
+func (r *timingRecorder) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.dnsStart, r.dnsDone, r.connectStart, r.connectDone = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+			r.tlsStart, r.tlsDone, r.wroteRequest, r.firstByte = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.reused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { r.record(&r.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { r.record(&r.dnsDone) },
+		ConnectStart: func(string, string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.connectStart.IsZero() {
+				r.connectStart = now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				r.record(&r.connectDone)
+			}
+		},
+		TLSHandshakeStart:    func() { r.record(&r.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { r.record(&r.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { r.record(&r.wroteRequest) },
+		GotFirstResponseByte: func() { r.record(&r.firstByte) },
+	}
+}
+
+// timing builds the breakdown of the last exchange, ended at end.
+func (r *timingRecorder) timing(end time.Time) Timing {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Timing{
+		DNSLookup:        between(r.dnsStart, r.dnsDone),
+		Connect:          between(r.connectStart, r.connectDone),
+		TLSHandshake:     between(r.tlsStart, r.tlsDone),
+		TimeToFirstByte:  between(r.wroteRequest, r.firstByte),
+		ContentTransfer:  between(r.firstByte, end),
+		Total:            between(r.start, end),
+		ConnectionReused: r.reused,
+	}
+}
+
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute_Timing(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, "first")
+		w.(http.Flusher).Flush()
+		time.Sleep(30 * time.Millisecond)
+		fmt.Fprint(w, "second")
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := Execute(req, config.Setting{TLS: &config.TLSSetting{InsecureSkipVerify: true}})
+	require.NoError(t, err)
+	require.Equal(t, "firstsecond", string(resp.Body))
+
+	timing := resp.Timing
+	require.Positive(t, timing.Connect)
+	require.Positive(t, timing.TLSHandshake)
+	require.GreaterOrEqual(t, timing.TimeToFirstByte, 50*time.Millisecond)
+	require.GreaterOrEqual(t, timing.ContentTransfer, 30*time.Millisecond)
+	require.GreaterOrEqual(t, timing.Total, timing.Connect+timing.TLSHandshake+timing.TimeToFirstByte+timing.ContentTransfer)
+	require.False(t, timing.ConnectionReused)
+}
+
+func TestExecute_TimingReportsTheLastExchange(t *testing.T) {
+	stubSleep(t)
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := Execute(req, config.Setting{Retry: &config.RetrySetting{MaxAttempts: 2}})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.True(t, resp.Timing.ConnectionReused)
+	require.Zero(t, resp.Timing.Connect)
+}
+
+func TestTiming_String(t *testing.T) {
+	timing := Timing{
+		DNSLookup:        1500 * time.Microsecond,
+		Connect:          2 * time.Millisecond,
+		TLSHandshake:     10 * time.Millisecond,
+		TimeToFirstByte:  120 * time.Millisecond,
+		ContentTransfer:  3 * time.Millisecond,
+		Total:            137 * time.Millisecond,
+		ConnectionReused: true,
+	}
+
+	expected := "" +
+		"DNS lookup:         1.5ms\n" +
+		"TCP connect:        2ms\n" +
+		"TLS handshake:      10ms\n" +
+		"Time to first byte: 120ms\n" +
+		"Content transfer:   3ms\n" +
+		"Total:              137ms\n" +
+		"Connection reused:  true\n"
+
+	require.Equal(t, expected, timing.String())
+}
+
+func TestTiming_MarshalJSON(t *testing.T) {
+	timing := Timing{
+		DNSLookup:       1500 * time.Microsecond,
+		Connect:         2 * time.Millisecond,
+		TimeToFirstByte: 120 * time.Millisecond,
+		Total:           137 * time.Millisecond,
+	}
+
+	data, err := json.Marshal(timing)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"dnsLookupMs": 1.5,
+		"connectMs": 2,
+		"tlsHandshakeMs": 0,
+		"timeToFirstByteMs": 120,
+		"contentTransferMs": 0,
+		"totalMs": 137,
+		"connectionReused": false
+	}`, string(data))
+}
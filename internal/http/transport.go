@@ -0,0 +1,85 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/ashttp/internal/config"
+)
+
+var warningOutput io.Writer = os.Stderr
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func newTransport(setting config.Setting) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy, err := newProxyFunc(setting.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	if setting.TLS != nil {
+		tlsConfig, err := newTLSConfig(*setting.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls setting: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return transport, nil
+}
+
+func newTLSConfig(setting config.TLSSetting) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         setting.ServerName,
+		InsecureSkipVerify: setting.InsecureSkipVerify,
+	}
+
+	if setting.MinVersion != "" {
+		version, ok := tlsVersions[setting.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown minimum version %q, expected one of 1.0, 1.1, 1.2, 1.3", setting.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if setting.Cert != "" || setting.Key != "" {
+		cert, err := tls.LoadX509KeyPair(os.ExpandEnv(setting.Cert), os.ExpandEnv(setting.Key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if setting.CA != "" {
+		pem, err := os.ReadFile(os.ExpandEnv(setting.CA))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", setting.CA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if setting.InsecureSkipVerify {
+		fmt.Fprintln(warningOutput,
+			"[warning] TLS certificate verification is DISABLED for this alias (insecureSkipVerify), "+
+				"the connection is open to man-in-the-middle attacks")
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,324 @@
+package http
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (c testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+func (c testCertificate) writeFiles(t *testing.T, dir, name string) (certPath, keyPath string) {
+	certPath = filepath.Join(dir, name+".crt")
+	keyPath = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certPath, c.certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyPath, c.keyPEM, 0600))
+	return certPath, keyPath
+}
+
+func generateCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+type testPKI struct {
+	dir        string
+	caPath     string
+	clientCert string
+	clientKey  string
+	server     tls.Certificate
+	clientCAs  *x509.CertPool
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	dir := t.TempDir()
+
+	ca := generateCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ashttp test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}, nil)
+
+	server := generateCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "internal.service"},
+		DNSNames:    []string{"internal.service"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	}, &ca)
+
+	client := generateCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "ashttp client"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	}, &ca)
+
+	caPath := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caPath, ca.certPEM, 0600))
+	clientCert, clientKey := client.writeFiles(t, dir, "client")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	return testPKI{
+		dir:        dir,
+		caPath:     caPath,
+		clientCert: clientCert,
+		clientKey:  clientKey,
+		server:     server.tlsCertificate(t),
+		clientCAs:  pool,
+	}
+}
+
+func newMutualTLSServer(t *testing.T, pki testPKI) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"client": %q}`, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pki.server},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pki.clientCAs,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExecute_MutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newMutualTLSServer(t, pki)
+	otherPKI := newTestPKI(t)
+
+	tests := []struct {
+		name         string
+		tls          *config.TLSSetting
+		expectedBody string
+		expectError  bool
+	}{
+		{
+			name: "client certificate and private CA",
+			tls: &config.TLSSetting{
+				Cert:       pki.clientCert,
+				Key:        pki.clientKey,
+				CA:         pki.caPath,
+				ServerName: "internal.service",
+			},
+			expectedBody: `{"client": "ashttp client"}`,
+		},
+		{
+			name: "minimum version is honored",
+			tls: &config.TLSSetting{
+				Cert:       pki.clientCert,
+				Key:        pki.clientKey,
+				CA:         pki.caPath,
+				ServerName: "internal.service",
+				MinVersion: "1.3",
+			},
+			expectedBody: `{"client": "ashttp client"}`,
+		},
+		{
+			name:        "default TLS does not trust the private CA",
+			tls:         nil,
+			expectError: true,
+		},
+		{
+			name: "server name must match the certificate",
+			tls: &config.TLSSetting{
+				Cert: pki.clientCert,
+				Key:  pki.clientKey,
+				CA:   pki.caPath,
+			},
+			expectError: true,
+		},
+		{
+			name: "missing client certificate is rejected by the server",
+			tls: &config.TLSSetting{
+				CA:         pki.caPath,
+				ServerName: "internal.service",
+			},
+			expectError: true,
+		},
+		{
+			name: "client certificate from another CA is rejected",
+			tls: &config.TLSSetting{
+				Cert:       otherPKI.clientCert,
+				Key:        otherPKI.clientKey,
+				CA:         pki.caPath,
+				ServerName: "internal.service",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setting := config.Setting{URL: server.URL, TLS: tt.tls}
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := Execute(req, setting)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedBody, string(resp.Body))
+		})
+	}
+}
+
+func TestExecute_InsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	var warnings bytes.Buffer
+	originalOutput := warningOutput
+	warningOutput = &warnings
+	defer func() {
+		warningOutput = originalOutput
+	}()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := Execute(req, config.Setting{TLS: &config.TLSSetting{InsecureSkipVerify: true}})
+	require.NoError(t, err)
+	require.Equal(t, "ok", string(resp.Body))
+	require.Contains(t, warnings.String(), "[warning] TLS certificate verification is DISABLED")
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	pki := newTestPKI(t)
+	invalidPEM := filepath.Join(pki.dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalidPEM, []byte("not a certificate"), 0600))
+
+	tests := []struct {
+		name        string
+		setting     config.TLSSetting
+		expectError bool
+		check       func(t *testing.T, cfg *tls.Config)
+	}{
+		{
+			name:    "empty setting keeps defaults",
+			setting: config.TLSSetting{},
+			check: func(t *testing.T, cfg *tls.Config) {
+				require.Nil(t, cfg.RootCAs)
+				require.Empty(t, cfg.Certificates)
+				require.False(t, cfg.InsecureSkipVerify)
+			},
+		},
+		{
+			name:    "minimum version 1.2",
+			setting: config.TLSSetting{MinVersion: "1.2"},
+			check: func(t *testing.T, cfg *tls.Config) {
+				require.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+			},
+		},
+		{
+			name: "paths are expanded from environment variables",
+			setting: config.TLSSetting{
+				CA: filepath.Join("$ASHTTP_TEST_PKI", "ca.pem"),
+			},
+			check: func(t *testing.T, cfg *tls.Config) {
+				require.NotNil(t, cfg.RootCAs)
+			},
+		},
+		{
+			name:        "unknown minimum version",
+			setting:     config.TLSSetting{MinVersion: "2.0"},
+			expectError: true,
+		},
+		{
+			name:        "missing key file",
+			setting:     config.TLSSetting{Cert: pki.clientCert},
+			expectError: true,
+		},
+		{
+			name:        "missing CA file",
+			setting:     config.TLSSetting{CA: filepath.Join(pki.dir, "missing.pem")},
+			expectError: true,
+		},
+		{
+			name:        "CA file without certificates",
+			setting:     config.TLSSetting{CA: invalidPEM},
+			expectError: true,
+		},
+	}
+
+	t.Setenv("ASHTTP_TEST_PKI", pki.dir)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := newTLSConfig(tt.setting)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestNewTransport_InvalidTLSSetting(t *testing.T) {
+	_, err := newTransport(config.Setting{TLS: &config.TLSSetting{MinVersion: "9"}})
+	require.ErrorContains(t, err, "invalid tls setting")
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ashttp/internal/config"
+)
+
+// Upgrade sends req, a handshake such as the WebSocket one, and returns
+// the connection once the server has switched protocols. The alias TLS,
+// proxy and timeout settings apply as for any request.
+func Upgrade(req *http.Request, setting config.Setting) (*http.Response, io.ReadWriteCloser, error) {
+	transport, err := newTransport(setting)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	ctx, cancel := withPhaseTimeouts(req.Context(), setting.Timeouts)
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel(nil)
+		return nil, nil, timeoutCause(ctx, err)
+	}
+	logf("%s %s %s", req.Method, req.URL, resp.Status)
+
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if resp.StatusCode != http.StatusSwitchingProtocols || !ok {
+		resp.Body.Close()
+		cancel(nil)
+		return resp, nil, fmt.Errorf("the server answered %s instead of switching protocols", resp.Status)
+	}
+
+	return resp, upgradedConn{ReadWriteCloser: conn, cancel: cancel}, nil
+}
+
+// upgradedConn releases the handshake context once the connection closes.
+type upgradedConn struct {
+	io.ReadWriteCloser
+	cancel context.CancelCauseFunc
+}
+
+func (c upgradedConn) Close() error {
+	err := c.ReadWriteCloser.Close()
+	c.cancel(nil)
+	return err
+}
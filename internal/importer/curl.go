@@ -0,0 +1,374 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Command is a curl invocation broken down into the parts ashttp cares about.
+type Command struct {
+	Method   string
+	URL      string
+	Headers  map[string]string
+	Body     []byte
+	Insecure bool
+}
+
+// curlFlags are accepted and ignored since they only change how curl itself
+// behaves or prints the response.
+var curlFlags = map[string]bool{
+	"-s": true, "--silent": true,
+	"-S": true, "--show-error": true,
+	"-L": true, "--location": true,
+	"-i": true, "--include": true,
+	"-v": true, "--verbose": true,
+	"-f": true, "--fail": true,
+	"-g": true, "--globoff": true,
+	"--compressed": true,
+}
+
+type curlParser struct {
+	command   Command
+	data      []string
+	form      []string
+	getQuery  bool
+	hasMethod bool
+}
+
+// ParseCurl parses a curl command line as it would be pasted in a shell.
+func ParseCurl(command string) (Command, error) {
+	words, err := splitWords(command)
+	if err != nil {
+		return Command{}, err
+	}
+
+	if len(words) == 0 || words[0] != "curl" {
+		return Command{}, fmt.Errorf("not a curl command")
+	}
+
+	p := curlParser{command: Command{Headers: map[string]string{}}}
+	args := expandShortFlags(words[1:])
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if p.command.URL != "" {
+				return Command{}, fmt.Errorf("unexpected argument %q, only one URL is supported", arg)
+			}
+			p.command.URL = arg
+			continue
+		}
+
+		if curlFlags[arg] {
+			continue
+		}
+
+		switch arg {
+		case "-k", "--insecure":
+			p.command.Insecure = true
+			continue
+		case "-G", "--get":
+			p.getQuery = true
+			continue
+		}
+
+		if i+1 >= len(args) {
+			return Command{}, fmt.Errorf("option %s expects a value", arg)
+		}
+		i++
+		if err := p.option(arg, args[i]); err != nil {
+			return Command{}, err
+		}
+	}
+
+	if err := p.finish(); err != nil {
+		return Command{}, err
+	}
+
+	return p.command, nil
+}
+
+func (p *curlParser) option(name, value string) error {
+	switch name {
+	case "-X", "--request":
+		p.command.Method = strings.ToUpper(value)
+		p.hasMethod = true
+	case "--url":
+		p.command.URL = value
+	case "-H", "--header":
+		key, headerValue, found := strings.Cut(value, ":")
+		if !found {
+			return fmt.Errorf("invalid header %q, expected \"Name: value\"", value)
+		}
+		p.command.Headers[http.CanonicalHeaderKey(strings.TrimSpace(key))] = strings.TrimSpace(headerValue)
+	case "-A", "--user-agent":
+		p.command.Headers["User-Agent"] = value
+	case "-e", "--referer":
+		p.command.Headers["Referer"] = value
+	case "-b", "--cookie":
+		p.command.Headers["Cookie"] = value
+	case "-u", "--user":
+		p.command.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(value))
+	case "-d", "--data", "--data-ascii", "--data-binary":
+		data, err := readData(value)
+		if err != nil {
+			return err
+		}
+		p.data = append(p.data, data)
+	case "--data-raw":
+		p.data = append(p.data, value)
+	case "--data-urlencode":
+		data, err := urlencodeData(value)
+		if err != nil {
+			return err
+		}
+		p.data = append(p.data, data)
+	case "-F", "--form":
+		p.form = append(p.form, value)
+	default:
+		return fmt.Errorf("unsupported curl option %s", name)
+	}
+
+	return nil
+}
+
+func (p *curlParser) finish() error {
+	if p.command.URL == "" {
+		return fmt.Errorf("no URL found in the curl command")
+	}
+
+	if !strings.Contains(p.command.URL, "://") {
+		p.command.URL = "http://" + p.command.URL
+	}
+
+	if len(p.form) > 0 && len(p.data) > 0 {
+		return fmt.Errorf("-F cannot be combined with -d")
+	}
+
+	switch {
+	case p.getQuery:
+		if len(p.data) > 0 {
+			separator := "?"
+			if strings.Contains(p.command.URL, "?") {
+				separator = "&"
+			}
+			p.command.URL += separator + strings.Join(p.data, "&")
+		}
+		p.setMethod(http.MethodGet)
+	case len(p.form) > 0:
+		if err := p.multipartBody(); err != nil {
+			return err
+		}
+		p.setMethod(http.MethodPost)
+	case len(p.data) > 0:
+		p.command.Body = []byte(strings.Join(p.data, "&"))
+		if _, ok := p.command.Headers["Content-Type"]; !ok {
+			p.command.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+		p.setMethod(http.MethodPost)
+	default:
+		p.setMethod(http.MethodGet)
+	}
+
+	return nil
+}
+
+func (p *curlParser) setMethod(method string) {
+	if !p.hasMethod {
+		p.command.Method = method
+	}
+}
+
+func (p *curlParser) multipartBody() error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, field := range p.form {
+		name, value, found := strings.Cut(field, "=")
+		if !found {
+			return fmt.Errorf("invalid form field %q, expected name=value", field)
+		}
+
+		if path, isFile := strings.CutPrefix(value, "@"); isFile {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read form file: %w", err)
+			}
+			part, err := writer.CreateFormFile(name, filepath.Base(path))
+			if err != nil {
+				return err
+			}
+			if _, err := part.Write(content); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := writer.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	p.command.Body = body.Bytes()
+	p.command.Headers["Content-Type"] = writer.FormDataContentType()
+	return nil
+}
+
+// readData follows curl's -d semantics: "@path" reads the body from a file.
+func readData(value string) (string, error) {
+	path, isFile := strings.CutPrefix(value, "@")
+	if !isFile {
+		return value, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	return strings.NewReplacer("\r", "", "\n", "").Replace(string(content)), nil
+}
+
+// urlencodeData follows curl's --data-urlencode forms: "content",
+// "=content", "name=content", "@path" and "name@path".
+func urlencodeData(value string) (string, error) {
+	if name, content, found := strings.Cut(value, "="); found {
+		if name == "" {
+			return url.QueryEscape(content), nil
+		}
+		return name + "=" + url.QueryEscape(content), nil
+	}
+
+	if name, path, found := strings.Cut(value, "@"); found {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read data file: %w", err)
+		}
+		if name == "" {
+			return url.QueryEscape(string(content)), nil
+		}
+		return name + "=" + url.QueryEscape(string(content)), nil
+	}
+
+	return url.QueryEscape(value), nil
+}
+
+// expandShortFlags splits grouped short options such as "-sSL" and attached
+// values such as "-XPOST" into separate arguments.
+func expandShortFlags(args []string) []string {
+	expanded := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) <= 2 || arg[0] != '-' || arg[1] == '-' {
+			expanded = append(expanded, arg)
+			if takesValue(arg) && i+1 < len(args) {
+				i++
+				expanded = append(expanded, args[i])
+			}
+			continue
+		}
+
+		for j := 1; j < len(arg); j++ {
+			short := "-" + string(arg[j])
+			expanded = append(expanded, short)
+			if takesValue(short) {
+				if j+1 < len(arg) {
+					expanded = append(expanded, arg[j+1:])
+				} else if i+1 < len(args) {
+					i++
+					expanded = append(expanded, args[i])
+				}
+				break
+			}
+		}
+	}
+
+	return expanded
+}
+
+func takesValue(option string) bool {
+	if !strings.HasPrefix(option, "-") || curlFlags[option] {
+		return false
+	}
+
+	switch option {
+	case "-k", "--insecure", "-G", "--get":
+		return false
+	}
+
+	return true
+}
+
+// splitWords splits a command the way a POSIX shell would, honoring single
+// and double quotes, backslash escapes and line continuations.
+func splitWords(command string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			escaped = false
+			if r == '\n' {
+				continue
+			}
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			inWord = true
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case r == '\\':
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		current.WriteRune('\\')
+	}
+	if inWord || escaped {
+		words = append(words, current.String())
+	}
+
+	return words, nil
+}
@@ -0,0 +1,207 @@
+package importer
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCurl(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataFile := filepath.Join(tmpDir, "body.json")
+	require.NoError(t, os.WriteFile(dataFile, []byte("{\"name\":\n\"ana\"}\n"), 0644))
+
+	tests := []struct {
+		name        string
+		command     string
+		expected    Command
+		expectError string
+	}{
+		{
+			name:    "plain GET",
+			command: "curl https://api.example.com/users",
+			expected: Command{
+				Method:  "GET",
+				URL:     "https://api.example.com/users",
+				Headers: map[string]string{},
+			},
+		},
+		{
+			name: "multi line command with quotes and headers",
+			command: `curl -X POST 'https://api.example.com/users' \
+  -H 'Content-Type: application/json' \
+  -H "authorization: Bearer abc" \
+  --data-raw '{"name": "ana"}'`,
+			expected: Command{
+				Method: "POST",
+				URL:    "https://api.example.com/users",
+				Headers: map[string]string{
+					"Content-Type":  "application/json",
+					"Authorization": "Bearer abc",
+				},
+				Body: []byte(`{"name": "ana"}`),
+			},
+		},
+		{
+			name:    "data implies POST and a form content type",
+			command: "curl -d name=ana -d age=30 https://api.example.com/users",
+			expected: Command{
+				Method:  "POST",
+				URL:     "https://api.example.com/users",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+				Body:    []byte("name=ana&age=30"),
+			},
+		},
+		{
+			name:    "data read from a file drops newlines",
+			command: "curl -H 'Content-Type: application/json' --data @" + dataFile + " https://api.example.com/users",
+			expected: Command{
+				Method:  "POST",
+				URL:     "https://api.example.com/users",
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    []byte(`{"name":"ana"}`),
+			},
+		},
+		{
+			name:    "url encoded data",
+			command: "curl --data-urlencode 'q=a b&c' --data-urlencode =x/y https://api.example.com/search",
+			expected: Command{
+				Method:  "POST",
+				URL:     "https://api.example.com/search",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+				Body:    []byte("q=a+b%26c&x%2Fy"),
+			},
+		},
+		{
+			name:    "get moves the data to the query string",
+			command: "curl -G -d limit=10 --data-urlencode 'q=a b' 'https://api.example.com/search?page=2'",
+			expected: Command{
+				Method:  "GET",
+				URL:     "https://api.example.com/search?page=2&limit=10&q=a+b",
+				Headers: map[string]string{},
+			},
+		},
+		{
+			name:    "basic auth, grouped flags and attached values",
+			command: "curl -sSLk -XDELETE -u ana:secret https://api.example.com/users/1",
+			expected: Command{
+				Method:   "DELETE",
+				URL:      "https://api.example.com/users/1",
+				Headers:  map[string]string{"Authorization": "Basic YW5hOnNlY3JldA=="},
+				Insecure: true,
+			},
+		},
+		{
+			name:    "url option and missing scheme",
+			command: "curl --compressed --url api.example.com/health",
+			expected: Command{
+				Method:  "GET",
+				URL:     "http://api.example.com/health",
+				Headers: map[string]string{},
+			},
+		},
+		{
+			name:        "not curl",
+			command:     "wget https://api.example.com",
+			expectError: "not a curl command",
+		},
+		{
+			name:        "unterminated quote",
+			command:     "curl 'https://api.example.com",
+			expectError: "unterminated ' quote",
+		},
+		{
+			name:        "unsupported option",
+			command:     "curl -o out.json https://api.example.com",
+			expectError: "unsupported curl option -o",
+		},
+		{
+			name:        "missing value",
+			command:     "curl https://api.example.com -H",
+			expectError: "option -H expects a value",
+		},
+		{
+			name:        "missing URL",
+			command:     "curl -X GET",
+			expectError: "no URL found",
+		},
+		{
+			name:        "several URLs",
+			command:     "curl https://a.example.com https://b.example.com",
+			expectError: "only one URL is supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseCurl(tt.command)
+			if tt.expectError != "" {
+				require.ErrorContains(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, cmd)
+		})
+	}
+}
+
+func TestParseCurl_Form(t *testing.T) {
+	avatar := filepath.Join(t.TempDir(), "avatar.png")
+	require.NoError(t, os.WriteFile(avatar, []byte("png-bytes"), 0644))
+
+	cmd, err := ParseCurl("curl -F name=ana -F avatar=@" + avatar + " https://api.example.com/profile")
+	require.NoError(t, err)
+	require.Equal(t, "POST", cmd.Method)
+
+	mediaType, params, err := mime.ParseMediaType(cmd.Headers["Content-Type"])
+	require.NoError(t, err)
+	require.Equal(t, "multipart/form-data", mediaType)
+
+	reader := multipart.NewReader(strings.NewReader(string(cmd.Body)), params["boundary"])
+
+	part, err := reader.NextPart()
+	require.NoError(t, err)
+	require.Equal(t, "name", part.FormName())
+	value, err := io.ReadAll(part)
+	require.NoError(t, err)
+	require.Equal(t, "ana", string(value))
+
+	part, err = reader.NextPart()
+	require.NoError(t, err)
+	require.Equal(t, "avatar", part.FormName())
+	require.Equal(t, "avatar.png", part.FileName())
+	value, err = io.ReadAll(part)
+	require.NoError(t, err)
+	require.Equal(t, "png-bytes", string(value))
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{name: "plain words", input: "a b  c", expected: []string{"a", "b", "c"}},
+		{name: "single quotes keep everything", input: `'a \ "b"' c`, expected: []string{`a \ "b"`, "c"}},
+		{name: "double quotes unescape", input: `"a \"b\" \$x \n"`, expected: []string{`a "b" $x \n`}},
+		{name: "backslash escapes a space", input: `a\ b c`, expected: []string{"a b", "c"}},
+		{name: "line continuation", input: "a \\\n  b", expected: []string{"a", "b"}},
+		{name: "empty quoted word", input: "a '' b", expected: []string{"a", "", "b"}},
+		{name: "adjacent quoted parts", input: `a'b'"c"`, expected: []string{"abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := splitWords(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, words)
+		})
+	}
+}
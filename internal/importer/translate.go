@@ -0,0 +1,207 @@
+package importer
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ashttp/internal/config"
+	"github.com/ashttp/internal/export"
+	internalhttp "github.com/ashttp/internal/http"
+)
+
+// Suggestion is the ashttp counterpart of a curl command: the alias to call
+// it with and the request relative to that alias.
+type Suggestion struct {
+	Alias config.URLAlias
+	// NewAlias is set when no existing alias matched the URL and holds the
+	// entry to add to the config file.
+	NewAlias *config.ExternalSettingURLAlias
+	// Setting is the one the request runs with, either the matched alias
+	// setting or the one built from NewAlias.
+	Setting        config.Setting
+	Method         string
+	PathComponents []string
+	Options        map[string]string
+	Headers        map[string]string
+	Body           []byte
+}
+
+// Translate maps cmd onto the alias whose base URL is the longest prefix of
+// its URL, or suggests a new alias named name when none matches. An empty
+// name derives one from the host.
+func Translate(cmd Command, settings config.SettingByURLAlias, name config.URLAlias) (Suggestion, error) {
+	target, err := url.Parse(cmd.URL)
+	if err != nil {
+		return Suggestion{}, fmt.Errorf("invalid URL %q: %w", cmd.URL, err)
+	}
+	if target.Host == "" {
+		return Suggestion{}, fmt.Errorf("invalid URL %q: missing host", cmd.URL)
+	}
+
+	s := Suggestion{
+		Method:  strings.ToLower(cmd.Method),
+		Options: queryOptions(target.RawQuery),
+		Headers: map[string]string{},
+		Body:    cmd.Body,
+	}
+
+	path := target.EscapedPath()
+	alias, setting, matched := matchAlias(target, settings)
+	if matched {
+		s.Alias = alias
+		s.Setting = setting
+		base, _ := url.Parse(strings.TrimSuffix(setting.URL, "/"))
+		path = strings.TrimPrefix(path, base.EscapedPath())
+
+		for k, v := range cmd.Headers {
+			if defaultValue, ok := headerValue(setting.Headers, k); !ok || defaultValue != v {
+				s.Headers[k] = v
+			}
+		}
+	} else {
+		s.newAlias(cmd, target, settings, name)
+	}
+
+	for component := range strings.SplitSeq(path, "/") {
+		if component != "" {
+			s.PathComponents = append(s.PathComponents, component)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *Suggestion) newAlias(cmd Command, target *url.URL, settings config.SettingByURLAlias, name config.URLAlias) {
+	if name == "" {
+		name = aliasName(target.Hostname(), settings)
+	}
+
+	alias := config.ExternalSettingURLAlias{
+		URL:            target.Scheme + "://" + target.Host,
+		DefaultHeaders: map[string]string{},
+	}
+	for k, v := range cmd.Headers {
+		// The content type belongs to this request only, not to every call
+		// made through the alias.
+		if k == "Content-Type" {
+			s.Headers[k] = v
+			continue
+		}
+		alias.DefaultHeaders[k] = v
+	}
+	if cmd.Insecure {
+		alias.TLS = &config.TLSSetting{InsecureSkipVerify: true}
+	}
+
+	s.Alias = name
+	s.NewAlias = &alias
+	s.Setting = config.Setting{URL: alias.URL, Headers: alias.DefaultHeaders, TLS: alias.TLS}
+}
+
+// Request builds the ashttp request the suggestion stands for.
+func (s Suggestion) Request() internalhttp.Request {
+	return internalhttp.Request{
+		Path:      strings.Join(s.PathComponents, "/"),
+		Method:    s.Method,
+		Headers:   s.Headers,
+		Arguments: s.Options,
+		Body:      s.Body,
+	}
+}
+
+// CommandLine returns the ashttp command equivalent to the curl one.
+func (s Suggestion) CommandLine() string {
+	words := []string{"ashttp"}
+	for _, k := range slices.Sorted(maps.Keys(s.Headers)) {
+		words = append(words, "-header", export.ShellQuote(k+": "+s.Headers[k]))
+	}
+	if len(s.Body) > 0 {
+		words = append(words, "-data", export.ShellQuote(string(s.Body)))
+	}
+
+	words = append(words, export.ShellQuote(string(s.Alias)), s.Method)
+	for _, component := range s.PathComponents {
+		words = append(words, export.ShellQuote(component))
+	}
+	for _, k := range slices.Sorted(maps.Keys(s.Options)) {
+		words = append(words, export.ShellQuote("--"+k), export.ShellQuote(s.Options[k]))
+	}
+
+	return strings.Join(words, " ")
+}
+
+// matchAlias finds the alias whose base URL is the longest prefix of target,
+// on a path segment boundary.
+func matchAlias(target *url.URL, settings config.SettingByURLAlias) (config.URLAlias, config.Setting, bool) {
+	var (
+		bestAlias   config.URLAlias
+		bestSetting config.Setting
+		bestLength  = -1
+	)
+
+	for alias, setting := range settings {
+		base, err := url.Parse(strings.TrimSuffix(setting.URL, "/"))
+		if err != nil || !strings.EqualFold(base.Scheme, target.Scheme) || !strings.EqualFold(base.Host, target.Host) {
+			continue
+		}
+
+		basePath := base.EscapedPath()
+		path := target.EscapedPath()
+		if path != basePath && !strings.HasPrefix(path, basePath+"/") {
+			continue
+		}
+
+		// Ties are broken by name so the choice does not depend on map order.
+		if len(basePath) > bestLength || (len(basePath) == bestLength && alias < bestAlias) {
+			bestAlias, bestSetting, bestLength = alias, setting, len(basePath)
+		}
+	}
+
+	return bestAlias, bestSetting, bestLength >= 0
+}
+
+// aliasName derives an alias from the host, e.g. "github" for
+// api.github.com, adding a numeric suffix if the name is already taken.
+func aliasName(host string, settings config.SettingByURLAlias) config.URLAlias {
+	name := host
+	if net.ParseIP(host) != nil {
+		name = strings.NewReplacer(".", "-", ":", "-").Replace(host)
+	} else if labels := strings.Split(host, "."); len(labels) >= 2 {
+		name = labels[len(labels)-2]
+	}
+
+	candidate := config.URLAlias(name)
+	for i := 2; ; i++ {
+		if _, taken := settings[candidate]; !taken {
+			return candidate
+		}
+		candidate = config.URLAlias(fmt.Sprintf("%s-%d", name, i))
+	}
+}
+
+// queryOptions keeps the values as they are written in the URL, since
+// ashttp does not escape options when building the query string.
+func queryOptions(rawQuery string) map[string]string {
+	options := map[string]string{}
+	for pair := range strings.SplitSeq(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(pair, "=")
+		options[k] = v
+	}
+	return options
+}
+
+func headerValue(headers map[string]string, name string) (string, bool) {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslate(t *testing.T) {
+	settings := config.SettingByURLAlias{
+		"httpbin": {
+			URL:     "https://httpbin.dev/anything",
+			Headers: map[string]string{"authorization": "123"},
+		},
+		"httpbin-root": {URL: "https://httpbin.dev"},
+		"github":       {URL: "https://api.github.com"},
+	}
+
+	tests := []struct {
+		name        string
+		command     Command
+		aliasName   config.URLAlias
+		expected    Suggestion
+		commandLine string
+	}{
+		{
+			name: "longest matching alias wins and default headers are dropped",
+			command: Command{
+				Method:  "GET",
+				URL:     "https://httpbin.dev/anything/users/456?include=posts,comments",
+				Headers: map[string]string{"Authorization": "123", "X-Trace": "on"},
+			},
+			expected: Suggestion{
+				Alias:          "httpbin",
+				Setting:        settings["httpbin"],
+				Method:         "get",
+				PathComponents: []string{"users", "456"},
+				Options:        map[string]string{"include": "posts,comments"},
+				Headers:        map[string]string{"X-Trace": "on"},
+			},
+			commandLine: "ashttp -header 'X-Trace: on' httpbin get users 456 --include posts,comments",
+		},
+		{
+			name: "prefix must end on a path segment",
+			command: Command{
+				Method:  "POST",
+				URL:     "https://httpbin.dev/anythingelse",
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    []byte(`{"a": 1}`),
+			},
+			expected: Suggestion{
+				Alias:          "httpbin-root",
+				Setting:        settings["httpbin-root"],
+				Method:         "post",
+				PathComponents: []string{"anythingelse"},
+				Options:        map[string]string{},
+				Headers:        map[string]string{"Content-Type": "application/json"},
+				Body:           []byte(`{"a": 1}`),
+			},
+			commandLine: `ashttp -header 'Content-Type: application/json' -data '{"a": 1}' httpbin-root post anythingelse`,
+		},
+		{
+			name: "unknown host suggests a new alias named after it",
+			command: Command{
+				Method: "DELETE",
+				URL:    "https://api.stripe.com/v1/customers/cus_1?expand=true",
+				Headers: map[string]string{
+					"Authorization": "Bearer sk",
+					"Content-Type":  "application/json",
+				},
+				Insecure: true,
+			},
+			expected: Suggestion{
+				Alias: "stripe",
+				NewAlias: &config.ExternalSettingURLAlias{
+					URL:            "https://api.stripe.com",
+					DefaultHeaders: map[string]string{"Authorization": "Bearer sk"},
+					TLS:            &config.TLSSetting{InsecureSkipVerify: true},
+				},
+				Setting: config.Setting{
+					URL:     "https://api.stripe.com",
+					Headers: map[string]string{"Authorization": "Bearer sk"},
+					TLS:     &config.TLSSetting{InsecureSkipVerify: true},
+				},
+				Method:         "delete",
+				PathComponents: []string{"v1", "customers", "cus_1"},
+				Options:        map[string]string{"expand": "true"},
+				Headers:        map[string]string{"Content-Type": "application/json"},
+			},
+			commandLine: "ashttp -header 'Content-Type: application/json' stripe delete v1 customers cus_1 --expand true",
+		},
+		{
+			name:      "new alias with an explicit name",
+			command:   Command{Method: "GET", URL: "http://127.0.0.1:8080/health"},
+			aliasName: "local",
+			expected: Suggestion{
+				Alias: "local",
+				NewAlias: &config.ExternalSettingURLAlias{
+					URL:            "http://127.0.0.1:8080",
+					DefaultHeaders: map[string]string{},
+				},
+				Setting: config.Setting{
+					URL:     "http://127.0.0.1:8080",
+					Headers: map[string]string{},
+				},
+				Method:         "get",
+				PathComponents: []string{"health"},
+				Options:        map[string]string{},
+				Headers:        map[string]string{},
+			},
+			commandLine: "ashttp local get health",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestion, err := Translate(tt.command, settings, tt.aliasName)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, suggestion)
+			require.Equal(t, tt.commandLine, suggestion.CommandLine())
+		})
+	}
+}
+
+func TestTranslate_InvalidURL(t *testing.T) {
+	_, err := Translate(Command{Method: "GET", URL: "https://"}, nil, "")
+	require.ErrorContains(t, err, "missing host")
+}
+
+func TestSuggestion_Request(t *testing.T) {
+	suggestion := Suggestion{
+		Alias:          "github",
+		Setting:        config.Setting{URL: "https://api.github.com"},
+		Method:         "post",
+		PathComponents: []string{"repos", "ashttp", "issues"},
+		Options:        map[string]string{"per_page": "10"},
+		Headers:        map[string]string{"Accept": "application/vnd.github+json"},
+		Body:           []byte(`{"title": "bug"}`),
+	}
+
+	req, err := suggestion.Request().ToHTTPRequest(suggestion.Setting)
+	require.NoError(t, err)
+	require.Equal(t, "POST", req.Method)
+	require.Equal(t, "https://api.github.com/repos/ashttp/issues?per_page=10", req.URL.String())
+	require.Equal(t, "application/vnd.github+json", req.Header.Get("Accept"))
+}
+
+func TestAliasName(t *testing.T) {
+	settings := config.SettingByURLAlias{"github": {}, "github-2": {}}
+
+	tests := []struct {
+		host     string
+		expected config.URLAlias
+	}{
+		{host: "api.stripe.com", expected: "stripe"},
+		{host: "api.github.com", expected: "github-3"},
+		{host: "localhost", expected: "localhost"},
+		{host: "10.0.0.1", expected: "10-0-0-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			require.Equal(t, tt.expected, aliasName(tt.host, settings))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package jsonrpc builds JSON-RPC 2.0 calls and reads their responses.
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ashttp/internal/jsonvalue"
+)
+
+// Call is a method to invoke with its params, nil, a list or an object.
+type Call struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Request is the envelope of a call as sent to the server.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      int64           `json:"id"`
+}
+
+// Requests wraps calls in envelopes numbered from 1.
+func Requests(calls []Call) []Request {
+	requests := make([]Request, len(calls))
+	for i, call := range calls {
+		requests[i] = Request{JSONRPC: "2.0", Method: call.Method, Params: call.Params, ID: int64(i + 1)}
+	}
+	return requests
+}
+
+// Body is the JSON body of requests, an array when they are a batch.
+func Body(requests []Request, batch bool) ([]byte, error) {
+	if !batch {
+		if len(requests) != 1 {
+			return nil, fmt.Errorf("expected a single call, got %d", len(requests))
+		}
+		return jsonvalue.Marshal(requests[0])
+	}
+	return jsonvalue.Marshal(requests)
+}
+
+// Params turns positional arguments into a list of params or options into
+// an object of named ones. Values are taken as JSON when they parse, as
+// strings otherwise, so 42 is a number and ana a string.
+func Params(positional []string, named map[string]string) (json.RawMessage, error) {
+	switch {
+	case len(positional) > 0 && len(named) > 0:
+		return nil, errors.New("params are either positional or named, not both")
+	case len(positional) > 0:
+		list := make([]any, len(positional))
+		for i, arg := range positional {
+			list[i] = value(arg)
+		}
+		return jsonvalue.Marshal(list)
+	case len(named) > 0:
+		names := make([]string, 0, len(named))
+		for name := range named {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		object := jsonvalue.NewObject()
+		for _, name := range names {
+			object.Set(name, value(named[name]))
+		}
+		return jsonvalue.Marshal(object)
+	}
+	return nil, nil
+}
+
+func value(arg string) any {
+	if decoded, err := jsonvalue.Decode([]byte(arg)); err == nil {
+		return decoded
+	}
+	return arg
+}
+
+// ReadBatch reads the calls of a batch file, a JSON array of objects with
+// a method and optional params.
+func ReadBatch(data []byte) ([]Call, error) {
+	var calls []Call
+	if err := json.Unmarshal(data, &calls); err != nil {
+		return nil, errors.New("a batch is a JSON array of {\"method\": ..., \"params\": ...} objects")
+	}
+	if len(calls) == 0 {
+		return nil, errors.New("the batch is empty")
+	}
+
+	for i, call := range calls {
+		if call.Method == "" {
+			return nil, fmt.Errorf("call %d has no method", i+1)
+		}
+		params := strings.TrimSpace(string(call.Params))
+		if params == "null" {
+			calls[i].Params = nil
+		} else if params != "" && params[0] != '[' && params[0] != '{' {
+			return nil, fmt.Errorf("params of call %d must be an array or an object", i+1)
+		}
+	}
+	return calls, nil
+}
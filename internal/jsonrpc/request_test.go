@@ -0,0 +1,106 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		positional []string
+		named      map[string]string
+		expected   string
+		err        string
+	}{
+		{name: "none"},
+		{
+			name:       "positional",
+			positional: []string{"1", "ana", "true", `{"b":1,"a":2}`, "null"},
+			expected:   `[1,"ana",true,{"b":1,"a":2},null]`,
+		},
+		{
+			name:     "named",
+			named:    map[string]string{"name": "ana", "age": "42", "tags": `["a"]`},
+			expected: `{"age":42,"name":"ana","tags":["a"]}`,
+		},
+		{
+			name:     "quoted number stays a string",
+			named:    map[string]string{"id": `"42"`},
+			expected: `{"id":"42"}`,
+		},
+		{
+			name:       "both",
+			positional: []string{"1"},
+			named:      map[string]string{"a": "2"},
+			err:        "params are either positional or named, not both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := Params(tt.positional, tt.named)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(params))
+		})
+	}
+}
+
+func TestBody(t *testing.T) {
+	requests := Requests([]Call{
+		{Method: "math.add", Params: json.RawMessage(`[1,2]`)},
+		{Method: "ping"},
+	})
+
+	body, err := Body(requests[:1], false)
+	require.NoError(t, err)
+	require.Equal(t, `{"jsonrpc":"2.0","method":"math.add","params":[1,2],"id":1}`, string(body))
+
+	body, err = Body(requests, true)
+	require.NoError(t, err)
+	require.Equal(t, `[{"jsonrpc":"2.0","method":"math.add","params":[1,2],"id":1},{"jsonrpc":"2.0","method":"ping","id":2}]`, string(body))
+
+	_, err = Body(requests, false)
+	require.EqualError(t, err, "expected a single call, got 2")
+}
+
+func TestReadBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Call
+		err      string
+	}{
+		{
+			name:  "calls",
+			input: `[{"method": "a", "params": [1]}, {"method": "b", "params": {"x": 1}}, {"method": "c", "params": null}]`,
+			expected: []Call{
+				{Method: "a", Params: json.RawMessage(`[1]`)},
+				{Method: "b", Params: json.RawMessage(`{"x": 1}`)},
+				{Method: "c"},
+			},
+		},
+		{name: "not an array", input: `{"method": "a"}`, err: `a batch is a JSON array of {"method": ..., "params": ...} objects`},
+		{name: "empty", input: `[]`, err: "the batch is empty"},
+		{name: "no method", input: `[{"method": "a"}, {"params": []}]`, err: "call 2 has no method"},
+		{name: "scalar params", input: `[{"method": "a", "params": 1}]`, err: "params of call 1 must be an array or an object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, err := ReadBatch([]byte(tt.input))
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, calls)
+		})
+	}
+}
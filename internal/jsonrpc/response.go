@@ -0,0 +1,82 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Error is the error member of a response.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// String prints an error as message (code -32601): data.
+func (e Error) String() string {
+	s := fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+	if len(e.Data) > 0 && string(e.Data) != "null" {
+		s += ": " + string(e.Data)
+	}
+	return s
+}
+
+// Result is the outcome of a call: the raw JSON of its result, or an error.
+type Result struct {
+	Result json.RawMessage
+	Error  *Error
+}
+
+type response struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+var errNoResponse = Error{Message: "the server sent no response for this call"}
+
+// ParseResponses matches the responses in body with requests, returning
+// one result per request in the same order whatever the order the server
+// answered in.
+func ParseResponses(body []byte, requests []Request) ([]Result, error) {
+	var responses []response
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &responses); err != nil {
+			return nil, errors.New("the response is not a JSON-RPC response")
+		}
+	} else {
+		var single response
+		if err := json.Unmarshal(body, &single); err != nil {
+			return nil, errors.New("the response is not a JSON-RPC response")
+		}
+		responses = []response{single}
+	}
+
+	byID := make(map[string]response, len(responses))
+	for _, r := range responses {
+		if r.Result == nil && r.Error == nil {
+			return nil, errors.New("the response has neither result nor error")
+		}
+		id := string(r.ID)
+		if id == "" {
+			id = "null"
+		}
+		byID[id] = r
+	}
+
+	results := make([]Result, len(requests))
+	for i, request := range requests {
+		r, found := byID[fmt.Sprint(request.ID)]
+		if !found {
+			// An error without id, such as a parse error, fails every call.
+			if r, found = byID["null"]; !found {
+				r.Error = &errNoResponse
+			}
+		}
+		results[i] = Result{Result: r.Result, Error: r.Error}
+	}
+	return results, nil
+}
@@ -0,0 +1,90 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResponses(t *testing.T) {
+	requests := Requests([]Call{{Method: "a"}, {Method: "b"}})
+
+	tests := []struct {
+		name     string
+		body     string
+		requests []Request
+		expected []Result
+		err      string
+	}{
+		{
+			name:     "result",
+			body:     `{"jsonrpc":"2.0","result":{"sum":3},"id":1}`,
+			requests: requests[:1],
+			expected: []Result{{Result: json.RawMessage(`{"sum":3}`)}},
+		},
+		{
+			name:     "null result",
+			body:     `{"jsonrpc":"2.0","result":null,"id":1}`,
+			requests: requests[:1],
+			expected: []Result{{Result: json.RawMessage(`null`)}},
+		},
+		{
+			name:     "error",
+			body:     `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`,
+			requests: requests[:1],
+			expected: []Result{{Error: &Error{Code: -32601, Message: "Method not found"}}},
+		},
+		{
+			name:     "batch answered out of order",
+			body:     `[{"jsonrpc":"2.0","result":"b","id":2}, {"jsonrpc":"2.0","result":"a","id":1}]`,
+			requests: requests,
+			expected: []Result{{Result: json.RawMessage(`"a"`)}, {Result: json.RawMessage(`"b"`)}},
+		},
+		{
+			name:     "missing response",
+			body:     `[{"jsonrpc":"2.0","result":"a","id":1}]`,
+			requests: requests,
+			expected: []Result{{Result: json.RawMessage(`"a"`)}, {Error: &errNoResponse}},
+		},
+		{
+			name:     "error without id fails every call",
+			body:     `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`,
+			requests: requests,
+			expected: []Result{
+				{Error: &Error{Code: -32700, Message: "Parse error"}},
+				{Error: &Error{Code: -32700, Message: "Parse error"}},
+			},
+		},
+		{
+			name:     "not json",
+			body:     `<html>`,
+			requests: requests[:1],
+			err:      "the response is not a JSON-RPC response",
+		},
+		{
+			name:     "not json-rpc",
+			body:     `{"status":"ok"}`,
+			requests: requests[:1],
+			err:      "the response has neither result nor error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := ParseResponses([]byte(tt.body), tt.requests)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, results)
+		})
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	require.Equal(t, "Method not found (code -32601)", Error{Code: -32601, Message: "Method not found"}.String())
+	require.Equal(t, `Invalid params (code -32602): {"field":"age"}`,
+		Error{Code: -32602, Message: "Invalid params", Data: json.RawMessage(`{"field":"age"}`)}.String())
+}
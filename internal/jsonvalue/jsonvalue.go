@@ -0,0 +1,130 @@
+// Package jsonvalue decodes JSON documents keeping the order of object keys,
+// so responses are rendered in the order the server wrote them.
+package jsonvalue
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Object is a JSON object whose keys keep their original order.
+type Object struct {
+	Keys   []string
+	Values map[string]any
+}
+
+func NewObject() *Object {
+	return &Object{Values: map[string]any{}}
+}
+
+func (o *Object) Get(key string) (any, bool) {
+	value, ok := o.Values[key]
+	return value, ok
+}
+
+// Set adds or replaces key, appending it to the keys when it is new.
+func (o *Object) Set(key string, value any) {
+	if _, exists := o.Values[key]; !exists {
+		o.Keys = append(o.Keys, key)
+	}
+	o.Values[key] = value
+}
+
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.Keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		k, err := Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := Marshal(o.Values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// Marshal encodes v as compact JSON without escaping HTML characters, which
+// only matters when JSON is embedded in HTML.
+func Marshal(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// Decode parses a single JSON document. Objects are decoded as *Object,
+// arrays as []any and numbers as json.Number so they are printed back
+// exactly as received.
+func Decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	value, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unexpected data after the JSON document")
+	}
+
+	return value, nil
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := NewObject()
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			object.Set(keyToken.(string), value)
+		}
+		_, err := dec.Token()
+		return object, err
+	case json.Delim('['):
+		array := []any{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err := dec.Token()
+		return array, err
+	default:
+		return token, nil
+	}
+}
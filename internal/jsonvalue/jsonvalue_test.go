@@ -0,0 +1,56 @@
+package jsonvalue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{name: "keys keep their order", input: `{"b": 1, "a": {"d": true, "c": null}}`, expected: `{"b":1,"a":{"d":true,"c":null}}`},
+		{name: "numbers are kept as written", input: `[1.50, 12345678901234567890, -0]`, expected: `[1.50,12345678901234567890,-0]`},
+		{name: "duplicated key keeps its first position", input: `{"a": 1, "b": 2, "a": 3}`, expected: `{"a":3,"b":2}`},
+		{name: "scalar document", input: `"text"`, expected: `"text"`},
+		{name: "HTML characters are not escaped", input: `{"html": "<a href=\"x\">&</a>"}`, expected: `{"html":"<a href=\"x\">&</a>"}`},
+		{name: "empty containers", input: `{"a": [], "b": {}}`, expected: `{"a":[],"b":{}}`},
+		{name: "invalid JSON", input: `{"a": }`, expectError: true},
+		{name: "trailing data", input: `{"a": 1} {"b": 2}`, expectError: true},
+		{name: "not JSON", input: `hello`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := Decode([]byte(tt.input))
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			encoded, err := Marshal(value)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(encoded))
+		})
+	}
+}
+
+func TestObject_Set(t *testing.T) {
+	object := NewObject()
+	object.Set("z", 1)
+	object.Set("a", 2)
+	object.Set("z", 3)
+
+	require.Equal(t, []string{"z", "a"}, object.Keys)
+	value, ok := object.Get("z")
+	require.True(t, ok)
+	require.Equal(t, 3, value)
+
+	_, ok = object.Get("missing")
+	require.False(t, ok)
+}
@@ -0,0 +1,254 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiKey     = "\x1b[1;34m"
+	ansiString  = "\x1b[32m"
+	ansiNumber  = "\x1b[36m"
+	ansiBoolean = "\x1b[33m"
+	ansiNull    = "\x1b[90m"
+	ansiTag     = "\x1b[34m"
+	ansiAttr    = "\x1b[36m"
+	ansiComment = "\x1b[90m"
+	ansiSuccess = "\x1b[1;32m"
+	ansiRedir   = "\x1b[1;36m"
+	ansiWarning = "\x1b[1;33m"
+	ansiFailure = "\x1b[1;31m"
+	ansiHeader  = "\x1b[36m"
+)
+
+// isTerminal reports whether f is a character device, which is how a
+// terminal looks from the process.
+var isTerminal = func(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// IsTerminal reports whether f is connected to a terminal.
+func IsTerminal(f *os.File) bool {
+	return isTerminal(f)
+}
+
+// ColorEnabled decides whether output written to out is colored. In auto
+// mode colors are only used for terminals and never when NO_COLOR is set,
+// see https://no-color.org.
+func ColorEnabled(mode string, out *os.File) (bool, error) {
+	switch mode {
+	case ColorAlways:
+		return true, nil
+	case ColorNever:
+		return false, nil
+	case ColorAuto:
+		if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+			return false, nil
+		}
+		return isTerminal(out), nil
+	default:
+		return false, fmt.Errorf("unknown color mode %q, expected %s, %s or %s", mode, ColorAuto, ColorAlways, ColorNever)
+	}
+}
+
+func paint(color, text string) string {
+	return color + text + ansiReset
+}
+
+// Highlight colors text rendered in format. JSON, XML, HTML, YAML and the
+// table header are highlighted, raw and csv output are left untouched.
+func Highlight(text, format string) string {
+	if format == FormatRaw || format == FormatCSV {
+		return text
+	}
+
+	// XML and HTML bodies are shown as markup whatever the format.
+	if looksLikeMarkup(text) {
+		return highlightMarkup(text)
+	}
+
+	switch format {
+	case FormatJSON:
+		if json.Valid([]byte(text)) {
+			return highlightJSON(text)
+		}
+	case FormatYAML:
+		return highlightYAML(text)
+	case FormatTable:
+		header, rest, _ := strings.Cut(text, "\n")
+		if rest == "" {
+			return paint(ansiBold, header)
+		}
+		return paint(ansiBold, header) + "\n" + rest
+	}
+
+	return text
+}
+
+func looksLikeMarkup(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), "<")
+}
+
+// highlightJSON colors valid, usually indented, JSON text. Strings followed
+// by a colon are object keys.
+func highlightJSON(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"':
+			end := stringEnd(text, i)
+			color := ansiString
+			if rest := strings.TrimLeft(text[end:], " \t\r\n"); strings.HasPrefix(rest, ":") {
+				color = ansiKey
+			}
+			b.WriteString(paint(color, text[i:end]))
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(text) && strings.IndexByte("0123456789.eE+-", text[end]) >= 0 {
+				end++
+			}
+			b.WriteString(paint(ansiNumber, text[i:end]))
+			i = end
+		case strings.HasPrefix(text[i:], "true"), strings.HasPrefix(text[i:], "false"):
+			end := i + 4
+			if c == 'f' {
+				end++
+			}
+			b.WriteString(paint(ansiBoolean, text[i:end]))
+			i = end
+		case strings.HasPrefix(text[i:], "null"):
+			b.WriteString(paint(ansiNull, "null"))
+			i += 4
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+// stringEnd returns the index right after the string starting at start.
+func stringEnd(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+var markupAttribute = regexp.MustCompile(`([^\s=/>]+)(\s*=\s*("[^"]*"|'[^']*'|[^\s>]+))?`)
+
+// highlightMarkup colors XML and HTML: tag names, attributes and their
+// values, and comments. Text between tags is left as it is.
+func highlightMarkup(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		if text[i] != '<' {
+			next := strings.IndexByte(text[i:], '<')
+			if next < 0 {
+				next = len(text) - i
+			}
+			b.WriteString(text[i : i+next])
+			i += next
+			continue
+		}
+
+		if strings.HasPrefix(text[i:], "<!--") {
+			end := strings.Index(text[i:], "-->")
+			if end < 0 {
+				end = len(text) - i
+			} else {
+				end += len("-->")
+			}
+			b.WriteString(paint(ansiComment, text[i:i+end]))
+			i += end
+			continue
+		}
+
+		end := strings.IndexByte(text[i:], '>')
+		if end < 0 {
+			b.WriteString(text[i:])
+			break
+		}
+		b.WriteString(highlightTag(text[i : i+end+1]))
+		i += end + 1
+	}
+
+	return b.String()
+}
+
+// highlightTag colors a single tag such as <a href="/x"> or </div>.
+func highlightTag(tag string) string {
+	inner := tag[1 : len(tag)-1]
+	prefix := ""
+	for _, p := range []string{"/", "?", "!"} {
+		if strings.HasPrefix(inner, p) {
+			prefix, inner = p, inner[1:]
+			break
+		}
+	}
+
+	suffix := ""
+	for _, s := range []string{"/", "?"} {
+		if strings.HasSuffix(inner, s) {
+			suffix, inner = s, inner[:len(inner)-1]
+			break
+		}
+	}
+
+	nameEnd := strings.IndexAny(inner, " \t\r\n")
+	if nameEnd < 0 {
+		nameEnd = len(inner)
+	}
+	name, attributes := inner[:nameEnd], inner[nameEnd:]
+
+	attributes = markupAttribute.ReplaceAllStringFunc(attributes, func(attribute string) string {
+		key, value, found := strings.Cut(attribute, "=")
+		if !found {
+			return paint(ansiAttr, attribute)
+		}
+		return paint(ansiAttr, key) + "=" + paint(ansiString, value)
+	})
+
+	return "<" + prefix + paint(ansiTag, name) + attributes + suffix + ">"
+}
+
+var yamlKey = regexp.MustCompile(`(?m)^(\s*(?:- )*)([^\s:#'"-][^:#]*?|"[^"]*"|'[^']*'):( |$)`)
+
+func highlightYAML(text string) string {
+	return yamlKey.ReplaceAllString(text, "${1}"+ansiKey+"${2}"+ansiReset+":${3}")
+}
+
+// statusColor picks the color of a status line by class: success,
+// redirection, client and server errors.
+func statusColor(statusCode int) string {
+	switch {
+	case statusCode >= 500:
+		return ansiFailure
+	case statusCode >= 400:
+		return ansiWarning
+	case statusCode >= 300:
+		return ansiRedir
+	default:
+		return ansiSuccess
+	}
+}
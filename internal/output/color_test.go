@@ -0,0 +1,130 @@
+package output
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// visible replaces the escape sequences with readable markers. Some
+// colors are shared, numbers, attributes and header names are all cyan.
+func visible(text string) string {
+	return strings.NewReplacer(
+		ansiReset, "</>",
+		ansiBold, "<bold>",
+		ansiKey, "<key>",
+		ansiString, "<str>",
+		ansiNumber, "<cyan>",
+		ansiBoolean, "<bool>",
+		ansiNull, "<gray>",
+		ansiTag, "<tag>",
+		ansiSuccess, "<ok>",
+		ansiRedir, "<redir>",
+		ansiWarning, "<warn>",
+		ansiFailure, "<fail>",
+	).Replace(text)
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		noColor  string
+		term     string
+		terminal bool
+		expected bool
+	}{
+		{name: "auto on a terminal", mode: ColorAuto, term: "xterm", terminal: true, expected: true},
+		{name: "auto when piped", mode: ColorAuto, term: "xterm", terminal: false, expected: false},
+		{name: "auto with NO_COLOR", mode: ColorAuto, noColor: "1", term: "xterm", terminal: true, expected: false},
+		{name: "auto on a dumb terminal", mode: ColorAuto, term: "dumb", terminal: true, expected: false},
+		{name: "always wins over NO_COLOR and pipes", mode: ColorAlways, noColor: "1", terminal: false, expected: true},
+		{name: "never on a terminal", mode: ColorNever, term: "xterm", terminal: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("TERM", tt.term)
+
+			originalIsTerminal := isTerminal
+			isTerminal = func(*os.File) bool { return tt.terminal }
+			defer func() {
+				isTerminal = originalIsTerminal
+			}()
+
+			enabled, err := ColorEnabled(tt.mode, os.Stdout)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, enabled)
+		})
+	}
+
+	_, err := ColorEnabled("sometimes", os.Stdout)
+	require.EqualError(t, err, `unknown color mode "sometimes", expected auto, always or never`)
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		format   string
+		expected string
+	}{
+		{
+			name:     "json keys and scalars",
+			text:     "{\n  \"id\": -1.5e3,\n  \"name\": \"a \\\"b\\\": c\",\n  \"ok\": true,\n  \"off\": false,\n  \"none\": null\n}",
+			format:   FormatJSON,
+			expected: "{\n  <key>\"id\"</>: <cyan>-1.5e3</>,\n  <key>\"name\"</>: <str>\"a \\\"b\\\": c\"</>,\n  <key>\"ok\"</>: <bool>true</>,\n  <key>\"off\"</>: <bool>false</>,\n  <key>\"none\"</>: <gray>null</>\n}",
+		},
+		{
+			name:     "json arrays",
+			text:     `["a", 1]`,
+			format:   FormatJSON,
+			expected: `[<str>"a"</>, <cyan>1</>]`,
+		},
+		{
+			name:     "html markup",
+			text:     `<!-- c --><a href="/x" hidden>link</a><br/>`,
+			format:   FormatJSON,
+			expected: `<gray><!-- c --></><<tag>a</> <cyan>href</>=<str>"/x"</> <cyan>hidden</>>link</<tag>a</>><<tag>br</>/>`,
+		},
+		{
+			name:     "xml declaration",
+			text:     `<?xml version="1.0"?><root/>`,
+			format:   FormatJSON,
+			expected: `<?<tag>xml</> <cyan>version</>=<str>"1.0"</>?><<tag>root</>/>`,
+		},
+		{
+			name:     "plain text is left alone",
+			text:     "hello: world",
+			format:   FormatJSON,
+			expected: "hello: world",
+		},
+		{
+			name:     "yaml keys",
+			text:     "name: ana\nitems:\n  - id: 1\n    url: http://x\n\"quoted key\": 2",
+			format:   FormatYAML,
+			expected: "<key>name</>: ana\n<key>items</>:\n  - <key>id</>: 1\n    <key>url</>: http://x\n<key>\"quoted key\"</>: 2",
+		},
+		{
+			name:     "table header",
+			text:     "ID  NAME\n1   ana",
+			format:   FormatTable,
+			expected: "<bold>ID  NAME</>\n1   ana",
+		},
+		{
+			name:     "raw is never colored",
+			text:     `{"id": 1}`,
+			format:   FormatRaw,
+			expected: `{"id": 1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, visible(Highlight(tt.text, tt.format)))
+		})
+	}
+}
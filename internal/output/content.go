@@ -0,0 +1,197 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf16"
+)
+
+type mediaKind int
+
+const (
+	mediaText mediaKind = iota
+	mediaJSON
+	mediaXML
+	mediaHTML
+	mediaBinary
+)
+
+// textTypes are application types that are read as plain text.
+var textTypes = []string{
+	"application/javascript",
+	"application/ecmascript",
+	"application/x-www-form-urlencoded",
+	"application/yaml",
+	"application/x-yaml",
+	"application/toml",
+	"application/graphql",
+	"application/sql",
+	"application/x-ndjson",
+}
+
+// mediaKindOf tells how a body is shown from its Content-Type, looking at
+// the body itself only when the type is missing or says nothing useful.
+func mediaKindOf(mediaType string, body []byte) mediaKind {
+	switch {
+	case mediaType == "":
+		return sniffMediaKind(body)
+	case mediaType == "text/html", mediaType == "application/xhtml+xml":
+		return mediaHTML
+	case strings.HasSuffix(mediaType, "/json"), strings.HasSuffix(mediaType, "+json"):
+		return mediaJSON
+	case strings.HasSuffix(mediaType, "/xml"), strings.HasSuffix(mediaType, "+xml"):
+		return mediaXML
+	case strings.HasPrefix(mediaType, "text/"):
+		return mediaText
+	case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"), strings.HasPrefix(mediaType, "font/"),
+		mediaType == "application/pdf", mediaType == "application/zip", mediaType == "application/gzip":
+		return mediaBinary
+	}
+
+	for _, t := range textTypes {
+		if mediaType == t {
+			return mediaText
+		}
+	}
+
+	// application/octet-stream and types we do not know about.
+	if sniffMediaKind(body) == mediaBinary {
+		return mediaBinary
+	}
+	return mediaText
+}
+
+func sniffMediaKind(body []byte) mediaKind {
+	if json.Valid(body) {
+		return mediaJSON
+	}
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(body))
+	switch {
+	case sniffed == "text/html":
+		return mediaHTML
+	case sniffed == "text/xml":
+		return mediaXML
+	case strings.HasPrefix(sniffed, "text/"):
+		return mediaText
+	default:
+		return mediaBinary
+	}
+}
+
+// Text decodes a body to UTF-8 following the charset of its Content-Type,
+// or its byte order mark. A charset that cannot be decoded is reported and
+// the body is used as it is.
+func Text(body []byte, contentType string) string {
+	_, params, _ := mime.ParseMediaType(contentType)
+	charset := params["charset"]
+	if charset == "" && (bytes.HasPrefix(body, utf16BEBOM) || bytes.HasPrefix(body, utf16LEBOM)) {
+		charset = "utf-16"
+	}
+
+	text, err := decodeCharset(body, charset)
+	if err != nil {
+		fmt.Fprintf(warningOutput, "[warning] %v, showing the body as it is\n", err)
+		return string(body)
+	}
+	return text
+}
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16BEBOM = []byte{0xfe, 0xff}
+	utf16LEBOM = []byte{0xff, 0xfe}
+)
+
+func decodeCharset(body []byte, charset string) (string, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return string(bytes.TrimPrefix(body, utf8BOM)), nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "l1":
+		return decodeSingleByte(body, nil), nil
+	case "windows-1252", "cp1252":
+		return decodeSingleByte(body, &windows1252), nil
+	case "utf-16":
+		// Without a byte order mark UTF-16 is big endian, see RFC 2781.
+		if bytes.HasPrefix(body, utf16LEBOM) {
+			return decodeUTF16(body[2:], false)
+		}
+		return decodeUTF16(bytes.TrimPrefix(body, utf16BEBOM), true)
+	case "utf-16be":
+		return decodeUTF16(bytes.TrimPrefix(body, utf16BEBOM), true)
+	case "utf-16le":
+		return decodeUTF16(bytes.TrimPrefix(body, utf16LEBOM), false)
+	default:
+		return "", fmt.Errorf("unsupported charset %q", charset)
+	}
+}
+
+// windows1252 maps the bytes 0x80 to 0x9f, where Windows-1252 differs from
+// ISO-8859-1.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8d, 'Ž', 0x8f,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9d, 'ž', 'Ÿ',
+}
+
+// decodeSingleByte decodes ISO-8859-1, where each byte is the code point,
+// with the optional 0x80 to 0x9f replacements of Windows-1252.
+func decodeSingleByte(body []byte, high *[32]rune) string {
+	var b strings.Builder
+	b.Grow(len(body))
+	for _, c := range body {
+		if high != nil && c >= 0x80 && c <= 0x9f {
+			b.WriteRune(high[c-0x80])
+			continue
+		}
+		b.WriteRune(rune(c))
+	}
+	return b.String()
+}
+
+func decodeUTF16(body []byte, bigEndian bool) (string, error) {
+	if len(body)%2 != 0 {
+		return "", errors.New("truncated UTF-16 body")
+	}
+
+	units := make([]uint16, len(body)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(body[2*i])<<8 | uint16(body[2*i+1])
+		} else {
+			units[i] = uint16(body[2*i+1])<<8 | uint16(body[2*i])
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range utf16.Decode(units) {
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
+
+// binarySummary replaces a body that would garble the terminal.
+func binarySummary(mediaType string, size int) string {
+	if mediaType == "" {
+		mediaType = "binary data"
+	}
+	return fmt.Sprintf("[%s, %s not shown, use -output-file or -download to save it, or -output raw to print it]",
+		mediaType, FormatSize(int64(size)))
+}
+
+// FormatSize prints a byte count the way people read it.
+func FormatSize(size int64) string {
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%d bytes", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KiB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1024*1024))
+	}
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMediaKindOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		body      string
+		expected  mediaKind
+	}{
+		{name: "json", mediaType: "application/json", expected: mediaJSON},
+		{name: "json suffix", mediaType: "application/vnd.api+json", expected: mediaJSON},
+		{name: "xml", mediaType: "text/xml", expected: mediaXML},
+		{name: "xml suffix", mediaType: "image/svg+xml", expected: mediaXML},
+		{name: "html", mediaType: "text/html", expected: mediaHTML},
+		{name: "xhtml", mediaType: "application/xhtml+xml", expected: mediaHTML},
+		{name: "text", mediaType: "text/csv", expected: mediaText},
+		{name: "text application type", mediaType: "application/javascript", expected: mediaText},
+		{name: "image", mediaType: "image/png", body: "hello", expected: mediaBinary},
+		{name: "pdf", mediaType: "application/pdf", body: "%PDF-1.7", expected: mediaBinary},
+		{name: "octet stream holding text", mediaType: "application/octet-stream", body: "hello", expected: mediaText},
+		{name: "octet stream holding bytes", mediaType: "application/octet-stream", body: "\x00\xff", expected: mediaBinary},
+		{name: "sniffed json", body: `[1, 2]`, expected: mediaJSON},
+		{name: "sniffed html", body: "<!DOCTYPE html><html></html>", expected: mediaHTML},
+		{name: "sniffed xml", body: `<?xml version="1.0"?><a/>`, expected: mediaXML},
+		{name: "sniffed text", body: "hello", expected: mediaText},
+		{name: "sniffed binary", body: "\x00\x01", expected: mediaBinary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, mediaKindOf(tt.mediaType, []byte(tt.body)))
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		contentType     string
+		expected        string
+		expectedWarning string
+	}{
+		{name: "utf-8 byte order mark is dropped", body: "\xef\xbb\xbfhi", contentType: "text/plain", expected: "hi"},
+		{name: "latin1", body: "na\xefve", contentType: "text/plain; charset=latin1", expected: "naïve"},
+		{name: "windows-1252", body: "\x93hi\x94 \x80", contentType: "text/plain; charset=windows-1252", expected: "“hi” €"},
+		{name: "utf-16 big endian by default", body: "\x00h\x00i", contentType: "text/plain; charset=utf-16", expected: "hi"},
+		{name: "utf-16 little endian mark", body: "\xff\xfeh\x00i\x00", contentType: "text/plain; charset=UTF-16", expected: "hi"},
+		{name: "utf-16le with a surrogate pair", body: "\x3d\xd8\x00\xde", contentType: "application/json; charset=utf-16le", expected: "😀"},
+		{name: "utf-16 detected from the mark", body: "\xfe\xff\x00h", contentType: "text/plain", expected: "h"},
+		{
+			name:            "unsupported charset",
+			body:            "hi",
+			contentType:     "text/plain; charset=koi8-r",
+			expected:        "hi",
+			expectedWarning: "[warning] unsupported charset \"koi8-r\", showing the body as it is\n",
+		},
+		{
+			name:            "truncated utf-16",
+			body:            "\x00h\x00",
+			contentType:     "text/plain; charset=utf-16be",
+			expected:        "\x00h\x00",
+			expectedWarning: "[warning] truncated UTF-16 body, showing the body as it is\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := stubWarnings(t)
+			require.Equal(t, tt.expected, Text([]byte(tt.body), tt.contentType))
+			require.Equal(t, tt.expectedWarning, warnings.String())
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	require.Equal(t, "512 bytes", FormatSize(512))
+	require.Equal(t, "1.5 KiB", FormatSize(1536))
+	require.Equal(t, "2.0 MiB", FormatSize(2*1024*1024))
+}
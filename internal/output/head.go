@@ -0,0 +1,33 @@
+package output
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Head renders the status line and the headers of a response, sorted by
+// name, the way curl -i shows them.
+func Head(proto, status string, statusCode int, header http.Header, color bool) string {
+	var b strings.Builder
+
+	statusLine := strings.TrimSpace(proto + " " + status)
+	if color {
+		statusLine = paint(statusColor(statusCode), statusLine)
+	}
+	b.WriteString(statusLine + "\n")
+
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[name] {
+			if color {
+				fmt.Fprintf(&b, "%s: %s\n", paint(ansiHeader, name), value)
+				continue
+			}
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHead(t *testing.T) {
+	header := http.Header{
+		"Content-Type": {"application/json"},
+		"Set-Cookie":   {"a=1", "b=2"},
+		"Age":          {"3"},
+	}
+
+	require.Equal(t, ""+
+		"HTTP/1.1 200 OK\n"+
+		"Age: 3\n"+
+		"Content-Type: application/json\n"+
+		"Set-Cookie: a=1\n"+
+		"Set-Cookie: b=2\n", Head("HTTP/1.1", "200 OK", http.StatusOK, header, false))
+
+	require.Equal(t, ""+
+		"<fail>HTTP/2.0 503 Service Unavailable</>\n"+
+		"<cyan>Age</>: 3\n", visible(Head("HTTP/2.0", "503 Service Unavailable", 503, http.Header{"Age": {"3"}}, true)))
+}
+
+func TestStatusColor(t *testing.T) {
+	require.Equal(t, ansiSuccess, statusColor(http.StatusCreated))
+	require.Equal(t, ansiRedir, statusColor(http.StatusFound))
+	require.Equal(t, ansiWarning, statusColor(http.StatusNotFound))
+	require.Equal(t, ansiFailure, statusColor(http.StatusBadGateway))
+}
@@ -0,0 +1,154 @@
+package output
+
+import (
+	"strings"
+)
+
+type markupToken struct {
+	text string
+	// kind is one of '<' for an opening tag, '/' for a closing tag, 'e' for
+	// an element without content, and 't' for text, comments and
+	// declarations.
+	kind byte
+	name string
+}
+
+// voidElements are the HTML elements that never have a closing tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawElements are the HTML elements whose content is kept as it is.
+var rawElements = map[string]bool{"script": true, "style": true, "pre": true, "textarea": true}
+
+// indentMarkup puts each element of an XML or HTML document on its own
+// line, indented by depth. Elements holding only text stay on one line.
+// The document is not validated, unbalanced tags only affect indentation.
+func indentMarkup(text string, html bool) string {
+	tokens := tokenizeMarkup(text, html)
+
+	var lines []string
+	depth := 0
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		indent := strings.Repeat("  ", depth)
+
+		switch token.kind {
+		case '<':
+			// <a>text</a> and <a></a> are kept together.
+			if i+2 < len(tokens) && tokens[i+1].kind == 't' && !strings.HasPrefix(tokens[i+1].text, "<") &&
+				tokens[i+2].kind == '/' && tokens[i+2].name == token.name {
+				lines = append(lines, indent+token.text+tokens[i+1].text+tokens[i+2].text)
+				i += 2
+				continue
+			}
+			if i+1 < len(tokens) && tokens[i+1].kind == '/' && tokens[i+1].name == token.name {
+				lines = append(lines, indent+token.text+tokens[i+1].text)
+				i++
+				continue
+			}
+			lines = append(lines, indent+token.text)
+			depth++
+		case '/':
+			depth = max(0, depth-1)
+			lines = append(lines, strings.Repeat("  ", depth)+token.text)
+		default:
+			lines = append(lines, indent+token.text)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func tokenizeMarkup(text string, html bool) []markupToken {
+	var tokens []markupToken
+	for i := 0; i < len(text); {
+		if text[i] != '<' {
+			end := strings.IndexByte(text[i:], '<')
+			if end < 0 {
+				end = len(text) - i
+			}
+			if content := strings.TrimSpace(text[i : i+end]); content != "" {
+				tokens = append(tokens, markupToken{text: content, kind: 't'})
+			}
+			i += end
+			continue
+		}
+
+		var end int
+		switch {
+		case strings.HasPrefix(text[i:], "<!--"):
+			end = untilAfter(text, i, "-->")
+		case strings.HasPrefix(text[i:], "<![CDATA["):
+			end = untilAfter(text, i, "]]>")
+		default:
+			end = tagEnd(text, i)
+		}
+		tag := text[i:end]
+		i = end
+
+		name := tagName(tag)
+		switch {
+		case strings.HasPrefix(tag, "</"):
+			tokens = append(tokens, markupToken{text: tag, kind: '/', name: name})
+		case strings.HasPrefix(tag, "<!"), strings.HasPrefix(tag, "<?"):
+			tokens = append(tokens, markupToken{text: tag, kind: 't'})
+		case strings.HasSuffix(tag, "/>"), html && voidElements[strings.ToLower(name)]:
+			tokens = append(tokens, markupToken{text: tag, kind: 'e', name: name})
+		case html && rawElements[strings.ToLower(name)]:
+			// The content and the closing tag are copied untouched.
+			closing := strings.Index(strings.ToLower(text[i:]), "</"+strings.ToLower(name))
+			if closing < 0 {
+				closing = len(text) - i
+			}
+			closeEnd := i + closing
+			if closeEnd < len(text) {
+				closeEnd = tagEnd(text, closeEnd)
+			}
+			tokens = append(tokens, markupToken{text: tag + text[i:closeEnd], kind: 'e', name: name})
+			i = closeEnd
+		default:
+			tokens = append(tokens, markupToken{text: tag, kind: '<', name: name})
+		}
+	}
+
+	return tokens
+}
+
+// untilAfter returns the index right after the first delimiter found from
+// start, or the end of text.
+func untilAfter(text string, start int, delimiter string) int {
+	end := strings.Index(text[start:], delimiter)
+	if end < 0 {
+		return len(text)
+	}
+	return start + end + len(delimiter)
+}
+
+// tagEnd returns the index right after the tag starting at start, skipping
+// any > inside quoted attribute values.
+func tagEnd(text string, start int) int {
+	var quote byte
+	for i := start + 1; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+func tagName(tag string) string {
+	name := strings.TrimLeft(tag, "</?!")
+	if end := strings.IndexAny(name, " \t\r\n/>"); end >= 0 {
+		name = name[:end]
+	}
+	return name
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndentMarkup(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		html     bool
+		expected string
+	}{
+		{
+			name:     "nested elements",
+			text:     "<a>\n  <b><c>1</c><c/></b>\n</a>",
+			expected: "<a>\n  <b>\n    <c>1</c>\n    <c/>\n  </b>\n</a>",
+		},
+		{
+			name:     "empty elements stay on one line",
+			text:     `<a><b x="1"></b></a>`,
+			expected: "<a>\n  <b x=\"1\"></b>\n</a>",
+		},
+		{
+			name:     "comments, cdata and quoted >",
+			text:     `<a><!-- <b> --><![CDATA[<x>]]><c title="a > b">t</c></a>`,
+			expected: "<a>\n  <!-- <b> -->\n  <![CDATA[<x>]]>\n  <c title=\"a > b\">t</c>\n</a>",
+		},
+		{
+			name:     "html void and raw elements",
+			text:     "<!DOCTYPE html><head><meta charset=utf-8><script>if (a<b) {}</script></head>",
+			html:     true,
+			expected: "<!DOCTYPE html>\n<head>\n  <meta charset=utf-8>\n  <script>if (a<b) {}</script>\n</head>",
+		},
+		{
+			name:     "pre keeps its whitespace",
+			text:     "<body><PRE>  a\n  b</PRE></body>",
+			html:     true,
+			expected: "<body>\n  <PRE>  a\n  b</PRE>\n</body>",
+		},
+		{
+			name:     "unbalanced closing tags",
+			text:     "</x><a>t</a></y>",
+			expected: "</x>\n<a>t</a>\n</y>",
+		},
+		{
+			name:     "unterminated tag",
+			text:     "<a><b",
+			expected: "<a>\n  <b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, indentMarkup(tt.text, tt.html))
+		})
+	}
+}
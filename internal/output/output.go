@@ -0,0 +1,131 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"strings"
+
+	"github.com/ashttp/internal/jsonvalue"
+)
+
+const (
+	FormatRaw   = "raw"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTable = "table"
+	FormatCSV   = "csv"
+)
+
+var Formats = []string{FormatRaw, FormatJSON, FormatYAML, FormatTable, FormatCSV}
+
+var warningOutput io.Writer = os.Stderr
+
+// Render formats a response body according to its Content-Type. JSON goes
+// through format, XML and HTML are indented, text is printed as it is and
+// binary bodies are summarized instead of garbling the terminal.
+func Render(body []byte, contentType, format string, columns []string) (string, error) {
+	if err := ValidateFormat(format); err != nil {
+		return "", err
+	}
+
+	if format == FormatRaw {
+		return string(body), nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	kind := mediaKindOf(mediaType, body)
+	if kind == mediaBinary {
+		return binarySummary(mediaType, len(body)), nil
+	}
+
+	text := Text(body, contentType)
+	switch kind {
+	case mediaJSON:
+		value, err := jsonvalue.Decode([]byte(text))
+		if err != nil {
+			return text, nil
+		}
+		return RenderValue(value, format, columns)
+	case mediaXML:
+		return indentMarkup(text, false), nil
+	case mediaHTML:
+		return indentMarkup(text, true), nil
+	default:
+		return text, nil
+	}
+}
+
+// RenderValues formats the results of a filter. Table and csv lay them out
+// together as rows, the other formats print one result after the other.
+func RenderValues(values []any, format string, columns []string) (string, error) {
+	if (format == FormatTable || format == FormatCSV) && len(values) != 1 {
+		return RenderValue(append([]any{}, values...), format, columns)
+	}
+
+	rendered := make([]string, len(values))
+	for i, value := range values {
+		var err error
+		if rendered[i], err = RenderValue(value, format, columns); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.Join(rendered, "\n"), nil
+}
+
+// RenderValue formats a value decoded by jsonvalue.Decode.
+func RenderValue(value any, format string, columns []string) (string, error) {
+	switch format {
+	case FormatRaw:
+		// Strings are printed without quotes so they can be used in scripts.
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		data, err := jsonvalue.Marshal(value)
+		return string(data), err
+	case FormatJSON:
+		return renderJSON(value)
+	case FormatYAML:
+		return renderYAML(value)
+	case FormatTable, FormatCSV:
+		rows, err := newRows(value, columns)
+		if err != nil {
+			fmt.Fprintf(warningOutput, "[warning] %v, showing json instead\n", err)
+			return renderJSON(value)
+		}
+		if format == FormatTable {
+			return renderTable(rows)
+		}
+		return renderCSV(rows)
+	default:
+		return "", ValidateFormat(format)
+	}
+}
+
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown output format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+func renderJSON(value any) (string, error) {
+	data, err := jsonvalue.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		return "", err
+	}
+
+	return indented.String(), nil
+}
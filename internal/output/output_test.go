@@ -0,0 +1,184 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ashttp/internal/jsonvalue"
+	"github.com/stretchr/testify/require"
+)
+
+func stubWarnings(t *testing.T) *bytes.Buffer {
+	var warnings bytes.Buffer
+	originalOutput := warningOutput
+	warningOutput = &warnings
+	t.Cleanup(func() {
+		warningOutput = originalOutput
+	})
+	return &warnings
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		contentType     string
+		format          string
+		columns         []string
+		expected        string
+		expectedWarning string
+		expectError     string
+	}{
+		{
+			name:     "json is indented keeping the key order",
+			body:     `{"name":"ana","id":1,"tags":["a","<b>"]}`,
+			format:   FormatJSON,
+			expected: "{\n  \"name\": \"ana\",\n  \"id\": 1,\n  \"tags\": [\n    \"a\",\n    \"<b>\"\n  ]\n}",
+		},
+		{
+			name:     "raw keeps the body untouched",
+			body:     `{"name": "ana"}`,
+			format:   FormatRaw,
+			expected: `{"name": "ana"}`,
+		},
+		{
+			name:     "body that is not JSON is printed as it is",
+			body:     "<html></html>",
+			format:   FormatTable,
+			expected: "<html></html>",
+		},
+		{
+			name:        "json suffix types are indented",
+			body:        `{"type":"about:blank","status":404}`,
+			contentType: "application/problem+json",
+			format:      FormatJSON,
+			expected:    "{\n  \"type\": \"about:blank\",\n  \"status\": 404\n}",
+		},
+		{
+			name:        "json in a text type is printed as it is",
+			body:        `{"id":1}`,
+			contentType: "text/plain",
+			format:      FormatJSON,
+			expected:    `{"id":1}`,
+		},
+		{
+			name:        "invalid json is printed as it is",
+			body:        `{"id":`,
+			contentType: "application/json",
+			format:      FormatJSON,
+			expected:    `{"id":`,
+		},
+		{
+			name:        "xml is indented",
+			body:        `<?xml version="1.0"?><user id="1"><name>ana</name><tags><tag>a</tag></tags></user>`,
+			contentType: "application/xml",
+			format:      FormatJSON,
+			expected:    "<?xml version=\"1.0\"?>\n<user id=\"1\">\n  <name>ana</name>\n  <tags>\n    <tag>a</tag>\n  </tags>\n</user>",
+		},
+		{
+			name:        "html is indented",
+			body:        "<html><body><p>hi<br>there</p></body></html>",
+			contentType: "text/html; charset=utf-8",
+			format:      FormatJSON,
+			expected:    "<html>\n  <body>\n    <p>\n      hi\n      <br>\n      there\n    </p>\n  </body>\n</html>",
+		},
+		{
+			name:        "latin1 text is decoded",
+			body:        "caf\xe9",
+			contentType: "text/plain; charset=ISO-8859-1",
+			format:      FormatJSON,
+			expected:    "café",
+		},
+		{
+			name:        "binary types are summarized",
+			body:        "\x89PNG\r\n\x1a\n",
+			contentType: "image/png",
+			format:      FormatJSON,
+			expected:    "[image/png, 8 bytes not shown, use -output-file or -download to save it, or -output raw to print it]",
+		},
+		{
+			name:     "binary bodies without a type are summarized",
+			body:     "\x00\x01\x02",
+			format:   FormatYAML,
+			expected: "[binary data, 3 bytes not shown, use -output-file or -download to save it, or -output raw to print it]",
+		},
+		{
+			name:        "raw keeps binary bodies",
+			body:        "\x00\x01",
+			contentType: "application/octet-stream",
+			format:      FormatRaw,
+			expected:    "\x00\x01",
+		},
+		{
+			name:     "table from an array of objects",
+			body:     `[{"id":1,"name":"ana"},{"id":2,"name":"bob","admin":true}]`,
+			format:   FormatTable,
+			expected: "ID  NAME  ADMIN\n1   ana   \n2   bob   true",
+		},
+		{
+			name:     "csv with selected nested columns",
+			body:     `[{"id":1,"owner":{"login":"ana"}},{"id":2,"owner":{"login":"b,ob"}}]`,
+			format:   FormatCSV,
+			columns:  []string{"owner.login", "id"},
+			expected: "owner.login,id\nana,1\n\"b,ob\",2",
+		},
+		{
+			name:            "unsupported shape falls back to json",
+			body:            `"just a string"`,
+			format:          FormatCSV,
+			expected:        `"just a string"`,
+			expectedWarning: "[warning] a string cannot be shown as rows, showing json instead\n",
+		},
+		{
+			name:        "unknown format",
+			body:        `{}`,
+			format:      "xml",
+			expectError: `unknown output format "xml", expected one of raw, json, yaml, table, csv`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := stubWarnings(t)
+
+			output, err := Render([]byte(tt.body), tt.contentType, tt.format, tt.columns)
+			if tt.expectError != "" {
+				require.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, output)
+			require.Equal(t, tt.expectedWarning, warnings.String())
+		})
+	}
+}
+
+func TestRenderValues(t *testing.T) {
+	first := jsonvalue.NewObject()
+	first.Set("id", json.Number("1"))
+	second := jsonvalue.NewObject()
+	second.Set("id", json.Number("2"))
+
+	tests := []struct {
+		name     string
+		values   []any
+		format   string
+		expected string
+	}{
+		{name: "json prints one result after the other", values: []any{first, "text"}, format: FormatJSON, expected: "{\n  \"id\": 1\n}\n\"text\""},
+		{name: "raw prints strings without quotes", values: []any{"a b", json.Number("2"), nil}, format: FormatRaw, expected: "a b\n2\nnull"},
+		{name: "table gathers the results as rows", values: []any{first, second}, format: FormatTable, expected: "ID\n1\n2"},
+		{name: "csv with a single result", values: []any{[]any{first}}, format: FormatCSV, expected: "id\n1"},
+		{name: "no results", values: nil, format: FormatJSON, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := RenderValues(tt.values, tt.format, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, output)
+		})
+	}
+}
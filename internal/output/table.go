@@ -0,0 +1,186 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ashttp/internal/jsonvalue"
+)
+
+// valueColumn names the only column of a table built from an array of
+// scalars.
+const valueColumn = "value"
+
+type rows struct {
+	columns []string
+	cells   [][]string
+}
+
+// newRows lays out an array of objects as rows, one column per key in the
+// order they first appear unless columns are given. Columns may reach
+// nested fields with dots, e.g. "owner.login". A single object is a
+// one-row table and an array of scalars a single "value" column.
+func newRows(value any, columns []string) (rows, error) {
+	var items []any
+	switch v := value.(type) {
+	case []any:
+		items = v
+	case *jsonvalue.Object:
+		items = []any{v}
+	default:
+		return rows{}, fmt.Errorf("a %s cannot be shown as rows", kind(value))
+	}
+
+	objects, scalars := 0, 0
+	for _, item := range items {
+		switch item.(type) {
+		case *jsonvalue.Object:
+			objects++
+		case []any:
+			return rows{}, fmt.Errorf("an array of arrays cannot be shown as rows")
+		default:
+			scalars++
+		}
+	}
+	if objects > 0 && scalars > 0 {
+		return rows{}, fmt.Errorf("an array mixing objects and scalars cannot be shown as rows")
+	}
+
+	if scalars > 0 {
+		r := rows{columns: []string{valueColumn}}
+		for _, item := range items {
+			r.cells = append(r.cells, []string{cell(item)})
+		}
+		return r, nil
+	}
+
+	if len(columns) == 0 {
+		columns = keys(items)
+	}
+
+	r := rows{columns: columns}
+	for _, item := range items {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = cell(lookup(item.(*jsonvalue.Object), column))
+		}
+		r.cells = append(r.cells, row)
+	}
+
+	return r, nil
+}
+
+func keys(objects []any) []string {
+	var columns []string
+	seen := map[string]bool{}
+	for _, item := range objects {
+		for _, key := range item.(*jsonvalue.Object).Keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	return columns
+}
+
+// lookup follows a dotted path through nested objects. A key containing
+// the whole path wins over nested objects.
+func lookup(object *jsonvalue.Object, path string) any {
+	if value, ok := object.Get(path); ok {
+		return value
+	}
+
+	head, rest, found := strings.Cut(path, ".")
+	if !found {
+		return nil
+	}
+
+	nested, ok := object.Values[head].(*jsonvalue.Object)
+	if !ok {
+		return nil
+	}
+
+	return lookup(nested, rest)
+}
+
+// cell prints scalars as they are and nested values as compact JSON.
+func cell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		data, err := jsonvalue.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+func kind(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func renderTable(r rows) (string, error) {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, strings.Join(upper(r.columns), "\t"))
+	for _, row := range r.cells {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			// Tabs and newlines would break the alignment.
+			cells[i] = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(c)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func upper(columns []string) []string {
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = strings.ToUpper(column)
+	}
+	return header
+}
+
+func renderCSV(r rows) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+
+	if err := w.Write(r.columns); err != nil {
+		return "", err
+	}
+	if err := w.WriteAll(r.cells); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
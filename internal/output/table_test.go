@@ -0,0 +1,118 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/ashttp/internal/jsonvalue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRows(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		columns     []string
+		expected    rows
+		expectError string
+	}{
+		{
+			name: "columns follow the order keys first appear",
+			body: `[{"b":1,"a":2},{"c":3,"a":4}]`,
+			expected: rows{
+				columns: []string{"b", "a", "c"},
+				cells:   [][]string{{"1", "2", ""}, {"", "4", "3"}},
+			},
+		},
+		{
+			name:    "selected columns, nested and missing ones",
+			body:    `[{"id":1,"owner":{"login":"ana"},"tags":["x"]}]`,
+			columns: []string{"owner.login", "tags", "owner.missing", "id"},
+			expected: rows{
+				columns: []string{"owner.login", "tags", "owner.missing", "id"},
+				cells:   [][]string{{"ana", `["x"]`, "", "1"}},
+			},
+		},
+		{
+			name:    "key with a dot wins over nested lookup",
+			body:    `[{"a.b":"flat","a":{"b":"nested"}}]`,
+			columns: []string{"a.b"},
+			expected: rows{
+				columns: []string{"a.b"},
+				cells:   [][]string{{"flat"}},
+			},
+		},
+		{
+			name: "single object is a single row",
+			body: `{"id":1,"meta":{"page":2}}`,
+			expected: rows{
+				columns: []string{"id", "meta"},
+				cells:   [][]string{{"1", `{"page":2}`}},
+			},
+		},
+		{
+			name: "array of scalars",
+			body: `["a", 2, null]`,
+			expected: rows{
+				columns: []string{"value"},
+				cells:   [][]string{{"a"}, {"2"}, {""}},
+			},
+		},
+		{
+			name:     "empty array",
+			body:     `[]`,
+			expected: rows{},
+		},
+		{
+			name:        "mixed array",
+			body:        `[{"id":1}, 2]`,
+			expectError: "an array mixing objects and scalars cannot be shown as rows",
+		},
+		{
+			name:        "array of arrays",
+			body:        `[[1, 2]]`,
+			expectError: "an array of arrays cannot be shown as rows",
+		},
+		{
+			name:        "number",
+			body:        `42`,
+			expectError: "a number cannot be shown as rows",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := jsonvalue.Decode([]byte(tt.body))
+			require.NoError(t, err)
+
+			r, err := newRows(value, tt.columns)
+			if tt.expectError != "" {
+				require.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, r)
+		})
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	output, err := renderTable(rows{
+		columns: []string{"id", "description"},
+		cells:   [][]string{{"1", "multi\nline"}, {"200", "tab\there"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ""+
+		"ID   DESCRIPTION\n"+
+		"1    multi line\n"+
+		"200  tab here", output)
+}
+
+func TestRenderCSV(t *testing.T) {
+	output, err := renderCSV(rows{
+		columns: []string{"id", "note"},
+		cells:   [][]string{{"1", `say "hi"`}, {"2", "multi\nline"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "id,note\n1,\"say \"\"hi\"\"\"\n2,\"multi\nline\"", output)
+}
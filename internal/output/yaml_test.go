@@ -0,0 +1,60 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/ashttp/internal/jsonvalue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name: "nested objects keep their key order",
+			body: `{"name":"ana","id":7,"score":1.50,"active":true,"manager":null,"address":{"city":"Lisbon"}}`,
+			expected: "" +
+				"name: ana\n" +
+				"id: 7\n" +
+				"score: 1.50\n" +
+				"active: true\n" +
+				"manager: null\n" +
+				"address:\n" +
+				"  city: Lisbon",
+		},
+		{
+			name: "arrays of objects",
+			body: `[{"id":1},{"id":2,"tags":["a","b"]}]`,
+			expected: "" +
+				"- id: 1\n" +
+				"- id: 2\n" +
+				"  tags:\n" +
+				"    - a\n" +
+				"    - b",
+		},
+		{
+			name:     "strings that look like other types are quoted",
+			body:     `{"a":"true","b":"10","c":"null","d":"x: y"}`,
+			expected: "a: \"true\"\nb: \"10\"\nc: \"null\"\nd: 'x: y'",
+		},
+		{
+			name:     "empty containers",
+			body:     `{"a":[],"b":{}}`,
+			expected: "a: []\nb: {}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := jsonvalue.Decode([]byte(tt.body))
+			require.NoError(t, err)
+
+			output, err := renderYAML(value)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, output)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Package report writes test results as JUnit XML or TAP, for continuous
+// integration servers.
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is the results of a scenario.
+type Suite struct {
+	Name     string
+	Cases    []Case
+	Duration time.Duration
+}
+
+// Case is the result of a step.
+type Case struct {
+	Name string
+	// Request is the method and URL sent, if the request was built.
+	Request  string
+	Status   int
+	Duration time.Duration
+	Failures []string
+	Skipped  bool
+}
+
+func (c Case) Failed() bool {
+	return !c.Skipped && len(c.Failures) > 0
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit writes suites as JUnit XML.
+func JUnit(w io.Writer, suites []Suite) error {
+	report := junitSuites{}
+	var total time.Duration
+	for _, suite := range suites {
+		js := junitSuite{Name: suite.Name, Time: seconds(suite.Duration)}
+		for _, c := range suite.Cases {
+			jc := junitCase{Name: c.Name, ClassName: suite.Name, Time: seconds(c.Duration)}
+			if c.Request != "" {
+				jc.SystemOut = fmt.Sprintf("%s\nstatus %d", c.Request, c.Status)
+			}
+
+			switch {
+			case c.Skipped:
+				jc.Skipped = &struct{}{}
+				js.Skipped++
+			case c.Failed():
+				jc.Failure = &junitFailure{
+					Message: c.Failures[0],
+					Type:    "AssertionError",
+					Text:    strings.Join(c.Failures, "\n"),
+				}
+				js.Failures++
+			}
+			js.Cases = append(js.Cases, jc)
+		}
+		js.Tests = len(suite.Cases)
+
+		report.Tests += js.Tests
+		report.Failures += js.Failures
+		report.Skipped += js.Skipped
+		report.Suites = append(report.Suites, js)
+		total += suite.Duration
+	}
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// tapDiagnostic is the YAML block following a failed test.
+type tapDiagnostic struct {
+	Request  string   `yaml:"request,omitempty"`
+	Status   int      `yaml:"status,omitempty"`
+	Duration string   `yaml:"duration,omitempty"`
+	Failures []string `yaml:"failures"`
+}
+
+// TAP writes suites as a TAP version 13 stream, a test per case.
+func TAP(w io.Writer, suites []Suite) error {
+	var b strings.Builder
+	count := 0
+	for _, suite := range suites {
+		count += len(suite.Cases)
+	}
+	fmt.Fprintf(&b, "TAP version 13\n1..%d\n", count)
+
+	n := 0
+	for _, suite := range suites {
+		for _, c := range suite.Cases {
+			n++
+			status := "ok"
+			if c.Failed() {
+				status = "not ok"
+			}
+			fmt.Fprintf(&b, "%s %d - %s: %s", status, n, suite.Name, c.Name)
+			if c.Skipped {
+				b.WriteString(" # SKIP a previous step failed")
+			}
+			b.WriteString("\n")
+
+			if !c.Failed() {
+				continue
+			}
+			diagnostic := tapDiagnostic{Request: c.Request, Status: c.Status, Failures: c.Failures}
+			if c.Duration > 0 {
+				diagnostic.Duration = c.Duration.Round(time.Millisecond).String()
+			}
+			var data strings.Builder
+			enc := yaml.NewEncoder(&data)
+			enc.SetIndent(2)
+			if err := enc.Encode(diagnostic); err != nil {
+				return err
+			}
+			b.WriteString("  ---\n")
+			for line := range strings.Lines(data.String()) {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("  ...\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
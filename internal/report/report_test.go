@@ -0,0 +1,84 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var suites = []Suite{
+	{
+		Name:     "orders",
+		Duration: 1500 * time.Millisecond,
+		Cases: []Case{
+			{Name: "create", Request: "POST http://localhost/orders", Status: 201, Duration: 120 * time.Millisecond},
+			{
+				Name:     "get",
+				Request:  "GET http://localhost/orders/1",
+				Status:   404,
+				Duration: 30 * time.Millisecond,
+				Failures: []string{"expected status 200, got 404", `expected .id to equal 1, got "x" & <y>`},
+			},
+			{Name: "delete", Skipped: true},
+		},
+	},
+	{Name: "health", Cases: []Case{{Name: "step 1", Failures: []string{"failed to render body: undefined variable id"}}}},
+}
+
+func TestJUnit(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, JUnit(&b, suites))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="4" failures="2" skipped="1" time="1.500">
+  <testsuite name="orders" tests="3" failures="1" errors="0" skipped="1" time="1.500">
+    <testcase name="create" classname="orders" time="0.120">
+      <system-out>POST http://localhost/orders&#xA;status 201</system-out>
+    </testcase>
+    <testcase name="get" classname="orders" time="0.030">
+      <failure message="expected status 200, got 404" type="AssertionError">expected status 200, got 404&#xA;expected .id to equal 1, got &#34;x&#34; &amp; &lt;y&gt;</failure>
+      <system-out>GET http://localhost/orders/1&#xA;status 404</system-out>
+    </testcase>
+    <testcase name="delete" classname="orders" time="0.000">
+      <skipped></skipped>
+    </testcase>
+  </testsuite>
+  <testsuite name="health" tests="1" failures="1" errors="0" skipped="0" time="0.000">
+    <testcase name="step 1" classname="health" time="0.000">
+      <failure message="failed to render body: undefined variable id" type="AssertionError">failed to render body: undefined variable id</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, b.String())
+}
+
+func TestTAP(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, TAP(&b, suites))
+	require.Equal(t, `TAP version 13
+1..4
+ok 1 - orders: create
+not ok 2 - orders: get
+  ---
+  request: GET http://localhost/orders/1
+  status: 404
+  duration: 30ms
+  failures:
+    - expected status 200, got 404
+    - expected .id to equal 1, got "x" & <y>
+  ...
+ok 3 - orders: delete # SKIP a previous step failed
+not ok 4 - health: step 1
+  ---
+  failures:
+    - 'failed to render body: undefined variable id'
+  ...
+`, b.String())
+}
+
+func TestEmpty(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, TAP(&b, nil))
+	require.Equal(t, "TAP version 13\n1..0\n", b.String())
+}
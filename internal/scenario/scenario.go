@@ -0,0 +1,117 @@
+// Package scenario reads the scenario files run by ashttp run-file and
+// ashttp test: steps sharing the values they capture, each one checked
+// against its expectations.
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ashttp/internal/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// What to do with the remaining steps once one fails.
+const (
+	OnFailureStop     = "stop"
+	OnFailureContinue = "continue"
+)
+
+type Scenario struct {
+	Name      string         `yaml:"name"`
+	Vars      map[string]any `yaml:"vars"`
+	OnFailure string         `yaml:"onFailure"`
+	Steps     []Step         `yaml:"steps"`
+}
+
+type Step struct {
+	Name    string            `yaml:"name"`
+	Alias   string            `yaml:"alias"`
+	Method  string            `yaml:"method"`
+	Path    Path              `yaml:"path"`
+	Options map[string]string `yaml:"options"`
+	Headers map[string]string `yaml:"headers"`
+	Body    Body              `yaml:"body"`
+	Capture map[string]string `yaml:"capture"`
+	Expect  assert.Assertions `yaml:"expect"`
+
+	// OnFailure overrides the scenario setting for this step.
+	OnFailure string `yaml:"onFailure"`
+}
+
+// Load reads a YAML or JSON scenario file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Scenario
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Schema files are found next to the scenario.
+	for i, step := range s.Steps {
+		if step.Expect.Schema == nil {
+			continue
+		}
+		if err := step.Expect.Schema.Load(filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("%s: step %d: failed to load schema: %w", path, i+1, err)
+		}
+	}
+	return &s, nil
+}
+
+func (s *Scenario) validate() error {
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("the scenario has no steps")
+	}
+	if err := validOnFailure(s.OnFailure); err != nil {
+		return err
+	}
+
+	for i, step := range s.Steps {
+		switch {
+		case step.Alias == "":
+			return fmt.Errorf("step %d has no alias", i+1)
+		case step.Method == "":
+			return fmt.Errorf("step %d has no method", i+1)
+		}
+		if err := validOnFailure(step.OnFailure); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if err := step.Expect.Validate(); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validOnFailure(onFailure string) error {
+	if onFailure != "" && onFailure != OnFailureStop && onFailure != OnFailureContinue {
+		return fmt.Errorf("onFailure must be %s or %s, got %q", OnFailureStop, OnFailureContinue, onFailure)
+	}
+	return nil
+}
+
+// Title names the step numbered n, from 1, in reports.
+func (s Step) Title(n int) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("step %d", n)
+}
+
+// StopsOnFailure reports whether the steps after this one are skipped
+// when it fails, stopping being the default.
+func (s *Scenario) StopsOnFailure(step Step) bool {
+	if step.OnFailure != "" {
+		return step.OnFailure == OnFailureStop
+	}
+	return s.OnFailure != OnFailureContinue
+}
@@ -0,0 +1,162 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ashttp/internal/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScenario(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeScenario(t, "scenario.yaml", `
+name: orders
+vars:
+  item: book
+onFailure: continue
+steps:
+  - name: create
+    alias: shop
+    method: post
+    path: orders
+    headers:
+      X-Request-Id: "{{ uuid }}"
+    body:
+      item: "{{ .item }}"
+      quantity: 2
+      tags: [a, b]
+    capture:
+      id: .data.id
+    expect:
+      status: 201
+  - alias: shop
+    method: get
+    path: [orders, "{{ .id }}"]
+    options:
+      page: 2
+    body: plain text
+    expect:
+      status: [200, 304]
+    onFailure: stop
+`)
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, &Scenario{
+		Name:      "orders",
+		Vars:      map[string]any{"item": "book"},
+		OnFailure: OnFailureContinue,
+		Steps: []Step{
+			{
+				Name:    "create",
+				Alias:   "shop",
+				Method:  "post",
+				Path:    Path{"orders"},
+				Headers: map[string]string{"X-Request-Id": "{{ uuid }}"},
+				Body:    `{"item":"{{ .item }}","quantity":2,"tags":["a","b"]}`,
+				Capture: map[string]string{"id": ".data.id"},
+				Expect:  assert.Assertions{Status: assert.Statuses{201}},
+			},
+			{
+				Alias:     "shop",
+				Method:    "get",
+				Path:      Path{"orders", "{{ .id }}"},
+				Options:   map[string]string{"page": "2"},
+				Body:      "plain text",
+				Expect:    assert.Assertions{Status: assert.Statuses{200, 304}},
+				OnFailure: OnFailureStop,
+			},
+		},
+	}, s)
+}
+
+func TestLoadJSON(t *testing.T) {
+	path := writeScenario(t, "scenario.json", `{"steps": [{"alias": "shop", "method": "post", "body": {"b": 1, "a": null}}]}`)
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, Body(`{"b":1,"a":null}`), s.Steps[0].Body)
+}
+
+func TestLoadSchema(t *testing.T) {
+	path := writeScenario(t, "scenario.yaml", `
+steps:
+  - alias: shop
+    method: get
+    expect:
+      schema: order.json
+`)
+	schema := `{"type": "object", "required": ["id"]}`
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "order.json"), []byte(schema), 0644))
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"$: missing required property id"}, s.Steps[0].Expect.Schema.Validate([]byte(`{}`)))
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "no steps", content: "name: empty\n", err: "the scenario has no steps"},
+		{name: "no alias", content: "steps:\n  - method: get\n", err: "step 1 has no alias"},
+		{name: "no method", content: "steps:\n  - alias: shop\n", err: "step 1 has no method"},
+		{
+			name:    "on failure",
+			content: "onFailure: retry\nsteps:\n  - alias: shop\n    method: get\n",
+			err:     `onFailure must be stop or continue, got "retry"`,
+		},
+		{
+			name:    "step on failure",
+			content: "steps:\n  - alias: shop\n    method: get\n    onFailure: retry\n",
+			err:     `step 1: onFailure must be stop or continue, got "retry"`,
+		},
+		{
+			name:    "status",
+			content: "steps:\n  - alias: shop\n    method: get\n    expect:\n      status: ok\n",
+			err:     `line 5: invalid status "ok"`,
+		},
+		{
+			name:    "json assertion",
+			content: "steps:\n  - alias: shop\n    method: get\n    expect:\n      json:\n        - path: .id\n",
+			err:     "step 1: json path .id: expected equals or matches",
+		},
+		{
+			name:    "schema file",
+			content: "steps:\n  - alias: shop\n    method: get\n    expect:\n      schema: missing.json\n",
+			err:     "step 1: failed to load schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeScenario(t, "scenario.yaml", tt.content))
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestStopsOnFailure(t *testing.T) {
+	s := &Scenario{}
+	require.True(t, s.StopsOnFailure(Step{}))
+	require.False(t, s.StopsOnFailure(Step{OnFailure: OnFailureContinue}))
+
+	s.OnFailure = OnFailureContinue
+	require.False(t, s.StopsOnFailure(Step{}))
+	require.True(t, s.StopsOnFailure(Step{OnFailure: OnFailureStop}))
+}
+
+func TestStepTitle(t *testing.T) {
+	require.Equal(t, "create", Step{Name: "create"}.Title(1))
+	require.Equal(t, "step 2", Step{}.Title(2))
+}
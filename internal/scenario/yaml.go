@@ -0,0 +1,82 @@
+package scenario
+
+import (
+	"github.com/ashttp/internal/jsonvalue"
+	"gopkg.in/yaml.v3"
+)
+
+// Path is the path components of a step, written as a list or a single
+// string such as users/42.
+type Path []string
+
+func (p *Path) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*p = Path{node.Value}
+		return nil
+	}
+
+	var components []string
+	if err := node.Decode(&components); err != nil {
+		return err
+	}
+	*p = components
+	return nil
+}
+
+// Body is a request body, written as text or as a YAML value sent as JSON
+// with its keys in order.
+type Body string
+
+func (b *Body) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		*b = Body(node.Value)
+		return nil
+	}
+
+	value, err := nodeValue(node)
+	if err != nil {
+		return err
+	}
+	data, err := jsonvalue.Marshal(value)
+	if err != nil {
+		return err
+	}
+	*b = Body(data)
+	return nil
+}
+
+// nodeValue converts a YAML node, objects becoming *jsonvalue.Object to
+// keep the order of their keys.
+func nodeValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return nodeValue(node.Content[0])
+	case yaml.AliasNode:
+		return nodeValue(node.Alias)
+	case yaml.MappingNode:
+		object := jsonvalue.NewObject()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := nodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			object.Set(node.Content[i].Value, value)
+		}
+		return object, nil
+	case yaml.SequenceNode:
+		list := make([]any, len(node.Content))
+		for i, item := range node.Content {
+			var err error
+			if list[i], err = nodeValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	}
+
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,54 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ashttp/internal/filter"
+	"github.com/ashttp/internal/jsonvalue"
+)
+
+// ParseCapture reads a name=expression capture, such as id=.data.id.
+func ParseCapture(s string) (string, string, error) {
+	name, expression, found := strings.Cut(s, "=")
+	name, expression = strings.TrimSpace(name), strings.TrimSpace(expression)
+	if !found || name == "" || expression == "" {
+		return "", "", fmt.Errorf("expected name=expression, got %q", s)
+	}
+
+	if _, err := filter.Parse(expression); err != nil {
+		return "", "", fmt.Errorf("invalid expression for %s: %w", name, err)
+	}
+	return name, expression, nil
+}
+
+// Capture extracts the single value that expression selects in a JSON
+// body.
+func Capture(body []byte, expression string) (json.RawMessage, error) {
+	query, err := filter.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := jsonvalue.Decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("the response is not JSON: %w", err)
+	}
+
+	results, err := query.Apply(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(results) == 0:
+		return nil, fmt.Errorf("%s selects nothing", expression)
+	case len(results) > 1:
+		return nil, fmt.Errorf("%s selects %d values, expected one", expression, len(results))
+	case results[0] == nil:
+		return nil, fmt.Errorf("%s is null", expression)
+	}
+
+	return jsonvalue.Marshal(results[0])
+}
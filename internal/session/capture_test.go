@@ -0,0 +1,54 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCapture(t *testing.T) {
+	name, expression, err := ParseCapture("id = .data.id")
+	require.NoError(t, err)
+	require.Equal(t, "id", name)
+	require.Equal(t, ".data.id", expression)
+
+	_, _, err = ParseCapture("id")
+	require.EqualError(t, err, `expected name=expression, got "id"`)
+
+	_, _, err = ParseCapture("id=.data[")
+	require.ErrorContains(t, err, "invalid expression for id")
+}
+
+func TestCapture(t *testing.T) {
+	body := []byte(`{"data": {"id": 42, "name": "ana", "tags": ["a", "b"], "owner": {"login": "bob"}, "gone": null}}`)
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+		err        string
+	}{
+		{name: "number", expression: ".data.id", expected: `42`},
+		{name: "string", expression: ".data.name", expected: `"ana"`},
+		{name: "object", expression: ".data.owner", expected: `{"login":"bob"}`},
+		{name: "array", expression: ".data.tags", expected: `["a","b"]`},
+		{name: "several values", expression: ".data.tags[]", err: ".data.tags[] selects 2 values, expected one"},
+		{name: "null", expression: ".data.gone", err: ".data.gone is null"},
+		{name: "missing", expression: ".data.missing", err: ".data.missing is null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := Capture(body, tt.expression)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(value))
+		})
+	}
+
+	_, err := Capture([]byte("<html>"), ".id")
+	require.ErrorContains(t, err, "the response is not JSON")
+}
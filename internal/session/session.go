@@ -0,0 +1,149 @@
+// Package session stores the values captured from responses, so later
+// requests can use them as template variables.
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ashttp/internal/export"
+)
+
+// Values are the stored values, as JSON, by name.
+type Values map[string]json.RawMessage
+
+var defaultFilePath = path.Join(os.ExpandEnv("$HOME"), ".config", "ashttp", "session.json")
+
+func GetDefaultPath() string {
+	return defaultFilePath
+}
+
+// Load reads the stored values.
+func Load() (Values, error) {
+	return loadFromFile(defaultFilePath)
+}
+
+// Set stores values, replacing the ones of the same names.
+func Set(values Values) error {
+	stored, err := loadFromFile(defaultFilePath)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range values {
+		stored[name] = value
+	}
+	return saveToFile(defaultFilePath, stored)
+}
+
+// Clear removes the values named, or every value when names is empty.
+func Clear(names []string) error {
+	if len(names) == 0 {
+		return saveToFile(defaultFilePath, Values{})
+	}
+
+	stored, err := loadFromFile(defaultFilePath)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if _, exists := stored[name]; !exists {
+			return fmt.Errorf("no value named %s in the session", name)
+		}
+		delete(stored, name)
+	}
+	return saveToFile(defaultFilePath, stored)
+}
+
+func loadFromFile(filePath string) (Values, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Values{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	var values Values
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	if values == nil {
+		values = Values{}
+	}
+	return values, nil
+}
+
+func saveToFile(filePath string, values Values) error {
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	// Captured values are often tokens, the file is only readable by its owner.
+	return os.WriteFile(filePath, append(data, '\n'), 0600)
+}
+
+// Names lists the names of the values, sorted.
+func (v Values) Names() []string {
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Text returns a value as text: strings unquoted, anything else as
+// compact JSON.
+func (v Values) Text(name string) string {
+	var s string
+	if err := json.Unmarshal(v[name], &s); err == nil {
+		return s
+	}
+
+	// Values are indented in the session file.
+	var b bytes.Buffer
+	if err := json.Compact(&b, v[name]); err != nil {
+		return string(v[name])
+	}
+	return b.String()
+}
+
+// Vars decodes the values for templates, objects becoming maps so that
+// their fields can be reached as {{ .order.id }}.
+func (v Values) Vars() map[string]any {
+	vars := make(map[string]any, len(v))
+	for name, value := range v {
+		dec := json.NewDecoder(bytes.NewReader(value))
+		dec.UseNumber()
+
+		var decoded any
+		if err := dec.Decode(&decoded); err == nil {
+			vars[name] = decoded
+		}
+	}
+	return vars
+}
+
+// Env writes the values as name='value' lines, ready to be sourced by a
+// shell.
+func (v Values) Env() string {
+	var b strings.Builder
+	for _, name := range v.Names() {
+		fmt.Fprintf(&b, "%s=%s\n", name, export.ShellQuote(v.Text(name)))
+	}
+	return b.String()
+}
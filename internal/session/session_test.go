@@ -0,0 +1,65 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLoadAndClear(t *testing.T) {
+	originalPath := defaultFilePath
+	defaultFilePath = filepath.Join(t.TempDir(), "ashttp", "session.json")
+	defer func() {
+		defaultFilePath = originalPath
+	}()
+
+	values, err := Load()
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	require.NoError(t, Set(Values{"id": json.RawMessage(`42`), "token": json.RawMessage(`"abc"`)}))
+	require.NoError(t, Set(Values{"id": json.RawMessage(`43`)}))
+
+	values, err = Load()
+	require.NoError(t, err)
+	require.Equal(t, Values{"id": json.RawMessage(`43`), "token": json.RawMessage(`"abc"`)}, values)
+
+	info, err := os.Stat(defaultFilePath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, Clear([]string{"token"}))
+	require.EqualError(t, Clear([]string{"token"}), "no value named token in the session")
+
+	values, err = Load()
+	require.NoError(t, err)
+	require.Equal(t, []string{"id"}, values.Names())
+
+	require.NoError(t, Clear(nil))
+	values, err = Load()
+	require.NoError(t, err)
+	require.Empty(t, values)
+}
+
+func TestValues(t *testing.T) {
+	values := Values{
+		"id":    json.RawMessage(`42`),
+		"name":  json.RawMessage(`"ana o'neil"`),
+		"order": json.RawMessage("{\n  \"id\": \"o-1\"\n}"),
+	}
+
+	require.Equal(t, "42", values.Text("id"))
+	require.Equal(t, "ana o'neil", values.Text("name"))
+	require.Equal(t, `{"id":"o-1"}`, values.Text("order"))
+
+	require.Equal(t, map[string]any{
+		"id":    json.Number("42"),
+		"name":  "ana o'neil",
+		"order": map[string]any{"id": "o-1"},
+	}, values.Vars())
+
+	require.Equal(t, "id=42\nname='ana o'\\''neil'\norder='{\"id\":\"o-1\"}'\n", values.Env())
+}
@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLine bounds a single line of an event stream.
+const maxLine = 16 << 20
+
+// Event is a Server-Sent Event. Type is empty for the default "message"
+// events and ID is the last event ID the server set, which carries over
+// from one event to the next.
+type Event struct {
+	ID   string
+	Type string
+	Data string
+}
+
+// Decoder reads events from a text/event-stream body, following
+// https://html.spec.whatwg.org/multipage/server-sent-events.html.
+type Decoder struct {
+	scanner *bufio.Scanner
+	started bool
+
+	// LastEventID and Retry are updated as the stream sets them.
+	LastEventID string
+	Retry       time.Duration
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLine)
+	scanner.Split(scanEventLines)
+	return &Decoder{scanner: scanner}
+}
+
+// Next returns the next event, or io.EOF once the stream ends. An event
+// cut by the end of the stream is dropped.
+func (d *Decoder) Next() (Event, error) {
+	var event Event
+	var data strings.Builder
+
+	for d.scanner.Scan() {
+		line := d.scanner.Text()
+		if !d.started {
+			line = strings.TrimPrefix(line, "\ufeff")
+			d.started = true
+		}
+
+		if line == "" {
+			if data.Len() == 0 {
+				event = Event{}
+				continue
+			}
+			event.ID = d.LastEventID
+			event.Data = strings.TrimSuffix(data.String(), "\n")
+			return event, nil
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Type = value
+		case "data":
+			data.WriteString(value + "\n")
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				d.LastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 32); err == nil {
+				d.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	return Event{}, io.EOF
+}
+
+// scanEventLines splits lines ending with \r\n, \n or a lone \r.
+func scanEventLines(data []byte, atEOF bool) (int, []byte, error) {
+	i := bytes.IndexAny(data, "\r\n")
+	switch {
+	case i < 0:
+		if atEOF && len(data) > 0 {
+			// An unterminated last line never completes an event.
+			return len(data), nil, nil
+		}
+		return 0, nil, nil
+	case data[i] == '\n':
+		return i + 1, data[:i], nil
+	case i+1 < len(data):
+		if data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	case atEOF:
+		return i + 1, data[:i], nil
+	default:
+		// Wait to know whether \r is followed by \n.
+		return 0, nil, nil
+	}
+}
@@ -0,0 +1,88 @@
+package stream
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readEvents(t *testing.T, r io.Reader) ([]Event, *Decoder) {
+	decoder := NewDecoder(r)
+	var events []Event
+	for {
+		event, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			return events, decoder
+		}
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name           string
+		stream         string
+		expected       []Event
+		expectedLastID string
+		expectedRetry  time.Duration
+	}{
+		{
+			name:     "data lines are joined",
+			stream:   "data: first\ndata:second\n\n",
+			expected: []Event{{Data: "first\nsecond"}},
+		},
+		{
+			name:           "types and ids",
+			stream:         "event: update\nid: 1\ndata: {\"n\":1}\n\ndata: {\"n\":2}\n\n",
+			expected:       []Event{{ID: "1", Type: "update", Data: `{"n":1}`}, {ID: "1", Data: `{"n":2}`}},
+			expectedLastID: "1",
+		},
+		{
+			name:     "comments and unknown fields are ignored",
+			stream:   ": keep-alive\nfoo: bar\ndata: x\n\n",
+			expected: []Event{{Data: "x"}},
+		},
+		{
+			name:     "events without data are not dispatched",
+			stream:   "event: ping\n\ndata: x\n\n",
+			expected: []Event{{Data: "x"}},
+		},
+		{
+			name:          "retry is read in milliseconds",
+			stream:        "retry: 1500\nretry: soon\ndata: x\n\n",
+			expected:      []Event{{Data: "x"}},
+			expectedRetry: 1500 * time.Millisecond,
+		},
+		{
+			name:           "ids with NUL are ignored",
+			stream:         "id: 7\n\nid: a\x00b\ndata: x\n\n",
+			expected:       []Event{{ID: "7", Data: "x"}},
+			expectedLastID: "7",
+		},
+		{
+			name:     "crlf, cr and a byte order mark",
+			stream:   "\ufeffdata: a\r\n\r\ndata: b\r\rdata\n\n",
+			expected: []Event{{Data: "a"}, {Data: "b"}, {Data: ""}},
+		},
+		{
+			name:     "an event cut by the end of the stream is dropped",
+			stream:   "data: a\n\ndata: b\n",
+			expected: []Event{{Data: "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, decoder := readEvents(t, iotest.OneByteReader(strings.NewReader(tt.stream)))
+			require.Equal(t, tt.expected, events)
+			require.Equal(t, tt.expectedLastID, decoder.LastEventID)
+			require.Equal(t, tt.expectedRetry, decoder.Retry)
+		})
+	}
+}
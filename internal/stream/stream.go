@@ -0,0 +1,71 @@
+// Package stream reads responses that are printed as they arrive instead
+// of once complete: NDJSON lines and Server-Sent Events.
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+)
+
+type Mode int
+
+const (
+	None Mode = iota
+	NDJSON
+	SSE
+)
+
+// ndjsonTypes are the names used for newline delimited JSON.
+var ndjsonTypes = []string{
+	"application/x-ndjson",
+	"application/ndjson",
+	"application/jsonl",
+	"application/x-jsonlines",
+	"application/stream+json",
+}
+
+// Verbose receives notices about reconnections, like http.Verbose.
+var Verbose io.Writer = io.Discard
+
+func logf(format string, v ...any) {
+	fmt.Fprintf(Verbose, "[verbose] %s\n", fmt.Sprintf(format, v...))
+}
+
+// Detect tells from a Content-Type whether a response is a stream.
+func Detect(contentType string) Mode {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "text/event-stream" {
+		return SSE
+	}
+	for _, t := range ndjsonTypes {
+		if mediaType == t {
+			return NDJSON
+		}
+	}
+	return None
+}
+
+// Lines calls fn with each line of r, without its line ending, as soon as
+// it has been read. Blank lines are skipped.
+func Lines(r io.Reader, fn func(line []byte) error) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimRight(line, "\r\n"); len(bytes.TrimSpace(line)) > 0 {
+			if fnErr := fn(line); fnErr != nil {
+				return fnErr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package stream
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		contentType string
+		expected    Mode
+	}{
+		{contentType: "text/event-stream", expected: SSE},
+		{contentType: "text/event-stream; charset=utf-8", expected: SSE},
+		{contentType: "application/x-ndjson", expected: NDJSON},
+		{contentType: "application/jsonl", expected: NDJSON},
+		{contentType: "application/json", expected: None},
+		{contentType: "", expected: None},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			require.Equal(t, tt.expected, Detect(tt.contentType))
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	var lines []string
+	collect := func(line []byte) error {
+		lines = append(lines, string(line))
+		return nil
+	}
+
+	// One byte at a time, as a slow stream would deliver them.
+	err := Lines(iotest.OneByteReader(strings.NewReader("{\"a\":1}\r\n\n  \n{\"b\":2}\n{\"c\":3}")), collect)
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"a":1}`, `{"b":2}`, `{"c":3}`}, lines)
+
+	stop := errors.New("stop")
+	err = Lines(strings.NewReader("1\n2\n"), func([]byte) error { return stop })
+	require.ErrorIs(t, err, stop)
+
+	broken := io.MultiReader(strings.NewReader("1\n"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	err = Lines(broken, func([]byte) error { return nil })
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,109 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ashttp/internal/config"
+	internalhttp "github.com/ashttp/internal/http"
+)
+
+// DefaultRetry is the delay before reconnecting until the server sets one.
+const DefaultRetry = 3 * time.Second
+
+// errEnd tells the server does not want the client to reconnect.
+var errEnd = errors.New("end of the event stream")
+
+// Subscribe passes the events of first to fn and reconnects whenever the
+// connection ends, sending Last-Event-ID so the server can resume. It
+// returns nil once ctx is done or the server answers 204 No Content.
+func Subscribe(ctx context.Context, req *http.Request, setting config.Setting, first io.ReadCloser,
+	fn func(Event) error) error {
+	body := first
+	lastEventID, retry := "", DefaultRetry
+
+	for {
+		decoder := NewDecoder(body)
+		decoder.LastEventID = lastEventID
+
+		for {
+			event, err := decoder.Next()
+			if err != nil {
+				// The connection ended or dropped, either way it is reopened.
+				if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+					logf("event stream interrupted: %v", err)
+				}
+				break
+			}
+			if err := fn(event); err != nil {
+				body.Close()
+				return err
+			}
+		}
+		body.Close()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		lastEventID = decoder.LastEventID
+		if decoder.Retry > 0 {
+			retry = decoder.Retry
+		}
+
+		logf("event stream closed, reconnecting in %s", retry)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(retry):
+		}
+
+		var err error
+		body, err = reconnect(req, setting, lastEventID)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.Is(err, errEnd):
+			return nil
+		case err != nil:
+			return fmt.Errorf("reconnecting: %w", err)
+		}
+	}
+}
+
+func reconnect(req *http.Request, setting config.Setting, lastEventID string) (io.ReadCloser, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	if lastEventID != "" {
+		retry.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	stream, err := internalhttp.Open(retry, setting)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case stream.StatusCode == http.StatusNoContent:
+		stream.Body.Close()
+		return nil, errEnd
+	case stream.StatusCode != http.StatusOK:
+		stream.Body.Close()
+		return nil, fmt.Errorf("the server answered %s", stream.Status)
+	case Detect(stream.Header.Get("Content-Type")) != SSE:
+		stream.Body.Close()
+		return nil, fmt.Errorf("the server answered with %s instead of an event stream", stream.Header.Get("Content-Type"))
+	}
+
+	return stream.Body, nil
+}
@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ashttp/internal/config"
+	internalhttp "github.com/ashttp/internal/http"
+	"github.com/stretchr/testify/require"
+)
+
+// subscribe opens url and follows the event stream until it ends.
+func subscribe(t *testing.T, ctx context.Context, url string, fn func(Event) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	first, err := internalhttp.Open(req, config.Setting{})
+	require.NoError(t, err)
+
+	return Subscribe(ctx, req, config.Setting{}, first.Body, fn)
+}
+
+func TestSubscribe(t *testing.T) {
+	t.Run("reconnects with the last event id until 204", func(t *testing.T) {
+		var lastEventIDs []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+			switch r.Header.Get("Last-Event-ID") {
+			case "":
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, "retry: 1\nid: 1\ndata: a\n\n")
+			case "1":
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, "id: 2\ndata: b\n\n")
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		defer server.Close()
+
+		var events []Event
+		err := subscribe(t, context.Background(), server.URL, func(event Event) error {
+			events = append(events, event)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []Event{{ID: "1", Data: "a"}, {ID: "2", Data: "b"}}, events)
+		require.Equal(t, []string{"", "1", "2"}, lastEventIDs)
+	})
+
+	t.Run("ends quietly when cancelled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: a\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var received atomic.Int32
+		err := subscribe(t, ctx, server.URL, func(Event) error {
+			received.Add(1)
+			cancel()
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(1), received.Load())
+	})
+
+	t.Run("stops when reconnecting fails", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) > 1 {
+				http.Error(w, "down", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "retry: 1\ndata: a\n\n")
+		}))
+		defer server.Close()
+
+		err := subscribe(t, context.Background(), server.URL, func(Event) error { return nil })
+		require.EqualError(t, err, "reconnecting: the server answered 503 Service Unavailable")
+	})
+
+	t.Run("returns the error of fn", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: a\n\n")
+		}))
+		defer server.Close()
+
+		stop := errors.New("stop")
+		err := subscribe(t, context.Background(), server.URL, func(Event) error { return stop })
+		require.ErrorIs(t, err, stop)
+	})
+}
@@ -0,0 +1,177 @@
+// Package templating renders the {{ }} templates of the arguments, headers
+// and bodies of a request.
+package templating
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Vars are the variables templates refer to as {{ .name }}.
+type Vars map[string]any
+
+// now is replaced in tests.
+var now = time.Now
+
+var funcs = template.FuncMap{
+	"now":       func() time.Time { return now() },
+	"unix":      func(t time.Time) int64 { return t.Unix() },
+	"unixMilli": func(t time.Time) int64 { return t.UnixMilli() },
+	"date":      func(layout string, t time.Time) string { return t.Format(layout) },
+	"uuid":      uuid,
+	"env":       env,
+}
+
+// ParseVar reads a name=value variable.
+func ParseVar(s string) (string, string, error) {
+	name, value, found := strings.Cut(s, "=")
+	if !found || strings.TrimSpace(name) == "" {
+		return "", "", fmt.Errorf("expected name=value, got %q", s)
+	}
+	return strings.TrimSpace(name), value, nil
+}
+
+// LoadFile reads variables from a JSON or YAML object.
+func LoadFile(path string) (Vars, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var vars map[string]any
+	if err := yaml.Unmarshal(data, &vars); err != nil {
+		return nil, fmt.Errorf("%s is not a JSON or YAML object: %w", path, err)
+	}
+	if vars == nil {
+		vars = map[string]any{}
+	}
+	return Vars(vars), nil
+}
+
+// Render executes the template in text. Using a variable missing from vars
+// is an error.
+func Render(text string, vars Vars) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", cleanError(err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, map[string]any(vars)); err != nil {
+		return "", cleanError(err)
+	}
+	return b.String(), nil
+}
+
+// Variables returns the names of the variables text refers to, so values
+// that are costly to get are only read when used. It returns nothing when
+// text is not a valid template, Render reporting the error.
+func Variables(text string) []string {
+	if !strings.Contains(text, "{{") {
+		return nil
+	}
+
+	tmpl, err := template.New("").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.FieldNode:
+			names = append(names, n.Ident[0])
+		case *parse.VariableNode:
+			// $.name refers to the variables as .name does.
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				names = append(names, n.Ident[1])
+			}
+		}
+	}
+	walk(tmpl.Root)
+
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+var (
+	errorPrefix = regexp.MustCompile(`^template: :\d+(:\d+)?: (executing "" at <[^>]*>: )?(error calling \w+: )?`)
+	missingKey  = regexp.MustCompile(`^map has no entry for key "([^"]*)"$`)
+)
+
+// cleanError drops the template name and position from err, which are of
+// no use for a single argument.
+func cleanError(err error) error {
+	message := errorPrefix.ReplaceAllString(err.Error(), "")
+	if m := missingKey.FindStringSubmatch(message); m != nil {
+		return fmt.Errorf("undefined variable %s", m[1])
+	}
+	return errors.New(message)
+}
+
+func uuid() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+func env(name string) (string, error) {
+	value, found := os.LookupEnv(name)
+	if !found {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,119 @@
+package templating
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	originalNow := now
+	now = func() time.Time { return time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC) }
+	defer func() { now = originalNow }()
+
+	t.Setenv("ASHTTP_TOKEN", "s3cr3t")
+
+	vars := Vars{"userId": "42", "user": map[string]any{"name": "ana"}, "count": 3}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		err      string
+	}{
+		{name: "no template", text: "users", expected: "users"},
+		{name: "variable", text: "users-{{ .userId }}", expected: "users-42"},
+		{name: "nested variable", text: "{{ .user.name }}", expected: "ana"},
+		{name: "number", text: `{"count": {{ .count }}}`, expected: `{"count": 3}`},
+		{name: "unix time", text: "{{ now | unix }}", expected: "1792326600"},
+		{name: "unix milliseconds", text: "{{ now | unixMilli }}", expected: "1792326600000"},
+		{name: "date", text: `{{ now | date "2006-01-02" }}`, expected: "2026-10-18"},
+		{name: "environment", text: `Bearer {{ env "ASHTTP_TOKEN" }}`, expected: "Bearer s3cr3t"},
+		{name: "undefined variable", text: "{{ .orderId }}", err: "undefined variable orderId"},
+		{name: "unset environment variable", text: `{{ env "ASHTTP_MISSING" }}`, err: "environment variable ASHTTP_MISSING is not set"},
+		{name: "unknown function", text: "{{ nope }}", err: `function "nope" not defined`},
+		{name: "syntax error", text: "{{ .userId ", err: "unclosed action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := Render(tt.text, vars)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rendered)
+		})
+	}
+}
+
+func TestUUID(t *testing.T) {
+	first, err := Render("{{ uuid }}", nil)
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), first)
+
+	second, err := Render("{{ uuid }}", nil)
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+}
+
+func TestVariables(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{text: "users"},
+		{text: "{{ uuid }}-{{ now | unix }}"},
+		{text: "{{ .userId }}/{{ .user.name }}/{{ .userId }}", expected: []string{"user", "userId"}},
+		{text: `{{ if .admin }}{{ .role }}{{ else }}{{ env "X" }}{{ end }}`, expected: []string{"admin", "role"}},
+		{text: "{{ range .items }}{{ $.prefix }}{{ end }}", expected: []string{"items", "prefix"}},
+		{text: "{{ (.order).id | printf \"%v\" }}", expected: []string{"order"}},
+		{text: "{{ .userId "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			require.Equal(t, tt.expected, Variables(tt.text))
+		})
+	}
+}
+
+func TestParseVar(t *testing.T) {
+	name, value, err := ParseVar("userId=42")
+	require.NoError(t, err)
+	require.Equal(t, "userId", name)
+	require.Equal(t, "42", value)
+
+	_, value, err = ParseVar("query=a=b")
+	require.NoError(t, err)
+	require.Equal(t, "a=b", value)
+
+	_, _, err = ParseVar("userId")
+	require.EqualError(t, err, `expected name=value, got "userId"`)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "vars.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("userId: 42\nuser:\n  name: ana\n"), 0644))
+	vars, err := LoadFile(yamlPath)
+	require.NoError(t, err)
+	require.Equal(t, Vars{"userId": 42, "user": map[string]any{"name": "ana"}}, vars)
+
+	jsonPath := filepath.Join(dir, "vars.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"userId": "42"}`), 0644))
+	vars, err = LoadFile(jsonPath)
+	require.NoError(t, err)
+	require.Equal(t, Vars{"userId": "42"}, vars)
+
+	listPath := filepath.Join(dir, "list.yaml")
+	require.NoError(t, os.WriteFile(listPath, []byte("- a\n"), 0644))
+	_, err = LoadFile(listPath)
+	require.ErrorContains(t, err, "is not a JSON or YAML object")
+}
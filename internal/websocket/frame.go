@@ -0,0 +1,126 @@
+package websocket
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Opcodes of RFC 6455 section 5.2.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// maxMessageSize bounds a message, fragments included.
+const maxMessageSize = 32 << 20
+
+type frame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+}
+
+func (f frame) control() bool {
+	return f.opcode&0x8 != 0
+}
+
+// writeFrame writes f. Clients mask what they send, servers do not.
+func writeFrame(w io.Writer, f frame, mask bool) error {
+	header := make([]byte, 2, 14)
+	header[0] = f.opcode
+	if f.fin {
+		header[0] |= 0x80
+	}
+
+	length := len(f.payload)
+	switch {
+	case length < 126:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	payload := f.payload
+	if mask {
+		header[1] |= 0x80
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		header = append(header, key[:]...)
+
+		payload = make([]byte, length)
+		for i := range payload {
+			payload[i] = f.payload[i] ^ key[i%4]
+		}
+	}
+
+	_, err := w.Write(append(header, payload...))
+	return err
+}
+
+func readFrame(r io.Reader) (frame, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return frame{}, err
+	}
+
+	f := frame{fin: header[0]&0x80 != 0, opcode: header[0] & 0x0f}
+	if header[0]&0x70 != 0 {
+		return frame{}, errors.New("unexpected reserved bits, no extension was negotiated")
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(r, extended[:]); err != nil {
+			return frame{}, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(r, extended[:]); err != nil {
+			return frame{}, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if f.control() && (length > 125 || !f.fin) {
+		return frame{}, fmt.Errorf("invalid control frame %#x", f.opcode)
+	}
+	if length > maxMessageSize {
+		return frame{}, fmt.Errorf("frame of %d bytes is too large", length)
+	}
+
+	var key [4]byte
+	masked := header[1]&0x80 != 0
+	if masked {
+		if _, err := io.ReadFull(r, key[:]); err != nil {
+			return frame{}, err
+		}
+	}
+
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return frame{}, err
+	}
+	if masked {
+		for i := range f.payload {
+			f.payload[i] ^= key[i%4]
+		}
+	}
+
+	return f, nil
+}
@@ -0,0 +1,58 @@
+package websocket
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	tests := []struct {
+		name           string
+		payloadLength  int
+		mask           bool
+		expectedHeader int
+	}{
+		{name: "short unmasked", payloadLength: 125, expectedHeader: 2},
+		{name: "short masked", payloadLength: 5, mask: true, expectedHeader: 6},
+		{name: "16 bit length", payloadLength: 126, expectedHeader: 4},
+		{name: "64 bit length", payloadLength: 70000, mask: true, expectedHeader: 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := []byte(strings.Repeat("x", tt.payloadLength))
+
+			var buf bytes.Buffer
+			require.NoError(t, writeFrame(&buf, frame{fin: true, opcode: opBinary, payload: payload}, tt.mask))
+			require.Equal(t, tt.expectedHeader+tt.payloadLength, buf.Len())
+			require.Equal(t, tt.mask, buf.Bytes()[1]&0x80 != 0)
+
+			f, err := readFrame(&buf)
+			require.NoError(t, err)
+			require.Equal(t, frame{fin: true, opcode: opBinary, payload: payload}, f)
+		})
+	}
+}
+
+func TestReadFrame_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		expectError string
+	}{
+		{name: "reserved bits", data: []byte{0xc1, 0x00}, expectError: "unexpected reserved bits, no extension was negotiated"},
+		{name: "fragmented ping", data: []byte{0x09, 0x00}, expectError: "invalid control frame 0x9"},
+		{name: "long close", data: []byte{0x88, 0x7e, 0x00, 0x80}, expectError: "invalid control frame 0x8"},
+		{name: "truncated payload", data: []byte{0x81, 0x05, 'a'}, expectError: "unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readFrame(bytes.NewReader(tt.data))
+			require.EqualError(t, err, tt.expectError)
+		})
+	}
+}
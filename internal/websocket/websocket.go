@@ -0,0 +1,246 @@
+// Package websocket is a WebSocket client, RFC 6455, sharing the alias
+// settings of plain requests for its opening handshake.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ashttp/internal/config"
+	internalhttp "github.com/ashttp/internal/http"
+)
+
+const (
+	TextMessage   = opText
+	BinaryMessage = opBinary
+)
+
+// Close codes of RFC 6455 section 7.4.1.
+const (
+	CloseNormal    = 1000
+	CloseGoingAway = 1001
+	CloseProtocol  = 1002
+	// CloseNoStatus is reported for a close frame without a code, it is
+	// never sent.
+	CloseNoStatus = 1005
+
+	closeReasonMax = 123
+)
+
+var errClosing = errors.New("connection is closing")
+
+// acceptGUID is appended to the handshake key, see RFC 6455 section 1.3.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+type Message struct {
+	Type int
+	Data []byte
+}
+
+// CloseError is returned by ReadMessage once the server closed the
+// connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("connection closed with code %d", e.Code)
+	}
+	return fmt.Sprintf("connection closed with code %d: %s", e.Code, e.Reason)
+}
+
+// Normal reports whether the connection was closed on purpose.
+func (e *CloseError) Normal() bool {
+	return e.Code == CloseNormal || e.Code == CloseGoingAway || e.Code == CloseNoStatus
+}
+
+// Conn is a client connection. ReadMessage must be called from a single
+// goroutine, writes may come from any.
+type Conn struct {
+	rw     io.ReadWriteCloser
+	reader *bufio.Reader
+
+	writeMu   sync.Mutex
+	closeSent bool
+}
+
+// URL returns the ws or wss form of an http or https URL.
+func URL(u *url.URL) string {
+	ws := *u
+	switch ws.Scheme {
+	case "http":
+		ws.Scheme = "ws"
+	case "https":
+		ws.Scheme = "wss"
+	}
+	return ws.String()
+}
+
+// PrepareHandshake turns req into a handshake request, returning the key
+// the server must answer to.
+func PrepareHandshake(req *http.Request) (string, error) {
+	switch req.URL.Scheme {
+	case "ws":
+		req.URL.Scheme = "http"
+	case "wss":
+		req.URL.Scheme = "https"
+	}
+
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return "", err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	req.Method = http.MethodGet
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	return key, nil
+}
+
+// Dial opens a connection with the handshake request req, usually a GET
+// built from the alias so that its default headers and auth are sent.
+// Aliases may use ws and wss URLs as well as http and https ones.
+func Dial(req *http.Request, setting config.Setting) (*Conn, *http.Response, error) {
+	key, err := PrepareHandshake(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, rw, err := internalhttp.Upgrade(req, setting)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		rw.Close()
+		return nil, resp, errors.New("the server answered with an invalid Sec-WebSocket-Accept")
+	}
+
+	return &Conn{rw: rw, reader: bufio.NewReader(rw)}, resp, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ReadMessage returns the next text or binary message, answering pings on
+// the way. It returns a *CloseError once the server closes.
+func (c *Conn) ReadMessage() (Message, error) {
+	var message Message
+	for {
+		f, err := readFrame(c.reader)
+		if err != nil {
+			return Message{}, err
+		}
+
+		switch f.opcode {
+		case opPing:
+			err := c.write(frame{fin: true, opcode: opPong, payload: f.payload})
+			if err != nil && !errors.Is(err, errClosing) {
+				return Message{}, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			closeErr := &CloseError{Code: CloseNoStatus}
+			if len(f.payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(f.payload))
+				closeErr.Reason = string(f.payload[2:])
+			}
+			// Echo the close unless we started the closing handshake.
+			echo := closeErr.Code
+			if echo == CloseNoStatus {
+				echo = CloseNormal
+			}
+			_ = c.WriteClose(echo, "")
+			return Message{}, closeErr
+		case opText, opBinary:
+			if message.Type != 0 {
+				return Message{}, c.fail("new message before the previous one ended")
+			}
+			message.Type = int(f.opcode)
+		case opContinuation:
+			if message.Type == 0 {
+				return Message{}, c.fail("continuation frame without a message")
+			}
+		default:
+			return Message{}, c.fail(fmt.Sprintf("unknown opcode %#x", f.opcode))
+		}
+
+		if len(message.Data)+len(f.payload) > maxMessageSize {
+			return Message{}, c.fail("message too large")
+		}
+		message.Data = append(message.Data, f.payload...)
+
+		if f.fin {
+			if message.Type == TextMessage && !utf8.Valid(message.Data) {
+				return Message{}, c.fail("text message is not valid UTF-8")
+			}
+			return message, nil
+		}
+	}
+}
+
+// fail closes the connection after a protocol violation of the server.
+func (c *Conn) fail(reason string) error {
+	_ = c.WriteClose(CloseProtocol, reason)
+	c.rw.Close()
+	return fmt.Errorf("protocol error: %s", reason)
+}
+
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("unknown message type %d", messageType)
+	}
+	return c.write(frame{fin: true, opcode: byte(messageType), payload: data})
+}
+
+// WriteClose starts the closing handshake, or answers the server's. The
+// connection stays readable until the server confirms.
+func (c *Conn) WriteClose(code int, reason string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return nil
+	}
+	c.closeSent = true
+
+	if len(reason) > closeReasonMax {
+		reason = reason[:closeReasonMax]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return writeFrame(c.rw, frame{fin: true, opcode: opClose, payload: append(payload, reason...)}, true)
+}
+
+func (c *Conn) write(f frame) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return errClosing
+	}
+	return writeFrame(c.rw, f, true)
+}
+
+// Close closes the underlying connection without waiting for the server.
+func (c *Conn) Close() error {
+	return c.rw.Close()
+}
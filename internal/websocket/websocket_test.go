@@ -0,0 +1,194 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ashttp/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+// newEchoServer answers every message with the same message. A few
+// messages trigger other behaviours: "fragment" is echoed in two frames,
+// "ping" is preceded by a ping that must be answered and "bye" makes the
+// server close the connection.
+func newEchoServer(t *testing.T) (*httptest.Server, *http.Header) {
+	var handshake http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handshake = r.Header.Clone()
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			http.Error(w, "websocket only", http.StatusBadRequest)
+			return
+		}
+
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+			"Sec-WebSocket-Accept: %s\r\n\r\n", acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+		require.NoError(t, rw.Flush())
+
+		echo(t, rw)
+	}))
+	t.Cleanup(server.Close)
+	return server, &handshake
+}
+
+func echo(t *testing.T, rw *bufio.ReadWriter) {
+	send := func(f frame) {
+		require.NoError(t, writeFrame(rw, f, false))
+		require.NoError(t, rw.Flush())
+	}
+
+	for {
+		f, err := readFrame(rw)
+		if err != nil {
+			return
+		}
+
+		switch {
+		case f.opcode == opClose:
+			send(f)
+			return
+		case string(f.payload) == "fragment":
+			send(frame{opcode: opText, payload: []byte("frag")})
+			send(frame{fin: true, opcode: opContinuation, payload: []byte("ment")})
+		case string(f.payload) == "ping":
+			send(frame{fin: true, opcode: opPing, payload: []byte("p")})
+			pong, err := readFrame(rw)
+			require.NoError(t, err)
+			require.Equal(t, frame{fin: true, opcode: opPong, payload: []byte("p")}, pong)
+			send(f)
+		case string(f.payload) == "bye":
+			send(frame{fin: true, opcode: opClose, payload: append(binary.BigEndian.AppendUint16(nil, 4000), "bye"...)})
+		default:
+			send(f)
+		}
+	}
+}
+
+func dial(t *testing.T, rawURL string, setting config.Setting) (*Conn, *http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	require.NoError(t, err)
+	for name, value := range setting.Headers {
+		req.Header.Set(name, value)
+	}
+	return Dial(req, setting)
+}
+
+func TestConn(t *testing.T) {
+	server, handshake := newEchoServer(t)
+	wsURL := strings.Replace(server.URL, "http://", "ws://", 1)
+
+	conn, resp, err := dial(t, wsURL+"/chat?room=1", config.Setting{Headers: map[string]string{"Authorization": "Bearer t"}})
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, "Bearer t", handshake.Get("Authorization"))
+	require.Equal(t, "13", handshake.Get("Sec-WebSocket-Version"))
+
+	exchange := func(messageType int, data string) Message {
+		require.NoError(t, conn.WriteMessage(messageType, []byte(data)))
+		message, err := conn.ReadMessage()
+		require.NoError(t, err)
+		return message
+	}
+
+	require.Equal(t, Message{Type: TextMessage, Data: []byte(`{"hello":"world"}`)}, exchange(TextMessage, `{"hello":"world"}`))
+	require.Equal(t, Message{Type: BinaryMessage, Data: []byte{0, 1, 2}}, exchange(BinaryMessage, "\x00\x01\x02"))
+	require.Equal(t, Message{Type: TextMessage, Data: []byte("fragment")}, exchange(TextMessage, "fragment"))
+	require.Equal(t, Message{Type: TextMessage, Data: []byte("ping")}, exchange(TextMessage, "ping"))
+
+	t.Run("client closing", func(t *testing.T) {
+		require.NoError(t, conn.WriteClose(CloseNormal, ""))
+		_, err := conn.ReadMessage()
+
+		var closeErr *CloseError
+		require.ErrorAs(t, err, &closeErr)
+		require.True(t, closeErr.Normal())
+		require.ErrorIs(t, conn.WriteMessage(TextMessage, []byte("late")), errClosing)
+	})
+}
+
+func TestConn_ServerClosing(t *testing.T) {
+	server, _ := newEchoServer(t)
+
+	conn, _, err := dial(t, server.URL, config.Setting{})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteMessage(TextMessage, []byte("bye")))
+	_, err = conn.ReadMessage()
+	require.EqualError(t, err, "connection closed with code 4000: bye")
+
+	var closeErr *CloseError
+	require.ErrorAs(t, err, &closeErr)
+	require.False(t, closeErr.Normal())
+}
+
+func TestDial_Rejected(t *testing.T) {
+	t.Run("no upgrade", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		_, resp, err := dial(t, server.URL, config.Setting{})
+		require.EqualError(t, err, "the server answered 403 Forbidden instead of switching protocols")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("wrong accept key", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			conn, rw, err := http.NewResponseController(w).Hijack()
+			require.NoError(t, err)
+			defer conn.Close()
+			fmt.Fprint(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+				"Sec-WebSocket-Accept: nope\r\n\r\n")
+			require.NoError(t, rw.Flush())
+		}))
+		defer server.Close()
+
+		_, _, err := dial(t, server.URL, config.Setting{})
+		require.EqualError(t, err, "the server answered with an invalid Sec-WebSocket-Accept")
+	})
+}
+
+func TestURL(t *testing.T) {
+	for raw, expected := range map[string]string{
+		"http://api.local/chat":     "ws://api.local/chat",
+		"https://api.local:8443/ws": "wss://api.local:8443/ws",
+		"wss://api.local/ws":        "wss://api.local/ws",
+	} {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		require.Equal(t, expected, URL(u))
+	}
+}
+
+func TestPrepareHandshake(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "wss://api.local/chat", nil)
+	require.NoError(t, err)
+
+	key, err := PrepareHandshake(req)
+	require.NoError(t, err)
+	require.Len(t, key, 24)
+	require.Equal(t, http.MethodGet, req.Method)
+	require.Equal(t, "https://api.local/chat", req.URL.String())
+	require.Equal(t, "Upgrade", req.Header.Get("Connection"))
+	require.Equal(t, "websocket", req.Header.Get("Upgrade"))
+	require.Equal(t, "13", req.Header.Get("Sec-WebSocket-Version"))
+	require.Equal(t, key, req.Header.Get("Sec-WebSocket-Key"))
+}
+
+func TestAcceptKey(t *testing.T) {
+	// The example of RFC 6455 section 1.3.
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}